@@ -0,0 +1,36 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAtomic_WriteFile(t *testing.T) {
+	// Arrange
+	dir, err := ioutil.TempDir(os.TempDir(), "superk_test_atomic_")
+	assert.Nil(t, err)
+	path := filepath.Join(dir, "nested", "file.json")
+
+	// Act
+	err1 := writeFileAtomic(path, []byte("first version, which is longer"), 0600)
+	err2 := writeFileAtomic(path, []byte("second"), 0600)
+
+	// Assert
+	assert.Nil(t, err1)
+	assert.Nil(t, err2)
+	content, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "second", string(content))
+	files, err := ioutil.ReadDir(filepath.Dir(path))
+	assert.Nil(t, err)
+	assert.Len(t, files, 1)
+	assert.Equal(t, os.FileMode(0600), files[0].Mode().Perm())
+
+	// Cleanup
+	err = os.RemoveAll(dir)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,185 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// AuditEntry represents a command that ran, as recorded in the audit log
+type AuditEntry struct {
+	Command   string `json:"command"`
+	Context   string `json:"context,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	// User is who ran the command in this machine
+	User      string    `json:"user,omitempty"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+	ExitCode  int       `json:"exitCode"`
+	// OutputHash is the SHA-256 of what the command wrote to its standard output
+	OutputHash string `json:"outputHash"`
+	// Error is why the command could not run, if it could not (e.g. it changes the cluster and its context is read-only)
+	Error string `json:"error,omitempty"`
+	// Fake is whether a fake cluster answered the command, instead of a real one
+	Fake bool `json:"fake,omitempty"`
+}
+
+// AuditFilter represents which entries of the audit log a query returns. Empty fields match every entry.
+type AuditFilter struct {
+	// From and To are the range of times the commands started at
+	From    time.Time
+	To      time.Time
+	Verb    string
+	Context string
+}
+
+// AuditLog is an append-only file with the commands that ran, one JSON entry per line.
+// Several instances of the app may append to it at the same time.
+type AuditLog struct {
+	File  string
+	mutex sync.Mutex
+}
+
+// NewAuditLog creates an audit log that is written to a file
+func NewAuditLog(file string) *AuditLog {
+	return &AuditLog{File: file}
+}
+
+// Append adds an entry at the end of the log
+func (log *AuditLog) Append(entry AuditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+	if err := os.MkdirAll(filepath.Dir(log.File), 0700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(log.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	// Each entry is a single write, so entries of several instances that append at the same time are not mixed
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Query returns the entries of the log that match a filter, oldest first.
+// Lines that are not valid entries (e.g. a line cut by a crash) are skipped.
+func (log *AuditLog) Query(filter AuditFilter) ([]AuditEntry, error) {
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+
+	file, err := os.Open(log.File)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		if filter.Matches(&entry) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// Matches returns whether an entry of the audit log matches the filter
+func (filter *AuditFilter) Matches(entry *AuditEntry) bool {
+	switch {
+	case !filter.From.IsZero() && entry.StartTime.Before(filter.From):
+		return false
+	case !filter.To.IsZero() && entry.StartTime.After(filter.To):
+		return false
+	case filter.Verb != "" && entry.Verb() != filter.Verb:
+		return false
+	case filter.Context != "" && entry.Context != filter.Context:
+		return false
+	}
+	return true
+}
+
+// Verb returns the verb of the command of an entry (e.g. "delete" in "kubectl -n kubeflow delete pod nginx")
+func (entry *AuditEntry) Verb() string {
+	words, err := tokenize(entry.Command)
+	if err != nil || len(words) == 0 {
+		return ""
+	}
+	positionals, _, _ := guardWords(partArgs(entry.Command))
+	if len(positionals) == 0 {
+		return ""
+	}
+	return positionals[0]
+}
+
+// AuditExecutor is an executor that records every command it runs in an audit log, once it finishes
+type AuditExecutor struct {
+	// Executor runs the commands. nil runs them with the binaries installed in the machine.
+	Executor Executor
+	Log      *AuditLog
+	// Fake marks the entries of the commands as answered by a fake cluster
+	Fake bool
+}
+
+// Check interface
+var _ Executor = &AuditExecutor{}
+
+// Execute runs a command line and records it in the audit log
+func (executor *AuditExecutor) Execute(ctx context.Context, args []string, env []string, stdout, stderr io.Writer) (int, error) {
+	entry := AuditEntry{
+		Command:   commandLine(args),
+		Context:   KubeContext(args),
+		Namespace: KubeNamespace(args),
+		User:      currentUser(),
+		StartTime: time.Now(),
+		Fake:      executor.Fake,
+	}
+
+	inner := executor.Executor
+	if inner == nil {
+		inner = ExecExecutor{}
+	}
+	hash := sha256.New()
+	exitCode, err := inner.Execute(ctx, args, env, io.MultiWriter(stdout, hash), stderr)
+
+	entry.EndTime, entry.ExitCode, entry.OutputHash = time.Now(), exitCode, hex.EncodeToString(hash.Sum(nil))
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if err := executor.Log.Append(entry); err != nil {
+		fmt.Fprintf(stderr, "Could not write the audit log: %v\n", err)
+	}
+	return exitCode, err
+}
+
+// currentUser returns the name of the user that runs the app
+func currentUser() string {
+	if current, err := user.Current(); err == nil {
+		return current.Username
+	}
+	return os.Getenv("USER")
+}
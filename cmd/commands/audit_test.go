@@ -0,0 +1,166 @@
+package commands
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditLog_Query(t *testing.T) {
+	// Arrange
+	path, err := getTmpPath("superk_test_audit_")
+	assert.Nil(t, err)
+	log := NewAuditLog(path)
+	start := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	entries := []AuditEntry{
+		{Command: "kubectl get pod", Context: "aks-dev", StartTime: start},
+		{Command: "kubectl -n kubeflow delete pod nginx", Context: "aks-prod", StartTime: start.Add(time.Hour)},
+		{Command: "kubectl --context aks-dev delete ns test", Context: "aks-dev", StartTime: start.Add(2 * time.Hour)},
+	}
+	for _, entry := range entries {
+		assert.Nil(t, log.Append(entry))
+	}
+
+	tests := []struct {
+		name     string
+		filter   AuditFilter
+		expected []string
+	}{
+		{"All", AuditFilter{}, []string{entries[0].Command, entries[1].Command, entries[2].Command}},
+		{"From", AuditFilter{From: start.Add(time.Hour)}, []string{entries[1].Command, entries[2].Command}},
+		{"To", AuditFilter{To: start.Add(time.Hour)}, []string{entries[0].Command, entries[1].Command}},
+		{"Verb", AuditFilter{Verb: "delete"}, []string{entries[1].Command, entries[2].Command}},
+		{"Context", AuditFilter{Context: "aks-dev"}, []string{entries[0].Command, entries[2].Command}},
+		{"VerbAndContext", AuditFilter{Verb: "delete", Context: "aks-prod"}, []string{entries[1].Command}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			result, err := log.Query(test.filter)
+
+			// Assert
+			assert.Nil(t, err)
+			var commands []string
+			for _, entry := range result {
+				commands = append(commands, entry.Command)
+			}
+			assert.EqualValues(t, test.expected, commands)
+		})
+	}
+
+	// Cleanup
+	os.Remove(path)
+}
+
+func TestAuditLog_QueryInvalidLine(t *testing.T) {
+	// Arrange
+	path, err := writeTmpStore("{\"command\":\"kubectl get pod\"}\n{\"command\":\"kubectl del\n")
+	assert.Nil(t, err)
+	log := NewAuditLog(path)
+
+	// Act
+	result, err := log.Query(AuditFilter{})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Len(t, result, 1)
+
+	// Cleanup
+	os.Remove(path)
+}
+
+func TestAuditLog_QueryMissing(t *testing.T) {
+	// Arrange
+	log := NewAuditLog("/tmp/superk_test_audit_missing.jsonl")
+
+	// Act
+	result, err := log.Query(AuditFilter{})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Empty(t, result)
+}
+
+func TestAuditExecutor_Execute(t *testing.T) {
+	// Arrange
+	dir, err := writeTmpFakeCluster(map[string]string{
+		FakeFixturesFile: `{"fixtures": [{"command": "kubectl --context aks-prod -n kubeflow get pod", "stdout": "pods.txt"}]}`,
+		"pods.txt":       "nginx\n",
+	})
+	assert.Nil(t, err)
+	fake, err := NewFakeExecutor(dir)
+	assert.Nil(t, err)
+	path, err := getTmpPath("superk_test_audit_")
+	assert.Nil(t, err)
+	log := NewAuditLog(path)
+	executor := AuditExecutor{Executor: &ReadOnlyExecutor{Executor: fake, All: true}, Log: log}
+	hash := sha256.Sum256([]byte("nginx\n"))
+
+	// Act
+	var stdout, stderr strings.Builder
+	exitCode, err := executor.Execute(context.Background(), []string{"kubectl", "--context", "aks-prod", "--namespace=kubeflow", "get", "pod"}, nil, &stdout, &stderr)
+	_, refused := executor.Execute(context.Background(), []string{"kubectl", "delete", "pod", "nginx"}, nil, &stdout, &stderr)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 0, exitCode)
+	assert.Equal(t, "nginx\n", stdout.String())
+	assert.NotNil(t, refused)
+	entries, err := log.Query(AuditFilter{})
+	assert.Nil(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "kubectl --context aks-prod --namespace=kubeflow get pod", entries[0].Command)
+	assert.Equal(t, "aks-prod", entries[0].Context)
+	assert.Equal(t, "kubeflow", entries[0].Namespace)
+	assert.Equal(t, hex.EncodeToString(hash[:]), entries[0].OutputHash)
+	assert.False(t, entries[0].EndTime.Before(entries[0].StartTime))
+	assert.Empty(t, entries[0].Error)
+	assert.False(t, entries[0].Fake)
+	assert.Equal(t, -1, entries[1].ExitCode)
+	assert.Equal(t, refused.Error(), entries[1].Error)
+	content, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, strings.Count(string(content), "\n"))
+
+	// Cleanup
+	os.RemoveAll(dir)
+	os.Remove(path)
+}
+
+func TestAuditExecutor_ExecuteFake(t *testing.T) {
+	// Arrange
+	dir, err := writeTmpFakeCluster(map[string]string{
+		FakeFixturesFile: `{"fixtures": [{"command": "kubectl get pod", "stdout": "pods.txt"}]}`,
+		"pods.txt":       "nginx\n",
+	})
+	assert.Nil(t, err)
+	fake, err := NewFakeExecutor(dir)
+	assert.Nil(t, err)
+	path, err := getTmpPath("superk_test_audit_")
+	assert.Nil(t, err)
+	log := NewAuditLog(path)
+	executor := AuditExecutor{Executor: fake, Log: log, Fake: true}
+
+	// Act
+	var stdout, stderr strings.Builder
+	_, err = executor.Execute(context.Background(), []string{"kubectl", "get", "pod"}, nil, &stdout, &stderr)
+
+	// Assert
+	assert.Nil(t, err)
+	entries, err := log.Query(AuditFilter{})
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+	assert.True(t, entries[0].Fake)
+
+	// Cleanup
+	os.RemoveAll(dir)
+	os.Remove(path)
+}
@@ -20,31 +20,37 @@ func NewBackup(tempFileName string) *Backup {
 }
 
 // SetCommands updates the backup file with the provided commands
-func (backup *Backup) SetCommands(commands *CTree) error {
+func (backup *Backup) SetCommands(commands ISerializable) error {
 	commandsToBackup := commands.Serialize()
 	return backup.create(commandsToBackup)
 }
 
 func (backup *Backup) create(commands []string) error {
-	file, err := os.Create(backup.TempFile)
-	if err != nil {
-		return err
-	}
+	var content strings.Builder
 	for _, command := range commands {
-		if _, err := file.WriteString(fmt.Sprintf("%s\n", command)); err != nil {
-			return err
-		}
+		content.WriteString(fmt.Sprintf("%s\n", command))
 	}
-	return nil
+	return writeFileAtomic(backup.TempFile, []byte(content.String()), 0600)
 }
 
-// Commands returns the commands from the backup file
-func (backup *Backup) Commands() (*CTree, error) {
+// Commands returns the commands from the backup file.
+// Roots default to DefaultRoots. Roots found in the backup are allowed too, so no command is lost.
+func (backup *Backup) Commands(roots ...string) (*CForest, error) {
 	backupCommands, err := backup.get()
 	if err != nil {
 		backupCommands = nil
 	}
-	return NewCTree(backupCommands)
+	return newCForestFromBackup(roots, backupCommands)
+}
+
+func newCForestFromBackup(roots []string, commands []string) (*CForest, error) {
+	forest := CForest{Roots: append([]string{}, rootsOrDefault(roots)...)}
+	for _, command := range commands {
+		if root := forest.rootOf(command); root != "" && !forest.IsRoot(root) {
+			forest.Roots = append(forest.Roots, root)
+		}
+	}
+	return NewCForest(forest.Roots, commands)
 }
 
 func (backup *Backup) get() ([]string, error) {
@@ -59,6 +65,21 @@ func (backup *Backup) get() ([]string, error) {
 	return commands, nil
 }
 
+// Migrate rewrites the backup file with the canonical form of its commands.
+// It returns the number of commands before and after the migration.
+func (backup *Backup) Migrate(roots ...string) (int, int, error) {
+	commands, err := backup.get()
+	if err != nil {
+		return 0, 0, err
+	}
+	forest, err := newCForestFromBackup(roots, commands)
+	if err != nil {
+		return 0, 0, err
+	}
+	canonical := forest.Serialize()
+	return len(commands), len(canonical), backup.create(canonical)
+}
+
 // Delete deletes the backup file
 func (backup *Backup) Delete() error {
 	return os.Remove(backup.TempFile)
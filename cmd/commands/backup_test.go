@@ -52,6 +52,102 @@ func TestBackup_SetCommands(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestBackup_SetCommandsQuoted(t *testing.T) {
+	// Arrange
+	path, err := getTmpPath("superk_test_")
+	assert.Nil(t, err)
+	fileName := filepath.Base(path)
+	backup := NewBackup(fileName)
+
+	expected := []string{
+		"kubectl get pod -l 'app in (a,b)'",
+		"kubectl get pod -o jsonpath='{.items[*].metadata.name}'",
+		"kubectl get pod --field-selector \"status.phase!=Running\"",
+	}
+
+	tree, err := NewCTree(expected)
+	assert.Nil(t, err)
+
+	// Act
+	err = backup.SetCommands(tree)
+
+	// Assert
+	assert.Nil(t, err)
+	result, err := backup.Commands()
+	assert.Nil(t, err)
+	assert.EqualValues(t, expected, result.Serialize())
+
+	// Cleanup
+	err = backup.Delete()
+	assert.Nil(t, err)
+}
+
+func TestBackup_Migrate(t *testing.T) {
+	// Arrange
+	path, err := getTmpPath("superk_test_")
+	assert.Nil(t, err)
+	fileName := filepath.Base(path)
+	backup := NewBackup(fileName)
+
+	err = backup.create([]string{
+		"kubectl get pod -n kubeflow",
+		"kubectl get po --namespace kubeflow",
+		"kubectl --namespace=kubeflow get cronjobs",
+	})
+	assert.Nil(t, err)
+
+	expected := []string{
+		"kubectl -n kubeflow get pod",
+		"kubectl -n kubeflow get cronjob",
+	}
+
+	// Act
+	before, after, err := backup.Migrate()
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 3, before)
+	assert.Equal(t, 2, after)
+	result, err := backup.get()
+	assert.Nil(t, err)
+	assert.EqualValues(t, expected, result)
+
+	// Cleanup
+	err = backup.Delete()
+	assert.Nil(t, err)
+}
+
+func TestBackup_SetCommandsForest(t *testing.T) {
+	// Arrange
+	path, err := getTmpPath("superk_test_")
+	assert.Nil(t, err)
+	fileName := filepath.Base(path)
+	backup := NewBackup(fileName)
+
+	expected := []string{
+		"kubectl -n kubeflow get pod",
+		"helm -n kubeflow list",
+		"kind get clusters",
+	}
+
+	forest, err := NewCForest(DefaultRoots, expected)
+	assert.Nil(t, err)
+
+	// Act
+	err = backup.SetCommands(forest)
+
+	// Assert
+	assert.Nil(t, err)
+	result, err := backup.Commands("kubectl", "helm")
+	assert.Nil(t, err)
+	assert.EqualValues(t, expected, result.Serialize())
+	assert.EqualValues(t, []string{"kubectl", "helm", "kind"}, result.Roots)
+
+	// Cleanup
+	err = backup.Delete()
+	assert.Nil(t, err)
+}
+
 func getTmpPath(prefix string) (string, error) {
 	tmpFile, err := ioutil.TempFile(os.TempDir(), prefix)
 	if err != nil {
@@ -0,0 +1,174 @@
+package commands
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Statuses of the commands in a batch
+const (
+	BatchPending   string = "pending"
+	BatchRunning   string = "running"
+	BatchDone      string = "done"
+	BatchCancelled string = "cancelled"
+)
+
+// Batch represents several commands that run at the same time (e.g. all the commands in a subtree),
+// no more than a certain number of them at once
+type Batch struct {
+	mutex   sync.Mutex
+	entries []*BatchEntry
+	limit   int
+}
+
+// BatchEntry represents a command in a batch and the result of running it
+type BatchEntry struct {
+	Cmd       *Cmd
+	Status    string
+	StartTime time.Time
+	// Output is the output of the command, once it is done
+	Output *CmdOutput
+	// Started is whether the batch ran the command, instead of waiting for an execution that was already running
+	Started bool
+}
+
+// NewBatch creates a batch of commands that runs up to limit commands at once
+func NewBatch(cmds []*Cmd, limit int) *Batch {
+	if limit < 1 {
+		limit = 1
+	}
+	batch := Batch{limit: limit}
+	for _, cmd := range cmds {
+		batch.entries = append(batch.entries, &BatchEntry{Cmd: cmd, Status: BatchPending})
+	}
+	return &batch
+}
+
+// Run runs the commands of the batch in the background, in order.
+// changed is called from the background every time a command starts running and when it is done.
+func (batch *Batch) Run(changed func(entry BatchEntry)) {
+	semaphore := make(chan struct{}, batch.limit)
+	go func() {
+		for _, entry := range batch.entries {
+			semaphore <- struct{}{}
+			if !batch.set(entry, BatchRunning, nil) {
+				<-semaphore
+				continue
+			}
+			changed(batch.get(entry))
+
+			go func(entry *BatchEntry) {
+				defer func() { <-semaphore }()
+				// A command that is already running is waited for instead
+				result := make(chan *CmdOutput, 1)
+				started := entry.Cmd.RunAsync(func(output *CmdOutput) { result <- output })
+				var output *CmdOutput
+				if started {
+					output = <-result
+				} else {
+					entry.Cmd.waitRunning()
+					current := entry.Cmd.GetOutput()
+					output = &current
+				}
+				batch.done(entry, output, started)
+				changed(batch.get(entry))
+			}(entry)
+		}
+	}()
+}
+
+// set changes the status of a command in the batch.
+// Commands that did not start yet do not start once the batch is cancelled.
+func (batch *Batch) set(entry *BatchEntry, status string, output *CmdOutput) bool {
+	batch.mutex.Lock()
+	defer batch.mutex.Unlock()
+	if status == BatchRunning && entry.Status != BatchPending {
+		return false
+	}
+	entry.Status, entry.Output = status, output
+	if status == BatchRunning {
+		entry.StartTime = time.Now()
+	}
+	return true
+}
+
+// done records the output of a command of the batch, and whether the batch ran it
+func (batch *Batch) done(entry *BatchEntry, output *CmdOutput, started bool) {
+	batch.mutex.Lock()
+	defer batch.mutex.Unlock()
+	entry.Status, entry.Output, entry.Started = BatchDone, output, started
+}
+
+func (batch *Batch) get(entry *BatchEntry) BatchEntry {
+	batch.mutex.Lock()
+	defer batch.mutex.Unlock()
+	return *entry
+}
+
+// Cancel kills the commands of the batch that are running, and keeps the rest from running
+func (batch *Batch) Cancel() {
+	batch.mutex.Lock()
+	defer batch.mutex.Unlock()
+	for _, entry := range batch.entries {
+		switch entry.Status {
+		case BatchPending:
+			entry.Status = BatchCancelled
+		case BatchRunning:
+			entry.Cmd.Cancel()
+		}
+	}
+}
+
+// Entries returns the commands of the batch: failures first, then the commands that are running,
+// the ones that are waiting to run, the cancelled ones and the ones that succeeded
+func (batch *Batch) Entries() []BatchEntry {
+	batch.mutex.Lock()
+	defer batch.mutex.Unlock()
+
+	entries := make([]BatchEntry, len(batch.entries))
+	for index, entry := range batch.entries {
+		entries[index] = *entry
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].rank() < entries[j].rank()
+	})
+	return entries
+}
+
+// Count returns how many commands of the batch are done, how many of them failed, and how many commands there are
+func (batch *Batch) Count() (int, int, int) {
+	batch.mutex.Lock()
+	defer batch.mutex.Unlock()
+
+	done, failed := 0, 0
+	for _, entry := range batch.entries {
+		if entry.Status == BatchDone {
+			done++
+			if entry.Output.Failed() {
+				failed++
+			}
+		}
+	}
+	return done, failed, len(batch.entries)
+}
+
+// Failed returns whether the command ran and failed
+func (entry *BatchEntry) Failed() bool {
+	return entry.Status == BatchDone && entry.Output.Failed()
+}
+
+func (entry *BatchEntry) rank() int {
+	switch {
+	case entry.Failed():
+		return 0
+	case entry.Status == BatchRunning:
+		return 1
+	case entry.Status == BatchPending:
+		return 2
+	case entry.Status == BatchCancelled:
+		return 3
+	default:
+		return 4
+	}
+}
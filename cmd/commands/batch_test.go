@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatch_Run(t *testing.T) {
+	// Arrange
+	cmds := []*Cmd{
+		NewCmd("printf", "%s", "first"),
+		NewCmd("sh", "-c", "exit 3"),
+		NewCmd("sleep", "0.2"),
+	}
+	batch := NewBatch(cmds, 2)
+	var mutex sync.Mutex
+	running, maxRunning := 0, 0
+	var wg sync.WaitGroup
+	wg.Add(len(cmds))
+
+	// Act
+	batch.Run(func(entry BatchEntry) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if entry.Status == BatchRunning {
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			return
+		}
+		running--
+		wg.Done()
+	})
+	wg.Wait()
+	entries := batch.Entries()
+	done, failed, total := batch.Count()
+
+	// Assert
+	assert.Equal(t, 2, maxRunning)
+	assert.Equal(t, cmds[1], entries[0].Cmd)
+	assert.True(t, entries[0].Failed())
+	assert.Equal(t, 3, entries[0].Output.ExitCode)
+	assert.Equal(t, cmds[0], entries[1].Cmd)
+	assert.Equal(t, "first", *entries[1].Output.Output)
+	assert.Equal(t, cmds[2], entries[2].Cmd)
+	assert.Equal(t, BatchDone, entries[2].Status)
+	assert.Equal(t, 3, done)
+	assert.Equal(t, 1, failed)
+	assert.Equal(t, 3, total)
+}
+
+func TestBatch_RunAlreadyRunning(t *testing.T) {
+	// Arrange
+	running, other := NewCmd("sleep", "0.2"), NewCmd("printf", "%s", "other")
+	first := make(chan *CmdOutput, 1)
+	running.RunAsync(func(output *CmdOutput) { first <- output })
+	batch := NewBatch([]*Cmd{running, other}, 2)
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	// Act
+	batch.Run(func(entry BatchEntry) {
+		if entry.Status == BatchDone {
+			wg.Done()
+		}
+	})
+	wg.Wait()
+	output := <-first
+	entries := batch.Entries()
+
+	// Assert
+	assert.Equal(t, running, entries[0].Cmd)
+	assert.False(t, entries[0].Started)
+	assert.Equal(t, output.RunTime, entries[0].Output.RunTime)
+	assert.Equal(t, other, entries[1].Cmd)
+	assert.True(t, entries[1].Started)
+}
+
+func TestBatch_Cancel(t *testing.T) {
+	// Arrange
+	cmds := []*Cmd{NewCmd("sleep", "10"), NewCmd("printf", "%s", "never")}
+	batch := NewBatch(cmds, 1)
+	finished := make(chan BatchEntry, 1)
+	batch.Run(func(entry BatchEntry) {
+		if entry.Status == BatchDone {
+			finished <- entry
+		}
+	})
+	for !cmds[0].IsRunning() {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Act
+	batch.Cancel()
+	entry := <-finished
+
+	// Assert
+	assert.True(t, entry.Output.Cancelled)
+	entries := batch.Entries()
+	assert.Equal(t, BatchCancelled, entries[1].Status)
+	assert.Equal(t, cmds[1], entries[1].Cmd)
+	assert.False(t, cmds[1].IsRunning())
+	assert.Nil(t, cmds[1].GetOutput().Output)
+}
@@ -0,0 +1,244 @@
+package commands
+
+import (
+	"sort"
+	"strings"
+)
+
+// Binaries that share kubectl flags and resource names
+var canonicalRoots = map[string]bool{
+	"kubectl": true,
+	"oc":      true,
+}
+
+// Long flags and the short alias they are merged as
+var flagAliases = map[string]string{
+	"--namespace":      "-n",
+	"--output":         "-o",
+	"--all-namespaces": "-A",
+	"--selector":       "-l",
+	"--container":      "-c",
+}
+
+// Short flags that take a value, whose value may be attached to them (e.g. -nkubeflow or -n=kubeflow)
+var shortValueFlags = map[string]bool{
+	"-n": true,
+	"-o": true,
+	"-l": true,
+	"-c": true,
+	"-s": true,
+}
+
+// Global flags, in the order they are placed right after the root of the command
+var globalFlags = []string{
+	"--kubeconfig",
+	"--context",
+	"--cluster",
+	"--user",
+	"-s",
+	"--server",
+	"--as",
+	"--as-group",
+	"--request-timeout",
+	"-n",
+}
+
+// Verbs whose first argument is a resource type, and how many subcommands come before it
+var resourceVerbs = map[string]int{
+	"annotate":  0,
+	"autoscale": 0,
+	"delete":    0,
+	"describe":  0,
+	"edit":      0,
+	"explain":   0,
+	"expose":    0,
+	"get":       0,
+	"label":     0,
+	"patch":     0,
+	"rollout":   1,
+	"scale":     0,
+	"top":       0,
+	"wait":      0,
+}
+
+// Short and plural resource names, and the singular name they are merged as
+var resourceNames = map[string]string{
+	"po":                        "pod",
+	"pods":                      "pod",
+	"svc":                       "service",
+	"services":                  "service",
+	"deploy":                    "deployment",
+	"deployments":               "deployment",
+	"rs":                        "replicaset",
+	"replicasets":               "replicaset",
+	"ds":                        "daemonset",
+	"daemonsets":                "daemonset",
+	"sts":                       "statefulset",
+	"statefulsets":              "statefulset",
+	"jobs":                      "job",
+	"cj":                        "cronjob",
+	"cronjobs":                  "cronjob",
+	"cm":                        "configmap",
+	"configmaps":                "configmap",
+	"secrets":                   "secret",
+	"ns":                        "namespace",
+	"namespaces":                "namespace",
+	"no":                        "node",
+	"nodes":                     "node",
+	"ing":                       "ingress",
+	"ingresses":                 "ingress",
+	"pv":                        "persistentvolume",
+	"persistentvolumes":         "persistentvolume",
+	"pvc":                       "persistentvolumeclaim",
+	"persistentvolumeclaims":    "persistentvolumeclaim",
+	"sa":                        "serviceaccount",
+	"serviceaccounts":           "serviceaccount",
+	"ev":                        "event",
+	"events":                    "event",
+	"ep":                        "endpoints",
+	"hpa":                       "horizontalpodautoscaler",
+	"horizontalpodautoscalers":  "horizontalpodautoscaler",
+	"crd":                       "customresourcedefinition",
+	"crds":                      "customresourcedefinition",
+	"customresourcedefinitions": "customresourcedefinition",
+	"netpol":                    "networkpolicy",
+	"networkpolicies":           "networkpolicy",
+	"pdb":                       "poddisruptionbudget",
+	"poddisruptionbudgets":      "poddisruptionbudget",
+	"sc":                        "storageclass",
+	"storageclasses":            "storageclass",
+	"roles":                     "role",
+	"rolebindings":              "rolebinding",
+	"clusterroles":              "clusterrole",
+	"clusterrolebindings":       "clusterrolebinding",
+}
+
+// Canonicalize returns the canonical form of a command, which is the form it is merged into a tree as
+func Canonicalize(command string) (string, error) {
+	parts, err := split(command)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(canonicalize(parts), " "), nil
+}
+
+// canonicalize rewrites the parts of a kubectl command so equivalent commands produce the same parts:
+//   - Flag aliases are replaced by their short form: "--namespace=x" becomes "-n x"
+//   - Short flags are separated from their values: "-nx" and "-n=x" become "-n x"
+//   - Resource short and plural names are replaced by the singular name: "po" becomes "pod"
+//   - Global flags go right after the root: "kubectl get pod -n x" becomes "kubectl -n x get pod"
+//   - Other flags go after the verb and its arguments, sorted by name
+//
+// Everything after "--" is left untouched.
+func canonicalize(parts []string) []string {
+	if len(parts) == 0 || !canonicalRoots[parts[0]] {
+		return parts
+	}
+
+	globals := map[string][]string{}
+	var positionals, flags, passthrough []string
+	for i, part := range parts[1:] {
+		if part == "--" {
+			passthrough = parts[i+1:]
+			break
+		}
+		name, flag := canonicalFlag(part)
+		switch {
+		case name == "":
+			positionals = append(positionals, part)
+		case isGlobalFlag(name):
+			globals[name] = append(globals[name], flag)
+		default:
+			flags = append(flags, flag)
+		}
+	}
+
+	canonicalizeResources(positionals)
+	sort.SliceStable(flags, func(i, j int) bool {
+		return flagName(flags[i]) < flagName(flags[j])
+	})
+
+	canonical := []string{parts[0]}
+	for _, name := range globalFlags {
+		canonical = append(canonical, globals[name]...)
+	}
+	canonical = append(canonical, positionals...)
+	canonical = append(canonical, flags...)
+	return append(canonical, passthrough...)
+}
+
+// canonicalFlag returns the canonical name of a flag part and the part rewritten with that name.
+// The name is empty if the part is not a flag.
+func canonicalFlag(part string) (string, string) {
+	if !isFlag(part) {
+		return "", part
+	}
+
+	name, value := part, ""
+	if short := string([]rune(part)[:2]); shortValueFlags[short] && len(part) > 2 && part[2] != ' ' && part[2] != '=' {
+		name, value = short, part[2:]
+	} else if index := strings.IndexAny(part, " ="); index >= 0 && !strings.ContainsAny(part[:index], `'"\`) {
+		name, value = part[:index], part[index+1:]
+	}
+
+	alias, ok := flagAliases[name]
+	if !ok && shortValueFlags[name] {
+		alias, ok = name, true
+	}
+	switch {
+	case !ok:
+		return name, part
+	case value == "":
+		return alias, alias
+	case booleanFlags[alias] && value == "true":
+		return alias, alias
+	case booleanFlags[alias]:
+		return alias, alias + "=" + value
+	default:
+		return alias, alias + " " + value
+	}
+}
+
+func flagName(part string) string {
+	name, _ := canonicalFlag(part)
+	return name
+}
+
+func isGlobalFlag(name string) bool {
+	for _, global := range globalFlags {
+		if name == global {
+			return true
+		}
+	}
+	return false
+}
+
+// canonicalizeResources replaces resource names in the argument that follows a resource verb.
+// The argument may be a list ("po,svc") or a resource and a name ("deploy/nginx").
+func canonicalizeResources(positionals []string) {
+	if len(positionals) == 0 {
+		return
+	}
+	subcommands, ok := resourceVerbs[positionals[0]]
+	index := 1 + subcommands
+	if !ok || index >= len(positionals) {
+		return
+	}
+
+	argument := positionals[index]
+	if strings.ContainsAny(argument, `'"\`) {
+		return
+	}
+
+	resource, name := argument, ""
+	if slash := strings.Index(argument, "/"); slash >= 0 {
+		resource, name = argument[:slash], argument[slash:]
+	}
+	types := strings.Split(resource, ",")
+	for i, t := range types {
+		if canonical, ok := resourceNames[strings.ToLower(t)]; ok {
+			types[i] = canonical
+		}
+	}
+	positionals[index] = strings.Join(types, ",") + name
+}
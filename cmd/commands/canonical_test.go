@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonical_Canonicalize(t *testing.T) {
+	// Arrange
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Already canonical", "kubectl -n kubeflow get pod", "kubectl -n kubeflow get pod"},
+		{"Global flag after verb", "kubectl get pod -n kubeflow", "kubectl -n kubeflow get pod"},
+		{"Long namespace flag", "kubectl --namespace kubeflow get pod", "kubectl -n kubeflow get pod"},
+		{"Long namespace flag with equals", "kubectl get pod --namespace=kubeflow", "kubectl -n kubeflow get pod"},
+		{"Long output flag", "kubectl get pod --output=wide", "kubectl get pod -o wide"},
+		{"All namespaces", "kubectl get --all-namespaces pods", "kubectl get pod -A"},
+		{"Short resource name", "kubectl get deploy", "kubectl get deployment"},
+		{"Resource list", "kubectl get po,svc", "kubectl get pod,service"},
+		{"Resource and name", "kubectl describe deploy/nginx", "kubectl describe deployment/nginx"},
+		{"Rollout resource", "kubectl rollout status deploy/nginx", "kubectl rollout status deployment/nginx"},
+		{"Global flags order", "kubectl -n kubeflow --context prod get pod", "kubectl --context prod -n kubeflow get pod"},
+		{"Flags sorted", "kubectl get pod -o wide -l app=a", "kubectl get pod -l app=a -o wide"},
+		{"Quoted value kept", "kubectl get pod --selector 'app in (a,b)'", "kubectl get pod -l 'app in (a,b)'"},
+		{"Double dash kept", "kubectl exec my-pod -n kubeflow -- ls --namespace", "kubectl -n kubeflow exec my-pod -- ls --namespace"},
+		{"Other roots untouched", "helm list --namespace kubeflow", "helm list --namespace kubeflow"},
+		{"Short flag with attached value", "kubectl -nfoo get po", "kubectl -n foo get pod"},
+		{"Short flag with equals", "kubectl get po -n=foo", "kubectl -n foo get pod"},
+		{"Short output flag with attached value", "kubectl get pod -ojson", "kubectl get pod -o json"},
+		{"Short selector with attached value", "kubectl get pod -lapp=web", "kubectl get pod -l app=web"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			result, err := Canonicalize(test.input)
+			again, againErr := Canonicalize(result)
+
+			// Assert
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, result)
+			assert.Nil(t, againErr)
+			assert.Equal(t, result, again)
+		})
+	}
+}
+
+func TestCanonical_CanonicalizeRunsSameCommand(t *testing.T) {
+	// Arrange
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"kubectl -nfoo get po", []string{"kubectl", "-n", "foo", "get", "pod"}},
+		{"kubectl -n=foo get po", []string{"kubectl", "-n", "foo", "get", "pod"}},
+		{"kubectl --namespace=foo get po", []string{"kubectl", "-n", "foo", "get", "pod"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			// Act
+			tree, err := NewCTree([]string{test.input})
+
+			// Assert
+			assert.Nil(t, err)
+			assert.EqualValues(t, []string{"kubectl -n foo get pod"}, tree.Serialize())
+			assert.Equal(t, test.expected, tree.GetCmd(4).Args)
+			assert.Equal(t, "foo", KubeNamespace(tree.GetCmd(4).Args))
+		})
+	}
+}
+
+func TestCanonical_CanonicalizeInvalid(t *testing.T) {
+	// Arrange
+
+	// Act
+	result, err := Canonicalize("kubectl get pod -l 'app=a")
+
+	// Assert
+	assert.Equal(t, "", result)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,805 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SourceMaxAge is how long the output of the source of a placeholder is used before running the source again
+const SourceMaxAge = time.Minute
+
+// DefaultRoots are the binaries whose commands can be merged into a forest by default
+var DefaultRoots = []string{"kubectl", "helm", "kind", "oc", "az", "kustomize"}
+
+// ISerializable represents a set of commands that can be serialized
+type ISerializable interface {
+	Serialize() []string
+}
+
+// Check interface
+var _ ISerializable = &CTree{}
+var _ ISerializable = &CForest{}
+
+// CForest structure represents a forest of command trees, one per binary (e.g. kubectl, helm).
+// A forest must be changed from a single goroutine (e.g. the main loop of the UI),
+// but its commands may run in the background (see RecordRun).
+type CForest struct {
+	Roots []string
+	Trees []*CTree
+	// Policy is how commands run, unless their metadata says otherwise (e.g. a timeout of their own)
+	Policy Policy
+	// Executor is what runs commands (e.g. a fake cluster). nil runs them with the binaries installed in the machine.
+	Executor Executor
+	// Context is the kube context commands run against, unless their tree was set to run against another one.
+	// Empty means the current context of the kubeconfig.
+	Context string
+	journal *Journal
+	sources map[string]*Cmd
+}
+
+// NewCForest creates a forest of command trees for the allowed roots
+func NewCForest(roots []string, commands []string) (*CForest, error) {
+	if len(roots) == 0 {
+		return nil, errors.New("At least one root is required")
+	}
+
+	forest := CForest{Roots: roots}
+	for _, command := range commands {
+		if err := forest.MergeCommand(command); err != nil {
+			return nil, err
+		}
+	}
+	return &forest, nil
+}
+
+// DefaultRoot returns the root assumed for commands that don't start with an allowed root
+func (forest *CForest) DefaultRoot() string {
+	return forest.Roots[0]
+}
+
+// IsRoot returns whether commands of a given binary can be merged into the forest
+func (forest *CForest) IsRoot(root string) bool {
+	return forest.rootIndex(root) >= 0
+}
+
+func (forest *CForest) rootIndex(root string) int {
+	for index, current := range forest.Roots {
+		if current == root {
+			return index
+		}
+	}
+	return -1
+}
+
+// Qualify prefixes a command with the default root unless it already starts with an allowed root
+// Example: "get pod" becomes "kubectl get pod", but "helm list" stays as it is
+func (forest *CForest) Qualify(command string) string {
+	command = strings.TrimSpace(command)
+	if fields := strings.Fields(command); len(fields) > 0 && forest.IsRoot(fields[0]) {
+		return command
+	}
+	return strings.TrimSpace(fmt.Sprintf("%s %s", forest.DefaultRoot(), command))
+}
+
+// MergeCommand merges a command into the tree of its root, creating the tree if needed
+func (forest *CForest) MergeCommand(command string) error {
+	if err := forest.mergeCommand(command); err != nil {
+		return err
+	}
+	return forest.record(JournalMerge, command)
+}
+
+// record writes a change to the journal of the forest, if it has one
+func (forest *CForest) record(op, command string) error {
+	if forest.journal == nil {
+		return nil
+	}
+	return forest.journal.Append(op, command)
+}
+
+func (forest *CForest) mergeCommand(command string) error {
+	root := forest.rootOf(command)
+	if !forest.IsRoot(root) {
+		return fmt.Errorf("Commands must start with one of: %s", strings.Join(forest.Roots, ", "))
+	}
+
+	tree := forest.tree(root)
+	if tree == nil {
+		tree = &CTree{Part: root}
+		forest.addTree(tree)
+	}
+	return tree.MergeCommand(command)
+}
+
+func (forest *CForest) rootOf(command string) string {
+	if words, err := tokenize(command); err == nil && len(words) > 0 {
+		return words[0].value
+	}
+	return ""
+}
+
+func (forest *CForest) tree(root string) *CTree {
+	for _, tree := range forest.Trees {
+		if tree.Part == root {
+			return tree
+		}
+	}
+	return nil
+}
+
+// addTree adds a tree keeping the trees in the same order as their roots
+func (forest *CForest) addTree(tree *CTree) {
+	index := 0
+	for index < len(forest.Trees) && forest.rootIndex(forest.Trees[index].Part) < forest.rootIndex(tree.Part) {
+		index++
+	}
+	forest.Trees = append(forest.Trees, nil)
+	copy(forest.Trees[index+1:], forest.Trees[index:])
+	forest.Trees[index] = tree
+}
+
+// GetCmd returns the executable command at a certain position in the forest
+// (depth-first search, one tree after the other)
+func (forest *CForest) GetCmd(position int) *Cmd {
+	node := forest.getTree(position)
+	if node == nil {
+		return nil
+	}
+	return forest.cmd(node)
+}
+
+// cmd returns the executable command of a node, set up to run with the policy of the forest
+func (forest *CForest) cmd(node *CTree) *Cmd {
+	cmd := node.getCmd()
+	cmd.SetStreaming(node.Meta.Stream)
+	policy := forest.Policy
+	// Streaming commands run until they are stopped, so the default timeout and retries would kill and restart them
+	if cmd.IsStreaming() {
+		policy = Policy{}
+	}
+	if node.Meta.Timeout > 0 {
+		policy.Timeout = node.Meta.Timeout
+	}
+	cmd.SetPolicy(policy)
+	cmd.SetExecutor(forest.Executor)
+	root := node
+	for root.Parent != nil {
+		root = root.Parent
+	}
+	cmd.SetKubeContext(forest.context(root.Part))
+	return cmd
+}
+
+// GetLeafCmds returns the executable commands of the leaves under the node at a certain position
+// (the node itself if it is a leaf), in depth-first order. Commands that cannot run on their own are left out:
+// commands with placeholders without a value, and commands that stream their output, as they never finish.
+func (forest *CForest) GetLeafCmds(position int) []*Cmd {
+	node := forest.getTree(position)
+	if node == nil {
+		return nil
+	}
+
+	var cmds []*Cmd
+	node.walk(func(leaf *CTree) {
+		if len(leaf.Children) > 0 || len(leaf.missingValues()) > 0 {
+			return
+		}
+		if cmd := forest.cmd(leaf); !cmd.IsStreaming() {
+			cmds = append(cmds, cmd)
+		}
+	})
+	return cmds
+}
+
+// RunCmd executes the command at a certain position in the forest and records the execution in its metadata.
+// With cacheFirst, a command that already ran returns its cached output instead.
+func (forest *CForest) RunCmd(position int, cacheFirst bool) *Cmd {
+	node := forest.getTree(position)
+	if node == nil {
+		return nil
+	}
+
+	forest.cmd(node)
+	cmd, ran := node.run(cacheFirst)
+	if ran {
+		// Metadata is also saved with the next snapshot, so a journal error is not worth failing the run
+		_ = forest.recordRun(node)
+	}
+	return cmd
+}
+
+// RecordRun records an execution of a command that ran in the background in the metadata of its node.
+// It returns false if the command is no longer in the forest (e.g. it was removed while it ran).
+func (forest *CForest) RecordRun(cmd *Cmd, output *CmdOutput) bool {
+	found := forest.findCmd(cmd)
+	if found == nil {
+		found = forest.takeStaleCmd(cmd)
+	}
+	if found == nil {
+		return false
+	}
+
+	found.record(output)
+	// Metadata is also saved with the next snapshot, so a journal error is not worth failing the run
+	_ = forest.recordRun(found)
+	return true
+}
+
+// HasCmd returns whether an executable command is still the command of a node in the forest.
+// It is not once its node is removed, or the values of its placeholders change.
+func (forest *CForest) HasCmd(cmd *Cmd) bool {
+	return forest.findCmd(cmd) != nil
+}
+
+// findCmd returns the node whose executable command is a certain one
+func (forest *CForest) findCmd(cmd *Cmd) *CTree {
+	var found *CTree
+	for _, tree := range forest.Trees {
+		tree.walk(func(node *CTree) {
+			if node.Cmd == cmd {
+				found = node
+			}
+		})
+	}
+	return found
+}
+
+// takeStaleCmd returns the node that kept aside a certain command when the values of its placeholders changed,
+// and forgets that command
+func (forest *CForest) takeStaleCmd(cmd *Cmd) *CTree {
+	var found *CTree
+	for _, tree := range forest.Trees {
+		tree.walk(func(node *CTree) {
+			for index, stale := range node.stale {
+				if stale == cmd {
+					node.stale = append(node.stale[:index], node.stale[index+1:]...)
+					found = node
+					return
+				}
+			}
+		})
+	}
+	return found
+}
+
+// recordRun records the last execution of the command of a node in the journal
+func (forest *CForest) recordRun(node *CTree) error {
+	if forest.journal == nil {
+		return nil
+	}
+	exitCode := 0
+	if node.Meta.LastExitCode != nil {
+		exitCode = *node.Meta.LastExitCode
+	}
+	return forest.journal.AppendRun(node.toCommand(), exitCode)
+}
+
+// GetRunning returns the positions in the forest of the commands that are running
+func (forest *CForest) GetRunning() map[int]bool {
+	running := map[int]bool{}
+	position := 0
+	for _, tree := range forest.Trees {
+		tree.walk(func(node *CTree) {
+			position++
+			if node.Cmd != nil && node.Cmd.IsRunning() {
+				running[position] = true
+			}
+		})
+	}
+	return running
+}
+
+// GetLastExitCodes returns the exit codes of the last execution of the commands in the forest, by position.
+// Commands that never ran are not in the map.
+func (forest *CForest) GetLastExitCodes() map[int]int {
+	exitCodes := map[int]int{}
+	position := 0
+	for _, tree := range forest.Trees {
+		tree.walk(func(node *CTree) {
+			position++
+			if node.Meta.LastExitCode != nil {
+				exitCodes[position] = *node.Meta.LastExitCode
+			}
+		})
+	}
+	return exitCodes
+}
+
+// GetPlaceholders returns the names of the placeholders of the command at a certain position in the forest
+func (forest *CForest) GetPlaceholders(position int) []string {
+	node := forest.getTree(position)
+	if node == nil {
+		return nil
+	}
+	return Placeholders(node.toCommand())
+}
+
+// GetSources returns the commands that list the potential values of the placeholders
+// of the command at a certain position in the forest, by name
+func (forest *CForest) GetSources(position int) map[string]string {
+	node := forest.getTree(position)
+	if node == nil {
+		return nil
+	}
+	return Sources(node.toCommand())
+}
+
+// GetMissingValues returns the placeholders of the command at a certain position that have no value yet.
+// The command cannot run until they have one.
+func (forest *CForest) GetMissingValues(position int) []string {
+	node := forest.getTree(position)
+	if node == nil {
+		return nil
+	}
+	return node.missingValues()
+}
+
+// GetValues returns the values of the placeholders of the command at a certain position the last time it ran.
+// Placeholders the command never had a value for get the last value of its parent commands, if any.
+func (forest *CForest) GetValues(position int) map[string]string {
+	values := map[string]string{}
+	for node := forest.getTree(position); node != nil; node = node.Parent {
+		for name, value := range node.Meta.Values {
+			if _, ok := values[name]; !ok {
+				values[name] = value
+			}
+		}
+	}
+	return values
+}
+
+// SetValues sets the values of the placeholders of the command at a certain position.
+// The command in the tree doesn't change, its placeholders are replaced only when it runs.
+func (forest *CForest) SetValues(position int, values map[string]string) error {
+	node := forest.getTree(position)
+	if node == nil {
+		return errors.New("Command not found")
+	}
+
+	node.setValues(values)
+	if forest.journal == nil {
+		return nil
+	}
+	return forest.journal.AppendValues(node.toCommand(), values)
+}
+
+// GetSourceValues runs the source of a placeholder and returns the values it lists.
+// Sources that ran less than SourceMaxAge ago list the values of their cached output instead.
+func (forest *CForest) GetSourceValues(source string) ([]string, error) {
+	cmd, err := forest.GetSourceCmd(source)
+	if err != nil {
+		return nil, err
+	}
+	output := cmd.Run(cmd.IsFresh(SourceMaxAge))
+	return SourceValues(*output.Output), nil
+}
+
+// GetSourceCmd returns the executable command of the source of a placeholder.
+// Sources that are also commands in the forest share their cached output with them.
+func (forest *CForest) GetSourceCmd(source string) (*Cmd, error) {
+	source = forest.Qualify(source)
+	if missing := Placeholders(source); len(missing) > 0 {
+		return nil, fmt.Errorf("Fill in {{%s}} first", missing[0])
+	}
+	if node := forest.find(source); node != nil {
+		return forest.cmd(node), nil
+	}
+
+	if cmd, ok := forest.sources[source]; ok {
+		cmd.SetPolicy(forest.Policy)
+		cmd.SetExecutor(forest.Executor)
+		cmd.SetKubeContext(forest.context(forest.rootOf(source)))
+		return cmd, nil
+	}
+	words, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, 0, len(words)-1)
+	for _, w := range words[1:] {
+		args = append(args, w.value)
+	}
+	if forest.sources == nil {
+		forest.sources = map[string]*Cmd{}
+	}
+	forest.sources[source] = NewCmd(words[0].value, args...)
+	forest.sources[source].SetPolicy(forest.Policy)
+	forest.sources[source].SetExecutor(forest.Executor)
+	forest.sources[source].SetKubeContext(forest.context(words[0].value))
+	return forest.sources[source], nil
+}
+
+// SetTimeout sets how long each attempt of the command at a certain position may take.
+// 0 means the command takes the timeout of the forest policy.
+func (forest *CForest) SetTimeout(position int, timeout time.Duration) error {
+	node := forest.getTree(position)
+	if node == nil {
+		return errors.New("Command not found")
+	}
+	if timeout < 0 {
+		return errors.New("Timeout cannot be negative")
+	}
+
+	node.Meta.Timeout = timeout
+	if forest.journal == nil {
+		return nil
+	}
+	return forest.journal.AppendTimeout(node.toCommand(), timeout)
+}
+
+// GetTimeout returns the timeout of the command at a certain position, or 0 if it takes the timeout of the forest policy
+func (forest *CForest) GetTimeout(position int) time.Duration {
+	node := forest.getTree(position)
+	if node == nil {
+		return 0
+	}
+	return node.Meta.Timeout
+}
+
+// SetStreaming sets whether the command at a certain position streams its output,
+// even if its flags do not say it follows its output
+func (forest *CForest) SetStreaming(position int, stream bool) error {
+	node := forest.getTree(position)
+	if node == nil {
+		return errors.New("Command not found")
+	}
+
+	node.Meta.Stream = stream
+	if forest.journal == nil {
+		return nil
+	}
+	return forest.journal.AppendStream(node.toCommand(), stream)
+}
+
+// GetStreaming returns whether the command at a certain position was set to stream its output
+func (forest *CForest) GetStreaming(position int) bool {
+	node := forest.getTree(position)
+	return node != nil && node.Meta.Stream
+}
+
+// SetContext sets the kube context the commands of the tree at a certain position run against.
+// An empty context makes them run against the current context of the kubeconfig.
+func (forest *CForest) SetContext(position int, context string) error {
+	tree, _ := forest.locate(position)
+	if tree == nil {
+		return errors.New("Command not found")
+	}
+	if !TakesKubeContext(tree.Part) {
+		return fmt.Errorf("%s commands do not take a kube context", tree.Part)
+	}
+
+	tree.Meta.Context = context
+	if forest.journal == nil {
+		return nil
+	}
+	return forest.journal.AppendContext(tree.Part, context)
+}
+
+// GetContext returns the kube context the commands of the tree at a certain position were set to run against,
+// or nothing if they run against the current context of the kubeconfig
+func (forest *CForest) GetContext(position int) string {
+	tree, _ := forest.locate(position)
+	if tree == nil {
+		return ""
+	}
+	return tree.Meta.Context
+}
+
+// GetRoot returns the binary of the tree at a certain position (e.g. kubectl)
+func (forest *CForest) GetRoot(position int) string {
+	tree, _ := forest.locate(position)
+	if tree == nil {
+		return ""
+	}
+	return tree.Part
+}
+
+// context returns the kube context the commands of a binary run against, if it is not the current one of the kubeconfig
+func (forest *CForest) context(root string) string {
+	if tree := forest.tree(root); tree != nil && tree.Meta.Context != "" {
+		return tree.Meta.Context
+	}
+	return forest.Context
+}
+
+// CopyCommand copies the command at a certain position, and all its children commands, into another forest
+// with their settings (e.g. the values of their placeholders). It returns how many commands were copied.
+func (forest *CForest) CopyCommand(position int, target *CForest) (int, error) {
+	node := forest.getTree(position)
+	if node == nil {
+		return 0, errors.New("Command not found")
+	}
+
+	commands := node.Serialize()
+	for _, command := range commands {
+		if err := target.MergeCommand(command); err != nil {
+			return 0, err
+		}
+	}
+
+	var err error
+	node.walk(func(source *CTree) {
+		if copied := target.find(source.toCommand()); copied != nil && err == nil {
+			err = target.copyMeta(copied, source.Meta)
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(commands), nil
+}
+
+// copyMeta merges the settings of a command of another forest into the metadata of a node.
+// How the command ran in the other forest is left out, and so is the kube context of its tree.
+func (forest *CForest) copyMeta(node *CTree, meta CMeta) error {
+	node.Meta.merge(CMeta{Notes: meta.Notes, Pinned: meta.Pinned, Values: meta.Values, Timeout: meta.Timeout, Stream: meta.Stream})
+	return forest.recordMeta(node)
+}
+
+// recordMeta writes the settings of a node that the journal keeps to the journal of the forest, if it has one
+func (forest *CForest) recordMeta(node *CTree) error {
+	if forest.journal == nil {
+		return nil
+	}
+
+	command := node.toCommand()
+	if len(node.Meta.Values) > 0 {
+		if err := forest.journal.AppendValues(command, node.Meta.Values); err != nil {
+			return err
+		}
+	}
+	if node.Meta.Timeout > 0 {
+		if err := forest.journal.AppendTimeout(command, node.Meta.Timeout); err != nil {
+			return err
+		}
+	}
+	if node.Meta.Stream {
+		return forest.journal.AppendStream(command, true)
+	}
+	return nil
+}
+
+// CloneCommand copies the command at a certain position, and all its children commands, with a value replaced
+// in their arguments (e.g. the namespace team-a by team-b), along with their settings.
+// It returns how many commands were cloned.
+func (forest *CForest) CloneCommand(position int, old, new string) (int, error) {
+	tree, position := forest.locate(position)
+	if tree == nil {
+		return 0, errors.New("Command not found")
+	}
+	copies, err := tree.Clone(position, old, new)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, copied := range copies {
+		if err := forest.record(JournalMerge, copied.toCommand()); err != nil {
+			return 0, err
+		}
+		if err := forest.recordMeta(copied); err != nil {
+			return 0, err
+		}
+	}
+	return len(copies), nil
+}
+
+// PreviewClone returns the commands that cloning the command at a certain position would add,
+// one for each command without children
+func (forest *CForest) PreviewClone(position int, old, new string) ([]Change, error) {
+	node := forest.getTree(position)
+	if node == nil {
+		return nil, errors.New("Command not found")
+	}
+	changes, err := node.cloneChanges(old, new)
+	if err != nil {
+		return nil, err
+	}
+
+	var leaves []Change
+	for _, change := range changes {
+		if len(change.node.Children) == 0 {
+			leaves = append(leaves, change)
+		}
+	}
+	return leaves, nil
+}
+
+// ReplacePart replaces some text in the parts of every command of the forest, but the roots of its trees
+// (e.g. -n team-a by -n team-b). Commands that end up the same are merged. It returns how many parts changed.
+func (forest *CForest) ReplacePart(old, new string) (int, error) {
+	count, err := forest.replacePart(old, new)
+	if err != nil || count == 0 {
+		return count, err
+	}
+	if forest.journal == nil {
+		return count, nil
+	}
+	return count, forest.journal.AppendReplace(old, new)
+}
+
+func (forest *CForest) replacePart(old, new string) (int, error) {
+	// Nothing changes unless every part can be replaced
+	replacements := make([]map[*CTree]string, len(forest.Trees))
+	for index, tree := range forest.Trees {
+		parts, err := tree.replacements(old, new)
+		if err != nil {
+			return 0, err
+		}
+		replacements[index] = parts
+	}
+
+	count := 0
+	for index, tree := range forest.Trees {
+		tree.replaceParts(replacements[index])
+		count += len(replacements[index])
+	}
+	return count, nil
+}
+
+// PreviewReplace returns the commands without children that change when some text is replaced
+// in the parts of every command of the forest
+func (forest *CForest) PreviewReplace(old, new string) ([]Change, error) {
+	var changes []Change
+	for _, tree := range forest.Trees {
+		parts, err := tree.replacements(old, new)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, tree.previewReplace(parts)...)
+	}
+	return changes, nil
+}
+
+// getTree returns the node at a certain position in the forest
+func (forest *CForest) getTree(position int) *CTree {
+	tree, position := forest.locate(position)
+	if tree == nil {
+		return nil
+	}
+	return tree.getTree(&position)
+}
+
+// find returns the node of a command in the forest
+func (forest *CForest) find(command string) *CTree {
+	position := forest.GetPosition(command)
+	if position == nil {
+		return nil
+	}
+	return forest.getTree(*position)
+}
+
+// GetCommand returns the command at a certain position in the forest exactly as it was typed
+func (forest *CForest) GetCommand(position int) *string {
+	tree, position := forest.locate(position)
+	if tree == nil {
+		return nil
+	}
+	return tree.GetCommand(position)
+}
+
+// locate returns the tree that contains a position in the forest, and the position within that tree
+func (forest *CForest) locate(position int) (*CTree, int) {
+	if position <= 0 {
+		return nil, 0
+	}
+	for _, tree := range forest.Trees {
+		size := tree.size()
+		if position <= size {
+			return tree, position
+		}
+		position -= size
+	}
+	return nil, 0
+}
+
+// GetPosition returns the position of a command in the forest
+// (depth-first search, one tree after the other)
+func (forest *CForest) GetPosition(command string) *int {
+	root := forest.rootOf(command)
+	offset := 0
+	for _, tree := range forest.Trees {
+		if tree.Part == root {
+			position := tree.GetPosition(command)
+			if position != nil {
+				*position += offset
+			}
+			return position
+		}
+		offset += tree.size()
+	}
+	return nil
+}
+
+// GetNextParts returns a list of potential next parts for a given command
+func (forest *CForest) GetNextParts(command string) []string {
+	fields := strings.Fields(command)
+	if len(fields) == 1 && !strings.HasSuffix(command, " ") {
+		var roots []string
+		for _, tree := range forest.Trees {
+			if strings.HasPrefix(tree.Part, fields[0]) {
+				roots = append(roots, tree.Part)
+			}
+		}
+		return roots
+	}
+
+	if tree := forest.tree(forest.rootOf(command)); tree != nil {
+		return tree.GetNextParts(command)
+	}
+	return nil
+}
+
+// RemoveCommand removes a command and all its children commands from the forest.
+// Removing the root of a tree removes the whole tree.
+func (forest *CForest) RemoveCommand(position int) error {
+	node := forest.getTree(position)
+	if node == nil {
+		return errors.New("Command not found")
+	}
+
+	command := node.toCommand()
+	forest.removeTree(node)
+	return forest.record(JournalRemove, command)
+}
+
+func (forest *CForest) removeTree(node *CTree) {
+	if node.Parent != nil {
+		node.remove()
+		return
+	}
+
+	for index, tree := range forest.Trees {
+		if tree == node {
+			forest.Trees = append(forest.Trees[:index], forest.Trees[index+1:]...)
+			return
+		}
+	}
+}
+
+// replaceTrees replaces the trees of the forest with the trees of another forest,
+// keeping the cached executions of the commands that are in both, and the ones that are still running
+func (forest *CForest) replaceTrees(other *CForest) {
+	for _, tree := range other.Trees {
+		tree.walk(func(node *CTree) {
+			if current := forest.find(node.toCommand()); current != nil {
+				node.Cmd, node.stale = current.Cmd, current.stale
+			}
+		})
+	}
+	for _, root := range other.Roots {
+		if !forest.IsRoot(root) {
+			forest.Roots = append(forest.Roots, root)
+		}
+	}
+	forest.Trees = other.Trees
+}
+
+// ToStrings returns a list of indented command parts of all trees.
+// Example output:
+//   "kubectl"
+//   "  -n kubeflow"
+//   "    get"
+//   "      pod"
+//   "helm"
+//   "  list"
+func (forest *CForest) ToStrings(tabSize int) []string {
+	var all []string
+	for _, tree := range forest.Trees {
+		all = append(all, tree.ToStrings(tabSize)...)
+	}
+	return all
+}
+
+// Serialize returns the complete list of commands required to rebuild the forest from scratch
+// Example output:
+//   "kubectl -n kubeflow get pod"
+//   "helm list"
+func (forest *CForest) Serialize() []string {
+	var all []string
+	for _, tree := range forest.Trees {
+		all = append(all, tree.Serialize()...)
+	}
+	return all
+}
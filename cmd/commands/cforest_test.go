@@ -0,0 +1,694 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCForest_NewNil(t *testing.T) {
+	// Arrange
+
+	// Act
+	forest, err := NewCForest(DefaultRoots, nil)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.EqualValues(t, DefaultRoots, forest.Roots)
+	assert.Len(t, forest.Trees, 0)
+}
+
+func TestCForest_NewNoRoots(t *testing.T) {
+	// Arrange
+
+	// Act
+	forest, err := NewCForest(nil, nil)
+
+	// Assert
+	assert.Nil(t, forest)
+	assert.NotNil(t, err)
+}
+
+func TestCForest_New(t *testing.T) {
+	// Arrange
+	commands := []string{
+		"helm list",
+		"kubectl -n kubeflow get pod",
+		"kind get clusters",
+		"kubectl -n kubeflow get cronjob",
+	}
+
+	// Act
+	forest, err := NewCForest([]string{"kubectl", "helm", "kind"}, commands)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Len(t, forest.Trees, 3)
+	assert.Equal(t, "kubectl", forest.Trees[0].Part)
+	assert.Equal(t, "helm", forest.Trees[1].Part)
+	assert.Equal(t, "kind", forest.Trees[2].Part)
+	assert.Len(t, forest.Trees[0].Children[0].Children[0].Children, 2)
+}
+
+func TestCForest_NewInvalid(t *testing.T) {
+	// Arrange
+	commands := []string{
+		"kubectl -n kubeflow get pod",
+		"az login",
+	}
+
+	// Act
+	forest, err := NewCForest([]string{"kubectl", "helm"}, commands)
+
+	// Assert
+	assert.Nil(t, forest)
+	assert.NotNil(t, err)
+}
+
+func TestCForest_Qualify(t *testing.T) {
+	// Arrange
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"No root", "get pod", "kubectl get pod"},
+		{"Default root", "kubectl get pod", "kubectl get pod"},
+		{"Other root", "helm list", "helm list"},
+		{"Only root", "helm", "helm"},
+		{"Empty", "  ", "kubectl"},
+	}
+
+	forest, err := NewCForest(DefaultRoots, nil)
+	assert.Nil(t, err)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			result := forest.Qualify(test.input)
+
+			// Assert
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func TestCForest_GetCmd(t *testing.T) {
+	// Arrange
+	forest, err := NewCForest(DefaultRoots, []string{
+		"kubectl -n kubeflow get pod",
+		"helm -n kubeflow list",
+	})
+	assert.Nil(t, err)
+
+	expected := NewCmd("helm", "-n", "kubeflow", "list")
+
+	// Act
+	result := forest.GetCmd(7)
+
+	// Assert
+	assert.Equal(t, expected, result)
+}
+
+func TestCForest_GetCmdInvalid(t *testing.T) {
+	// Arrange
+	forest, err := NewCForest(DefaultRoots, []string{
+		"kubectl -n kubeflow get pod",
+		"helm -n kubeflow list",
+	})
+	assert.Nil(t, err)
+
+	// Act
+	result := forest.GetCmd(8)
+
+	// Assert
+	assert.Nil(t, result)
+}
+
+func TestCForest_GetPosition(t *testing.T) {
+	// Arrange
+	tests := []struct {
+		name     string
+		input    string
+		expected int
+	}{
+		{"First tree", "kubectl -n kubeflow get", 3},
+		{"Second tree root", "helm", 5},
+		{"Second tree", "helm -n kubeflow list", 7},
+	}
+
+	forest, err := NewCForest(DefaultRoots, []string{
+		"kubectl -n kubeflow get pod",
+		"helm -n kubeflow list",
+	})
+	assert.Nil(t, err)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			result := forest.GetPosition(test.input)
+
+			// Assert
+			assert.Equal(t, test.expected, *result)
+		})
+	}
+}
+
+func TestCForest_GetPositionInvalid(t *testing.T) {
+	// Arrange
+	forest, err := NewCForest(DefaultRoots, []string{
+		"kubectl -n kubeflow get pod",
+	})
+	assert.Nil(t, err)
+
+	// Act
+	result := forest.GetPosition("helm list")
+
+	// Assert
+	assert.Nil(t, result)
+}
+
+func TestCForest_GetNextParts(t *testing.T) {
+	// Arrange
+	forest, err := NewCForest(DefaultRoots, []string{
+		"kubectl -n kubeflow get pod",
+		"kind get clusters",
+		"kustomize build",
+	})
+	assert.Nil(t, err)
+
+	// Act
+	roots := forest.GetNextParts("k")
+	parts := forest.GetNextParts("kind g")
+
+	// Assert
+	assert.EqualValues(t, []string{"kubectl", "kind", "kustomize"}, roots)
+	assert.EqualValues(t, []string{"get"}, parts)
+}
+
+func TestCForest_RemoveCommand(t *testing.T) {
+	// Arrange
+	forest, err := NewCForest(DefaultRoots, []string{
+		"kubectl -n kubeflow get pod",
+		"helm -n kubeflow list",
+		"helm list",
+	})
+	assert.Nil(t, err)
+
+	// Act
+	err = forest.RemoveCommand(6)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"kubectl -n kubeflow get pod", "helm list"}, forest.Serialize())
+}
+
+func TestCForest_RemoveCommandRoot(t *testing.T) {
+	// Arrange
+	forest, err := NewCForest(DefaultRoots, []string{
+		"kubectl -n kubeflow get pod",
+		"helm list",
+	})
+	assert.Nil(t, err)
+
+	// Act
+	err = forest.RemoveCommand(1)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Len(t, forest.Trees, 1)
+	assert.EqualValues(t, []string{"helm list"}, forest.Serialize())
+}
+
+func TestCForest_RemoveCommandInvalid(t *testing.T) {
+	// Arrange
+	forest, err := NewCForest(DefaultRoots, []string{
+		"kubectl -n kubeflow get pod",
+	})
+	assert.Nil(t, err)
+
+	// Act
+	err = forest.RemoveCommand(10)
+
+	// Assert
+	assert.NotNil(t, err)
+}
+
+func TestCForest_ToStrings(t *testing.T) {
+	// Arrange
+	forest, err := NewCForest(DefaultRoots, []string{
+		"helm list",
+		"kubectl -n kubeflow get pod",
+	})
+	assert.Nil(t, err)
+
+	expected := []string{
+		"kubectl",
+		"  -n kubeflow",
+		"    get",
+		"      pod",
+		"helm",
+		"  list",
+	}
+
+	// Act
+	result := forest.ToStrings(2)
+
+	// Assert
+	assert.EqualValues(t, expected, result)
+}
+
+func TestCForest_Serialize(t *testing.T) {
+	// Arrange
+	expected := []string{
+		"kubectl -n kubeflow get pod",
+		"kubectl -n kubeflow get cronjob",
+		"helm -n kubeflow list",
+		"oc get route -l 'app in (a,b)'",
+	}
+	forest, err := NewCForest(DefaultRoots, expected)
+	assert.Nil(t, err)
+
+	// Act
+	result := forest.Serialize()
+
+	// Assert
+	assert.EqualValues(t, expected, result)
+}
+
+func TestCForest_RunCmd(t *testing.T) {
+	// Arrange
+	forest, err := NewCForest([]string{"printf"}, []string{"printf %s test"})
+	assert.Nil(t, err)
+
+	// Act
+	first := forest.RunCmd(3, true)
+	second := forest.RunCmd(3, true)
+	third := forest.RunCmd(3, false)
+
+	// Assert
+	assert.Equal(t, "test", *first.CmdOutput.Output)
+	assert.Equal(t, first, second)
+	assert.Equal(t, first, third)
+	meta := forest.Trees[0].Children[0].Children[0].Meta
+	assert.Equal(t, 2, meta.RunCount)
+	assert.Equal(t, third.RunTime, meta.LastRun)
+}
+
+func TestCForest_RunCmdInvalid(t *testing.T) {
+	// Arrange
+	forest, err := NewCForest(DefaultRoots, nil)
+	assert.Nil(t, err)
+
+	// Act
+	result := forest.RunCmd(1, true)
+
+	// Assert
+	assert.Nil(t, result)
+}
+
+func TestCForest_RunCmdPlaceholders(t *testing.T) {
+	// Arrange
+	forest, err := NewCForest([]string{"printf"}, []string{"printf %s-%s {{first}} {{second}}"})
+	assert.Nil(t, err)
+
+	// Act
+	missing := forest.GetMissingValues(4)
+	err = forest.SetValues(4, map[string]string{"first": "a", "second": "b c"})
+	first := forest.RunCmd(4, false)
+	err2 := forest.SetValues(4, map[string]string{"first": "d", "second": "e"})
+	second := forest.RunCmd(4, true)
+
+	// Assert
+	assert.EqualValues(t, []string{"first", "second"}, missing)
+	assert.Nil(t, err)
+	assert.Nil(t, err2)
+	assert.Equal(t, "a-b c", *first.CmdOutput.Output)
+	assert.Equal(t, "d-e", *second.CmdOutput.Output)
+	assert.Len(t, forest.GetMissingValues(4), 0)
+	assert.EqualValues(t, []string{"printf %s-%s {{first}} {{second}}"}, forest.Serialize())
+}
+
+func TestCForest_GetValues(t *testing.T) {
+	// Arrange
+	forest, err := NewCForest(DefaultRoots, []string{
+		"kubectl -n {{namespace}} get pod",
+		"kubectl -n {{namespace}} logs {{pod}}",
+	})
+	assert.Nil(t, err)
+	err = forest.SetValues(2, map[string]string{"namespace": "kubeflow"})
+	assert.Nil(t, err)
+	err = forest.SetValues(6, map[string]string{"namespace": "default", "pod": "web"})
+	assert.Nil(t, err)
+
+	// Act
+	inherited := forest.GetValues(4)
+	own := forest.GetValues(6)
+
+	// Assert
+	assert.EqualValues(t, []string{"namespace"}, forest.GetPlaceholders(4))
+	assert.EqualValues(t, map[string]string{"namespace": "kubeflow"}, inherited)
+	assert.EqualValues(t, map[string]string{"namespace": "default", "pod": "web"}, own)
+}
+
+func TestCForest_SetValuesInvalid(t *testing.T) {
+	// Arrange
+	forest, err := NewCForest(DefaultRoots, nil)
+	assert.Nil(t, err)
+
+	// Act
+	err = forest.SetValues(1, map[string]string{"pod": "web"})
+
+	// Assert
+	assert.NotNil(t, err)
+}
+
+func TestCForest_GetSourceValues(t *testing.T) {
+	// Arrange
+	forest, err := NewCForest([]string{"printf"}, nil)
+	assert.Nil(t, err)
+	source := `printf 'web\ndb\n'`
+
+	// Act
+	values, err := forest.GetSourceValues(source)
+	runTime := forest.sources[source].RunTime
+	cached, cachedErr := forest.GetSourceValues(source)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Nil(t, cachedErr)
+	assert.EqualValues(t, []string{"web", "db"}, values)
+	assert.EqualValues(t, values, cached)
+	assert.Equal(t, runTime, forest.sources[source].RunTime)
+}
+
+func TestCForest_GetSourceValuesFromTree(t *testing.T) {
+	// Arrange
+	forest, err := NewCForest([]string{"printf"}, []string{"printf web"})
+	assert.Nil(t, err)
+	cmd := forest.RunCmd(2, false)
+
+	// Act
+	values, err := forest.GetSourceValues("printf web")
+
+	// Assert
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"web"}, values)
+	assert.Equal(t, cmd.RunTime, forest.GetCmd(2).RunTime)
+	assert.Nil(t, forest.sources)
+}
+
+func TestCForest_GetSourceValuesMissingValues(t *testing.T) {
+	// Arrange
+	forest, err := NewCForest(DefaultRoots, nil)
+	assert.Nil(t, err)
+
+	// Act
+	values, err := forest.GetSourceValues("kubectl -n {{namespace}} get pod -o name")
+
+	// Assert
+	assert.Nil(t, values)
+	assert.NotNil(t, err)
+}
+
+func TestCForest_RecordRun(t *testing.T) {
+	// Arrange
+	forest, err := NewCForest([]string{"sleep"}, []string{"sleep 0.2", "sleep 0"})
+	assert.Nil(t, err)
+	cmd := forest.GetCmd(2)
+	removed := forest.GetCmd(3)
+	done := make(chan *CmdOutput, 1)
+	cmd.RunAsync(func(output *CmdOutput) { done <- output })
+	running := forest.GetRunning()
+	err = forest.RemoveCommand(3)
+	assert.Nil(t, err)
+
+	// Act
+	recorded := forest.RecordRun(cmd, <-done)
+	notRecorded := forest.RecordRun(removed, removed.Run(false))
+
+	// Assert
+	assert.EqualValues(t, map[int]bool{2: true}, running)
+	assert.True(t, recorded)
+	assert.False(t, notRecorded)
+	assert.True(t, forest.HasCmd(cmd))
+	assert.False(t, forest.HasCmd(removed))
+	assert.Equal(t, 1, forest.Trees[0].Children[0].Meta.RunCount)
+	assert.Equal(t, cmd.GetOutput().RunTime, forest.Trees[0].Children[0].Meta.LastRun)
+	assert.EqualValues(t, map[int]int{2: 0}, forest.GetLastExitCodes())
+	assert.Empty(t, forest.GetRunning())
+}
+
+func TestCForest_RecordRunAfterSetValues(t *testing.T) {
+	// Arrange
+	forest, err := NewCForest([]string{"sleep"}, []string{"sleep {{seconds}}"})
+	assert.Nil(t, err)
+	err = forest.SetValues(2, map[string]string{"seconds": "0.2"})
+	assert.Nil(t, err)
+	cmd := forest.GetCmd(2)
+	done := make(chan *CmdOutput, 1)
+	cmd.RunAsync(func(output *CmdOutput) { done <- output })
+	err = forest.SetValues(2, map[string]string{"seconds": "0"})
+	assert.Nil(t, err)
+
+	// Act
+	recorded := forest.RecordRun(cmd, <-done)
+	recordedTwice := forest.RecordRun(cmd, cmd.Run(false))
+
+	// Assert
+	assert.True(t, recorded)
+	assert.False(t, recordedTwice)
+	assert.False(t, forest.HasCmd(cmd))
+	assert.Equal(t, 1, forest.Trees[0].Children[0].Meta.RunCount)
+	assert.Equal(t, []string{"sleep", "0"}, forest.GetCmd(2).Args)
+}
+
+func TestCForest_SetTimeout(t *testing.T) {
+	// Arrange
+	forest, err := NewCForest([]string{"sleep"}, []string{"sleep 10"})
+	assert.Nil(t, err)
+	forest.Policy = Policy{Timeout: time.Hour}
+
+	// Act
+	err = forest.SetTimeout(2, 50*time.Millisecond)
+	invalidErr := forest.SetTimeout(2, -time.Second)
+	output := forest.RunCmd(2, false).GetOutput()
+
+	// Assert
+	assert.Nil(t, err)
+	assert.NotNil(t, invalidErr)
+	assert.Equal(t, 50*time.Millisecond, forest.GetTimeout(2))
+	assert.True(t, output.Attempts[0].TimedOut)
+	assert.Equal(t, time.Duration(0), forest.GetTimeout(1))
+}
+
+func TestCForest_StreamingPolicy(t *testing.T) {
+	// Arrange
+	forest, err := NewCForest([]string{"kubectl"}, []string{"kubectl logs -f web", "kubectl logs -f db", "kubectl get pod"})
+	assert.Nil(t, err)
+	forest.Policy = Policy{Timeout: time.Minute, Retry: RetryPolicy{MaxAttempts: 3}}
+	err = forest.SetTimeout(6, time.Hour)
+	assert.Nil(t, err)
+
+	// Act
+	streaming := forest.GetCmd(4)
+	timedOut := forest.GetCmd(6)
+	other := forest.GetCmd(8)
+
+	// Assert
+	assert.Equal(t, Policy{}, streaming.policy)
+	assert.Equal(t, Policy{Timeout: time.Hour}, timedOut.policy)
+	assert.Equal(t, forest.Policy, other.policy)
+}
+
+func TestCForest_SetContext(t *testing.T) {
+	// Arrange
+	forest, err := NewCForest([]string{"kubectl", "sleep"}, []string{"kubectl get pod", "sleep 10"})
+	assert.Nil(t, err)
+
+	// Act
+	err = forest.SetContext(3, "aks-prod")
+	unknownErr := forest.SetContext(20, "aks-prod")
+	rootErr := forest.SetContext(4, "aks-prod")
+
+	// Assert
+	assert.Nil(t, err)
+	assert.NotNil(t, unknownErr)
+	assert.NotNil(t, rootErr)
+	assert.Equal(t, "aks-prod", forest.GetContext(1))
+	assert.Equal(t, "kubectl", forest.GetRoot(3))
+	assert.EqualValues(t, []string{"kubectl", "--context", "aks-prod", "get", "pod"}, forest.GetCmd(3).ExecArgs())
+	assert.EqualValues(t, []string{"kubectl", "get", "pod"}, forest.GetCmd(3).Args)
+	assert.Equal(t, "", forest.GetContext(4))
+}
+
+func TestCForest_Context(t *testing.T) {
+	// Arrange
+	forest, err := NewCForest([]string{"kubectl", "helm"}, []string{"kubectl get pod", "helm list"})
+	assert.Nil(t, err)
+	forest.Context = "aks-dev"
+	err = forest.SetContext(1, "aks-prod")
+	assert.Nil(t, err)
+
+	// Act
+	kubectl := forest.GetCmd(3).ExecArgs()
+	helm := forest.GetCmd(5).ExecArgs()
+
+	// Assert
+	assert.EqualValues(t, []string{"kubectl", "--context", "aks-prod", "get", "pod"}, kubectl)
+	assert.EqualValues(t, []string{"helm", "--kube-context", "aks-dev", "list"}, helm)
+}
+
+func TestCForest_CopyCommand(t *testing.T) {
+	// Arrange
+	forest, err := NewCForest([]string{"kubectl"}, []string{
+		"kubectl -n kubeflow get pod",
+		"kubectl -n kubeflow logs {{pod}}",
+		"kubectl get ns",
+	})
+	assert.Nil(t, err)
+	err = forest.SetValues(6, map[string]string{"pod": "web"})
+	assert.Nil(t, err)
+	err = forest.SetTimeout(4, time.Minute)
+	assert.Nil(t, err)
+	err = forest.SetContext(1, "aks-dev")
+	assert.Nil(t, err)
+	forest.RunCmd(4, false)
+	target, err := NewCForest([]string{"kubectl"}, []string{"kubectl get node"})
+	assert.Nil(t, err)
+
+	// Act
+	count, err := forest.CopyCommand(2, target)
+	_, notFoundErr := forest.CopyCommand(20, target)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.NotNil(t, notFoundErr)
+	assert.Equal(t, 2, count)
+	assert.EqualValues(t, []string{
+		"kubectl get node",
+		"kubectl -n kubeflow get pod",
+		"kubectl -n kubeflow logs {{pod}}",
+	}, target.Serialize())
+	copied := target.find("kubectl -n kubeflow logs {{pod}}")
+	assert.EqualValues(t, map[string]string{"pod": "web"}, copied.Meta.Values)
+	assert.Equal(t, time.Minute, target.find("kubectl -n kubeflow get pod").Meta.Timeout)
+	assert.Equal(t, 0, target.find("kubectl -n kubeflow get pod").Meta.RunCount)
+	assert.Equal(t, "", target.Trees[0].Meta.Context)
+}
+
+func TestCForest_CloneCommand(t *testing.T) {
+	// Arrange
+	forest, err := NewCForest([]string{"kubectl", "helm"}, []string{
+		"helm list -n team-a",
+		"kubectl -n team-a get pod",
+		"kubectl -n team-a get deployment/team-a",
+	})
+	assert.Nil(t, err)
+	err = forest.SetTimeout(4, time.Minute)
+	assert.Nil(t, err)
+
+	// Act
+	preview, previewErr := forest.PreviewClone(2, "team-a", "team-b")
+	count, err := forest.CloneCommand(2, "team-a", "team-b")
+	_, notFoundErr := forest.CloneCommand(20, "team-a", "team-b")
+
+	// Assert
+	assert.Nil(t, previewErr)
+	assert.Equal(t, []string{"kubectl -n team-b get pod", "kubectl -n team-b get deployment/team-b"},
+		[]string{preview[0].After, preview[1].After})
+	assert.Equal(t, "kubectl -n team-a get pod", preview[0].Before)
+	assert.Nil(t, err)
+	assert.NotNil(t, notFoundErr)
+	assert.Equal(t, 4, count)
+	assert.EqualValues(t, []string{
+		"kubectl -n team-a get pod",
+		"kubectl -n team-a get deployment/team-a",
+		"kubectl -n team-b get pod",
+		"kubectl -n team-b get deployment/team-b",
+		"helm list -n team-a",
+	}, forest.Serialize())
+	assert.Equal(t, time.Minute, forest.find("kubectl -n team-b get pod").Meta.Timeout)
+}
+
+func TestCForest_ReplacePart(t *testing.T) {
+	// Arrange
+	forest, err := NewCForest([]string{"kubectl", "helm"}, []string{
+		"kubectl -n team-a get pod",
+		"kubectl -n team-b get pod",
+		"kubectl get ns",
+		"helm list -n team-a",
+	})
+	assert.Nil(t, err)
+	cmd := forest.GetCmd(4)
+
+	// Act
+	preview, previewErr := forest.PreviewReplace("team-a", "team-c")
+	count, err := forest.ReplacePart("team-a", "team-c")
+	_, invalidErr := forest.ReplacePart("pod", "'pod")
+
+	// Assert
+	assert.Nil(t, previewErr)
+	assert.Equal(t, []Change{
+		{Before: "kubectl -n team-a get pod", After: "kubectl -n team-c get pod", node: preview[0].node},
+		{Before: "helm list -n team-a", After: "helm list -n team-c", node: preview[1].node},
+	}, preview)
+	assert.Nil(t, err)
+	assert.NotNil(t, invalidErr)
+	assert.Equal(t, 2, count)
+	assert.EqualValues(t, []string{
+		"kubectl -n team-c get pod",
+		"kubectl -n team-b get pod",
+		"kubectl get namespace",
+		"helm list -n team-c",
+	}, forest.Serialize())
+	assert.NotEqual(t, cmd, forest.GetCmd(4))
+	assert.Equal(t, []string{"kubectl", "-n", "team-c", "get", "pod"}, forest.GetCmd(4).Args)
+}
+
+func TestCForest_GetLeafCmds(t *testing.T) {
+	// Arrange
+	forest, err := NewCForest([]string{"kubectl"}, []string{
+		"kubectl -n kubeflow get pod",
+		"kubectl -n kubeflow get cronjob",
+		"kubectl -n kubeflow get pod -w",
+		"kubectl -n kubeflow logs {{pod}}",
+		"kubectl get ns",
+	})
+	assert.Nil(t, err)
+
+	// Act
+	result := forest.GetLeafCmds(2)
+	leaf := forest.GetLeafCmds(9)
+	notFound := forest.GetLeafCmds(20)
+
+	// Assert
+	var commands []string
+	for _, cmd := range result {
+		commands = append(commands, cmd.ToString())
+	}
+	assert.EqualValues(t, []string{"kubectl -n kubeflow get cronjob"}, commands)
+	assert.Len(t, leaf, 1)
+	assert.Equal(t, "kubectl get namespace", leaf[0].ToString())
+	assert.Nil(t, notFound)
+}
+
+func TestCForest_SetStreaming(t *testing.T) {
+	// Arrange
+	forest, err := NewCForest([]string{"kubectl"}, []string{"kubectl get pod", "kubectl get pod -w"})
+	assert.Nil(t, err)
+
+	// Act
+	err = forest.SetStreaming(3, true)
+	notFoundErr := forest.SetStreaming(10, true)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.NotNil(t, notFoundErr)
+	assert.True(t, forest.GetStreaming(3))
+	assert.True(t, forest.GetCmd(3).IsStreaming())
+	assert.False(t, forest.GetStreaming(4))
+	assert.True(t, forest.GetCmd(4).IsStreaming())
+	assert.False(t, forest.GetCmd(2).IsStreaming())
+}
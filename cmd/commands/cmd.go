@@ -1,62 +1,323 @@
 package commands
 
 import (
+	"bytes"
+	"context"
 	"fmt"
-	"os/exec"
-	"strings"
+	"io"
+	"os"
+	"sync"
 	"time"
 )
 
 // CmdOutput represents the cached output of an executable command
 type CmdOutput struct {
-	Output  *string
-	RunTime *time.Time
+	// Output is what the command wrote to its standard output
+	Output *string
+	// Stderr is what the command wrote to its standard error
+	Stderr string
+	// ExitCode is 0 if the command succeeded, or -1 if it could not start or was killed
+	ExitCode  int
+	StartTime *time.Time
+	// RunTime is when the command finished running
+	RunTime  *time.Time
+	Duration time.Duration
+	// Env is the environment the command ran with
+	Env       []string
+	Cancelled bool
+	// Attempts are the times the command ran, the last one being the one whose output this is
+	Attempts    []CmdAttempt
+	MaxAttempts int
 }
 
-// Cmd represents an executable command
+// CmdAttempt represents one of the times a command ran, according to its retry policy
+type CmdAttempt struct {
+	Output    string
+	Stderr    string
+	ExitCode  int
+	TimedOut  bool
+	StartTime time.Time
+	Duration  time.Duration
+}
+
+// Cmd represents an executable command. It is safe to run it in the background
+// while its output is read, or it is cancelled, from other goroutines.
 type Cmd struct {
-	exec.Cmd
+	// Path is the binary of the command, and Args are its arguments, starting with the binary
+	Path string
+	Args []string
 	CmdOutput
+	mutex    sync.Mutex
+	policy   Policy
+	attempt  int
+	cancel   context.CancelFunc
+	finished chan struct{}
+	// streaming makes the command stream its output even if its flags do not say it follows its output
+	streaming bool
+	stream    *LineBuffer
+	executor  Executor
+	// kubeContext is the kube context the command runs against, if it is not the current context of the kubeconfig
+	kubeContext string
+}
+
+// outputWriter is where a command writes its output
+type outputWriter interface {
+	io.Writer
+	fmt.Stringer
 }
 
 // NewCmd creates an executable command
 func NewCmd(name string, arg ...string) *Cmd {
-	return &Cmd{Cmd: *exec.Command(name, arg...)}
+	return &Cmd{Path: name, Args: append([]string{name}, arg...)}
 }
 
-// ToString converts an executable command to a string
+// ToString converts an executable command to a string, quoting arguments where a shell would need it
 func (cmd *Cmd) ToString() string {
-	var toString string
 	if len(cmd.Args) > 0 {
-		toString = cmd.Args[0]
-		for _, arg := range cmd.Args[1:] {
-			toString = strings.Join([]string{toString, arg}, " ")
-		}
-	} else {
-		toString = cmd.Path
+		return commandLine(cmd.Args)
 	}
-	return toString
+	return cmd.Path
 }
 
-// Run executes an executable command
+// Run executes an executable command and waits for it to finish.
+// If the command is already running in the background, it waits for that execution instead.
 func (cmd *Cmd) Run(cacheFirst bool) *CmdOutput {
-	if cacheFirst && cmd.CmdOutput.Output != nil {
-		return &cmd.CmdOutput
+	if output := cmd.GetOutput(); cacheFirst && output.Output != nil {
+		return &output
+	}
+
+	result := make(chan *CmdOutput, 1)
+	if !cmd.RunAsync(func(output *CmdOutput) { result <- output }) {
+		cmd.waitRunning()
+		output := cmd.GetOutput()
+		return &output
+	}
+	return <-result
+}
+
+// RunAsync executes an executable command in the background and calls done with its output once it finishes.
+// It returns false without running the command if it is already running.
+func (cmd *Cmd) RunAsync(done func(output *CmdOutput)) bool {
+	cmd.mutex.Lock()
+	defer cmd.mutex.Unlock()
+	if cmd.finished != nil {
+		return false
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd.cancel, cmd.finished, cmd.stream = cancel, make(chan struct{}), nil
+	go func() {
+		defer cancel()
+		output := cmd.execute(ctx)
+		done(&output)
+	}()
+	return true
+}
+
+// execute runs the command as many times as its retry policy allows, until an attempt succeeds
+func (cmd *Cmd) execute(ctx context.Context) CmdOutput {
+	cmd.mutex.Lock()
+	policy := cmd.policy
+	cmd.mutex.Unlock()
+
+	env := os.Environ()
+	var attempts []CmdAttempt
+	for number := 1; ; number++ {
+		cmd.mutex.Lock()
+		cmd.attempt = number
+		cmd.mutex.Unlock()
+
+		attempt := cmd.executeAttempt(ctx, policy.Timeout, env)
+		attempts = append(attempts, attempt)
+		if ctx.Err() != nil || number >= policy.Retry.attempts() || !policy.Retry.retries(&attempt) {
+			break
+		}
+
+		timer := time.NewTimer(policy.Retry.backoff(number))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	last := attempts[len(attempts)-1]
+	output, stderr := last.Output, last.Stderr
+	cancelled := ctx.Err() == context.Canceled
+	if cancelled {
+		stderr = fmt.Sprintf("%sCancelled\n", stderr)
+	}
+	startTime, now := attempts[0].StartTime, time.Now()
+
+	cmd.mutex.Lock()
+	defer cmd.mutex.Unlock()
+	cmd.CmdOutput = CmdOutput{
+		Output:      &output,
+		Stderr:      stderr,
+		ExitCode:    last.ExitCode,
+		StartTime:   &startTime,
+		RunTime:     &now,
+		Duration:    now.Sub(startTime),
+		Env:         env,
+		Cancelled:   cancelled,
+		Attempts:    attempts,
+		MaxAttempts: policy.Retry.attempts(),
+	}
+	close(cmd.finished)
+	cmd.attempt, cmd.cancel, cmd.finished = 0, nil, nil
+	return cmd.CmdOutput
+}
+
+// executeAttempt runs the command once, killing it if it takes longer than the timeout
+func (cmd *Cmd) executeAttempt(ctx context.Context, timeout time.Duration, env []string) CmdAttempt {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	// Streaming commands may run for hours, so only their last lines are kept
+	var stdout, stderr outputWriter = &bytes.Buffer{}, &bytes.Buffer{}
+	if cmd.IsStreaming() {
+		stream := NewLineBuffer(StreamMaxLines)
+		stdout, stderr = stream, NewLineBuffer(StreamMaxLines)
+		cmd.mutex.Lock()
+		cmd.stream = stream
+		cmd.mutex.Unlock()
+	}
+
+	cmd.mutex.Lock()
+	executor := cmd.executor
+	cmd.mutex.Unlock()
+	if executor == nil {
+		executor = ExecExecutor{}
 	}
 
-	var output string
-	// We cannot call CombinedOutput twice on the same exec.Cmd
-	cmd.Cmd = *exec.Command(cmd.Cmd.Args[0], cmd.Cmd.Args[1:]...)
-	stdoutStderr, err := cmd.Cmd.CombinedOutput()
-	if err != nil {
-		output = fmt.Sprintf("%s", stdoutStderr)
-	} else {
-		output = fmt.Sprintf("%s", stdoutStderr)
+	attempt := CmdAttempt{StartTime: time.Now()}
+	exitCode, err := executor.Execute(ctx, cmd.ExecArgs(), env, stdout, stderr)
+	attempt.Duration = time.Since(attempt.StartTime)
+	attempt.Output, attempt.Stderr, attempt.ExitCode = stdout.String(), stderr.String(), exitCode
+	if err != nil && ctx.Err() == nil {
+		attempt.Stderr = fmt.Sprintf("%s%s\n", attempt.Stderr, err)
 	}
+	if ctx.Err() == context.DeadlineExceeded {
+		attempt.TimedOut = true
+		attempt.Stderr = fmt.Sprintf("%sTimed out after %s\n", attempt.Stderr, timeout)
+	}
+	return attempt
+}
+
+// SetKubeContext sets the kube context the command runs against. An empty context is the current context of the kubeconfig.
+// The output of the command is no longer cached once its context changes, as it comes from another cluster.
+func (cmd *Cmd) SetKubeContext(context string) {
+	cmd.mutex.Lock()
+	defer cmd.mutex.Unlock()
+	if cmd.kubeContext == context {
+		return
+	}
+	cmd.kubeContext = context
+	if cmd.finished == nil {
+		cmd.CmdOutput = CmdOutput{}
+	}
+}
+
+// ExecArgs returns the arguments the command runs with, including the flag of its kube context
+func (cmd *Cmd) ExecArgs() []string {
+	cmd.mutex.Lock()
+	defer cmd.mutex.Unlock()
+	return withKubeContext(cmd.Args, cmd.kubeContext)
+}
+
+// SetExecutor sets what runs the command from now on. A nil executor runs it with the binary installed in the machine.
+func (cmd *Cmd) SetExecutor(executor Executor) {
+	cmd.mutex.Lock()
+	defer cmd.mutex.Unlock()
+	cmd.executor = executor
+}
+
+// SetStreaming sets whether the command streams its output even if its flags do not say it follows its output
+func (cmd *Cmd) SetStreaming(streaming bool) {
+	cmd.mutex.Lock()
+	defer cmd.mutex.Unlock()
+	cmd.streaming = streaming
+}
+
+// IsStreaming returns whether the command streams its output line by line while it runs
+// (e.g. kubectl logs -f), instead of only when it finishes
+func (cmd *Cmd) IsStreaming() bool {
+	cmd.mutex.Lock()
+	defer cmd.mutex.Unlock()
+	return cmd.streaming || streams(cmd.Args)
+}
+
+// GetStream returns the output that a streaming command is writing, or nil if it did not start streaming yet.
+// Each attempt of the command writes to a new stream.
+func (cmd *Cmd) GetStream() *LineBuffer {
+	cmd.mutex.Lock()
+	defer cmd.mutex.Unlock()
+	return cmd.stream
+}
 
-	cmd.CmdOutput.Output = &output
-	now := time.Now()
-	cmd.CmdOutput.RunTime = &now
+// SetPolicy sets how the command runs from now on
+func (cmd *Cmd) SetPolicy(policy Policy) {
+	cmd.mutex.Lock()
+	defer cmd.mutex.Unlock()
+	cmd.policy = policy
+}
+
+// Attempt returns which attempt of the command is running, and how many attempts it may take.
+// The attempt is 0 if the command is not running.
+func (cmd *Cmd) Attempt() (int, int) {
+	cmd.mutex.Lock()
+	defer cmd.mutex.Unlock()
+	return cmd.attempt, cmd.policy.Retry.attempts()
+}
+
+// waitRunning waits for the command to finish, if it is running
+func (cmd *Cmd) waitRunning() {
+	cmd.mutex.Lock()
+	finished := cmd.finished
+	cmd.mutex.Unlock()
+	if finished != nil {
+		<-finished
+	}
+}
+
+// GetOutput returns the output of the last execution of the command
+func (cmd *Cmd) GetOutput() CmdOutput {
+	cmd.mutex.Lock()
+	defer cmd.mutex.Unlock()
+	return cmd.CmdOutput
+}
+
+// IsRunning returns whether the command is running
+func (cmd *Cmd) IsRunning() bool {
+	cmd.mutex.Lock()
+	defer cmd.mutex.Unlock()
+	return cmd.finished != nil
+}
+
+// Cancel kills the command if it is running. It returns whether it was running.
+func (cmd *Cmd) Cancel() bool {
+	cmd.mutex.Lock()
+	defer cmd.mutex.Unlock()
+	if cmd.cancel == nil {
+		return false
+	}
+	cmd.cancel()
+	return true
+}
+
+// IsFresh returns whether the command ran recently enough for its cached output to be used
+func (cmd *Cmd) IsFresh(maxAge time.Duration) bool {
+	output := cmd.GetOutput()
+	return output.Output != nil && output.RunTime != nil && time.Since(*output.RunTime) < maxAge
+}
 
-	return &cmd.CmdOutput
+// Failed returns whether the command failed (e.g. it exited with an error, timed out or was cancelled)
+func (output *CmdOutput) Failed() bool {
+	return output.RunTime != nil && output.ExitCode != 0
 }
@@ -1,7 +1,9 @@
 package commands
 
 import (
+	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -14,7 +16,7 @@ func TestCommand_New(t *testing.T) {
 	command := NewCmd("kubectl", "-n", "kubeflow", "get", "run")
 
 	// Assert
-	assert.Equal(t, "/usr/local/bin/kubectl", command.Path)
+	assert.Equal(t, "kubectl", command.Path)
 	assert.EqualValues(t, expectedArgs, command.Args)
 }
 
@@ -30,6 +32,18 @@ func TestCommand_ToString(t *testing.T) {
 	assert.Equal(t, expected, result)
 }
 
+func TestCommand_ToStringQuoted(t *testing.T) {
+	//Arrange
+	command := NewCmd("kubectl", "get", "pod", "-l", "app in (a,b)")
+	expected := "kubectl get pod -l 'app in (a,b)'"
+
+	// Act
+	result := command.ToString()
+
+	// Assert
+	assert.Equal(t, expected, result)
+}
+
 func TestCommand_Run_NoCacheFirst(t *testing.T) {
 	//Arrange
 	command := NewCmd("printf", "%s", "This is a test")
@@ -62,34 +76,172 @@ func TestCommand_Run_CacheFirst(t *testing.T) {
 	assert.Equal(t, time1, *result2.RunTime)
 }
 
+func TestCommand_RunResult(t *testing.T) {
+	//Arrange
+	command := NewCmd("printf", "%s", "This is a test")
+
+	// Act
+	result := command.Run(false)
+
+	// Assert
+	assert.Equal(t, "This is a test", *result.Output)
+	assert.Equal(t, "", result.Stderr)
+	assert.Equal(t, 0, result.ExitCode)
+	assert.False(t, result.Failed())
+	assert.False(t, result.RunTime.Before(*result.StartTime))
+	assert.Equal(t, result.RunTime.Sub(*result.StartTime), result.Duration)
+	assert.EqualValues(t, os.Environ(), result.Env)
+}
+
+func TestCommand_RunStreaming(t *testing.T) {
+	//Arrange
+	command := NewCmd("sh", "-c", "echo one; sleep 0.5; echo two")
+	command.SetStreaming(true)
+	done := make(chan *CmdOutput, 1)
+
+	// Act
+	command.RunAsync(func(output *CmdOutput) { done <- output })
+	var lines []string
+	for start := time.Now(); len(lines) == 0 && time.Since(start) < time.Second; time.Sleep(10 * time.Millisecond) {
+		if stream := command.GetStream(); stream != nil {
+			lines, _ = stream.Lines(0)
+		}
+	}
+	output := <-done
+
+	// Assert
+	assert.True(t, command.IsStreaming())
+	assert.EqualValues(t, []string{"one"}, lines)
+	assert.Equal(t, "one\ntwo\n", *output.Output)
+}
+
 func TestCommand_RunInvalid_NoCacheFirst(t *testing.T) {
 	//Arrange
 	command := NewCmd("printf", "%d", "This is a test")
-	expectedOutput := "printf: 'This is a test': expected a numeric value\n0"
+	expectedOutput := "0"
+	expectedStderr := "printf: 'This is a test': expected a numeric value\n"
 	result1 := command.Run(false)
 	time1 := *result1.RunTime
 	assert.Equal(t, expectedOutput, *result1.Output)
+	assert.Equal(t, expectedStderr, result1.Stderr)
+	assert.Equal(t, 1, result1.ExitCode)
+	assert.True(t, result1.Failed())
 
 	// Act
 	result2 := command.Run(false)
 
 	// Assert
 	assert.Equal(t, expectedOutput, *result2.Output)
+	assert.Equal(t, expectedStderr, result2.Stderr)
 	assert.True(t, result2.RunTime.After(time1))
 }
 
 func TestCommand_RunInvalid_CacheFirst(t *testing.T) {
 	//Arrange
 	command := NewCmd("printf", "%d", "This is a test")
-	expectedOutput := "printf: 'This is a test': expected a numeric value\n0"
+	expectedOutput := "0"
+	expectedStderr := "printf: 'This is a test': expected a numeric value\n"
 	result1 := command.Run(true)
 	time1 := *result1.RunTime
 	assert.Equal(t, expectedOutput, *result1.Output)
+	assert.Equal(t, expectedStderr, result1.Stderr)
+	assert.Equal(t, 1, result1.ExitCode)
+	assert.True(t, result1.Failed())
 
 	// Act
 	result2 := command.Run(true)
 
 	// Assert
 	assert.Equal(t, expectedOutput, *result2.Output)
+	assert.Equal(t, expectedStderr, result2.Stderr)
 	assert.Equal(t, time1, *result2.RunTime)
 }
+
+func TestCommand_Cancel(t *testing.T) {
+	//Arrange
+	command := NewCmd("sleep", "10")
+	done := make(chan *CmdOutput, 1)
+	started := command.RunAsync(func(output *CmdOutput) { done <- output })
+
+	// Act
+	cancelled := command.Cancel()
+	output := <-done
+
+	// Assert
+	assert.True(t, started)
+	assert.True(t, cancelled)
+	assert.Equal(t, "", *output.Output)
+	assert.Equal(t, "Cancelled\n", output.Stderr)
+	assert.Equal(t, -1, output.ExitCode)
+	assert.True(t, output.Cancelled)
+	assert.False(t, command.IsRunning())
+	assert.False(t, command.Cancel())
+}
+
+func TestCommand_RunAsyncAlreadyRunning(t *testing.T) {
+	//Arrange
+	command := NewCmd("sleep", "0.2")
+	done := make(chan *CmdOutput, 1)
+	first := command.RunAsync(func(output *CmdOutput) { done <- output })
+
+	// Act
+	second := command.RunAsync(func(output *CmdOutput) {})
+	running := command.IsRunning()
+	waited := command.Run(false)
+	output := <-done
+
+	// Assert
+	assert.True(t, first)
+	assert.False(t, second)
+	assert.True(t, running)
+	assert.Equal(t, output.RunTime, waited.RunTime)
+}
+
+func TestCommand_RunRetry(t *testing.T) {
+	//Arrange
+	retry, err := NewRetryPolicy(3, time.Millisecond, []string{"expected a numeric value"}, nil)
+	assert.Nil(t, err)
+	failing := NewCmd("printf", "%d", "This is a test")
+	failing.SetPolicy(Policy{Retry: retry})
+	succeeding := NewCmd("printf", "%s", "This is a test")
+	succeeding.SetPolicy(Policy{Retry: retry})
+
+	// Act
+	failed := failing.Run(false)
+	succeeded := succeeding.Run(false)
+
+	// Assert
+	assert.Len(t, failed.Attempts, 3)
+	assert.Equal(t, 3, failed.MaxAttempts)
+	assert.Equal(t, 1, failed.Attempts[2].ExitCode)
+	assert.Equal(t, failed.Attempts[2].Stderr, failed.Stderr)
+	assert.Len(t, succeeded.Attempts, 1)
+	assert.Equal(t, 0, succeeded.Attempts[0].ExitCode)
+}
+
+func TestCommand_RunTimeout(t *testing.T) {
+	//Arrange
+	command := NewCmd("sleep", "10")
+	command.SetPolicy(Policy{Timeout: 50 * time.Millisecond})
+
+	// Act
+	output := command.Run(false)
+
+	// Assert
+	assert.Len(t, output.Attempts, 1)
+	assert.True(t, output.Attempts[0].TimedOut)
+	assert.Equal(t, "Timed out after 50ms\n", output.Stderr)
+	assert.False(t, output.Cancelled)
+}
+
+func TestCommand_RunNotFound(t *testing.T) {
+	//Arrange
+	command := NewCmd("superk-test-not-found")
+
+	// Act
+	output := command.Run(false)
+
+	// Assert
+	assert.Equal(t, -1, output.Attempts[0].ExitCode)
+	assert.Contains(t, output.Stderr, "executable file not found")
+}
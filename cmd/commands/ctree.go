@@ -4,65 +4,74 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 )
 
-// CTree structure represents a tree of kubectl commands
+// CTree structure represents a tree of commands that share the same root (e.g. kubectl)
 type CTree struct {
 	Part     string
+	Meta     CMeta
 	Cmd      *Cmd
 	Parent   *CTree
 	Children []*CTree
+	// stale are the commands of the node that were still running when the values of its placeholders changed,
+	// kept until they finish so their executions are recorded
+	stale []*Cmd
+}
+
+// CMeta represents what we know about a command in the tree besides its part
+type CMeta struct {
+	Notes        string
+	Pinned       bool
+	LastRun      *time.Time
+	RunCount     int
+	LastExitCode *int
+	// Values are the values of the placeholders of the command the last time it ran
+	Values map[string]string
+	// Timeout is how long each attempt of the command may take, if it is not the default one
+	Timeout time.Duration
+	// Stream makes the command stream its output, even if its flags do not say it follows its output
+	Stream bool
+	// Context is the kube context the commands of a tree run against, in the metadata of its root.
+	// Empty means the current context of the kubeconfig.
+	Context string
 }
 
 // NewCTree creates a kubectl command tree
 func NewCTree(commands []string) (*CTree, error) {
-	root := CTree{
-		Part: "kubectl",
+	return NewRootCTree("kubectl", commands)
+}
+
+// NewRootCTree creates a command tree for commands of a given binary (e.g. helm)
+func NewRootCTree(root string, commands []string) (*CTree, error) {
+	tree := CTree{
+		Part: root,
 	}
 
 	for _, command := range commands {
-		if err := root.MergeCommand(command); err != nil {
+		if err := tree.MergeCommand(command); err != nil {
 			return nil, err
 		}
 	}
-	return &root, nil
+	return &tree, nil
 }
 
-// MergeCommand merges a kubectl command into the tree
+// MergeCommand merges a command into the tree in its canonical form
 func (tree *CTree) MergeCommand(command string) error {
-	parts := split(command)
+	parts, err := split(command)
+	if err != nil {
+		return err
+	}
+	parts = canonicalize(parts)
 
-	if len(parts) == 0 || parts[0] != "kubectl" {
-		return errors.New("This is not a kubectl command")
+	if len(parts) == 0 || parts[0] != tree.Part {
+		return fmt.Errorf("This is not a %s command", tree.Part)
 	}
 
 	tree.addChildren(parts[1:])
 	return nil
 }
 
-func split(command string) []string {
-	parts := strings.Fields(command)
-	var joinedParts []string
-
-	// Potential ways to specify flags
-	// --flag
-	// -f
-	// --flag value
-	// --flag=value
-	// -f value
-	// -f=value
-	for i, max := 0, len(parts); i < max; i++ {
-		if i+1 < max && strings.HasPrefix(parts[i], "-") && !strings.HasPrefix(parts[i+1], "-") {
-			joinedParts = append(joinedParts, strings.Join([]string{parts[i], parts[i+1]}, " "))
-			i++
-		} else {
-			joinedParts = append(joinedParts, parts[i])
-		}
-	}
-
-	return joinedParts
-}
-
 func (tree *CTree) addChildren(parts []string) {
 	if len(parts) == 0 {
 		return
@@ -107,11 +116,82 @@ func (tree *CTree) GetCmd(position int) *Cmd {
 		return nil
 	}
 
-	if current.Cmd == nil {
-		current.Cmd = current.toCmd()
+	return current.getCmd()
+}
+
+func (tree *CTree) getCmd() *Cmd {
+	if tree.Cmd == nil {
+		tree.Cmd = tree.toCmd()
+	}
+	return tree.Cmd
+}
+
+// run executes the command of this node and records the execution in its metadata.
+// It returns whether the command actually ran, or its cached output was used instead.
+func (tree *CTree) run(cacheFirst bool) (*Cmd, bool) {
+	cmd := tree.getCmd()
+	previousRunTime := cmd.GetOutput().RunTime
+	output := cmd.Run(cacheFirst)
+	if output.RunTime == previousRunTime {
+		return cmd, false
+	}
+
+	tree.record(output)
+	return cmd, true
+}
+
+// record records an execution of the command of this node in its metadata
+func (tree *CTree) record(output *CmdOutput) {
+	exitCode := output.ExitCode
+	tree.Meta.LastRun, tree.Meta.LastExitCode = output.RunTime, &exitCode
+	tree.Meta.RunCount++
+}
+
+// merge combines the metadata of a duplicate command into this metadata
+func (meta *CMeta) merge(other CMeta) {
+	switch {
+	case meta.Notes == "":
+		meta.Notes = other.Notes
+	case other.Notes != "" && other.Notes != meta.Notes:
+		meta.Notes = fmt.Sprintf("%s\n%s", meta.Notes, other.Notes)
+	}
+	meta.Pinned = meta.Pinned || other.Pinned
+	meta.Stream = meta.Stream || other.Stream
+	if meta.Timeout == 0 {
+		meta.Timeout = other.Timeout
+	}
+	if meta.Context == "" {
+		meta.Context = other.Context
+	}
+	meta.RunCount += other.RunCount
+	if other.LastRun != nil && (meta.LastRun == nil || other.LastRun.After(*meta.LastRun)) {
+		meta.LastRun, meta.LastExitCode = other.LastRun, other.LastExitCode
+	}
+	for name, value := range other.Values {
+		if _, ok := meta.Values[name]; !ok {
+			if meta.Values == nil {
+				meta.Values = map[string]string{}
+			}
+			meta.Values[name] = value
+		}
+	}
+}
+
+// walk calls a function for every node in the tree (depth-first search)
+func (tree *CTree) walk(visit func(node *CTree)) {
+	visit(tree)
+	for _, child := range tree.Children {
+		child.walk(visit)
 	}
+}
 
-	return current.Cmd
+// size returns the number of commands in the tree, including the root
+func (tree *CTree) size() int {
+	size := 1
+	for _, child := range tree.Children {
+		size += child.size()
+	}
+	return size
 }
 
 func (tree *CTree) getTree(position *int) *CTree {
@@ -136,16 +216,70 @@ func (tree *CTree) getTree(position *int) *CTree {
 	return nil
 }
 
+// toCmd builds the executable command of this node, replacing its placeholders with their last values
 func (tree *CTree) toCmd() *Cmd {
 	var args []string
 	var current *CTree
 	for current = tree; current.Parent != nil; current = current.Parent {
-		parts := strings.Split(current.Part, " ")
-		args = append(parts, args...)
+		args = append(partArgs(current.Part), args...)
+	}
+	for index, arg := range args {
+		args[index] = Expand(arg, tree.Meta.Values)
 	}
 	return NewCmd(current.Part, args...)
 }
 
+// missingValues returns the placeholders of the command of this node that have no value yet
+func (tree *CTree) missingValues() []string {
+	var missing []string
+	for _, name := range Placeholders(tree.toCommand()) {
+		if _, ok := tree.Meta.Values[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// setValues sets the values of the placeholders of the command of this node
+func (tree *CTree) setValues(values map[string]string) {
+	tree.Meta.Values = map[string]string{}
+	for name, value := range values {
+		tree.Meta.Values[name] = value
+	}
+	// The cached command was built with the previous values
+	tree.dropCmd()
+}
+
+// dropCmd drops the cached command of the node, which is built again the next time it is needed.
+// A command that is running is kept aside until it finishes.
+func (tree *CTree) dropCmd() {
+	if tree.Cmd != nil && tree.Cmd.IsRunning() {
+		tree.stale = append(tree.stale, tree.Cmd)
+	}
+	tree.Cmd = nil
+}
+
+// GetCommand returns the kubectl command at a certain position in the tree exactly as it was typed
+// (depth-first search)
+func (tree *CTree) GetCommand(position int) *string {
+	current := tree.getTree(&position)
+
+	if current == nil {
+		return nil
+	}
+
+	command := current.toCommand()
+	return &command
+}
+
+func (tree *CTree) toCommand() string {
+	var parts []string
+	for current := tree; current != nil; current = current.Parent {
+		parts = append([]string{current.Part}, parts...)
+	}
+	return strings.Join(parts, " ")
+}
+
 // GetPosition returns the position of a kubectl command in the tree (depth-first search)
 //      1
 //    /   \
@@ -155,7 +289,11 @@ func (tree *CTree) toCmd() *Cmd {
 //       |
 //       7
 func (tree *CTree) GetPosition(command string) *int {
-	parts := split(command)
+	parts, err := split(command)
+	if err != nil {
+		return nil
+	}
+	parts = canonicalize(parts)
 	position := 1
 	return tree.getPosition(parts, &position)
 }
@@ -181,7 +319,10 @@ func (tree *CTree) getPosition(parts []string, position *int) *int {
 
 // GetNextParts returns a list of potential next parts for a given command
 func (tree *CTree) GetNextParts(command string) []string {
-	parts := split(command)
+	parts, err := split(command)
+	if err != nil {
+		return nil
+	}
 	return tree.getNextParts(parts)
 }
 
@@ -275,7 +416,6 @@ func (tree *CTree) serialize(all *[]string) {
 			child.serialize(all)
 		}
 	} else {
-		command := tree.toCmd()
-		*all = append(*all, command.ToString())
+		*all = append(*all, tree.toCommand())
 	}
 }
@@ -366,3 +366,145 @@ func TestCTree_Serialize(t *testing.T) {
 	assert.Len(t, result, len(expected))
 	assert.EqualValues(t, expected, result)
 }
+
+func TestCTree_MergeCommandQuoted(t *testing.T) {
+	// Arrange
+	tree, err := NewCTree(nil)
+	assert.Nil(t, err)
+
+	// Act
+	err = tree.MergeCommand("kubectl get pod --field-selector \"status.phase!=Running\" -l 'app in (a,b)'")
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "get", tree.Children[0].Part)
+	assert.Equal(t, "pod", tree.Children[0].Children[0].Part)
+	assert.Equal(t, "--field-selector \"status.phase!=Running\"", tree.Children[0].Children[0].Children[0].Part)
+	assert.Equal(t, "-l 'app in (a,b)'", tree.Children[0].Children[0].Children[0].Children[0].Part)
+}
+
+func TestCTree_MergeCommandBooleanFlag(t *testing.T) {
+	// Arrange
+	tree, err := NewCTree(nil)
+	assert.Nil(t, err)
+
+	// Act
+	err = tree.MergeCommand("kubectl get -A pod")
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "get", tree.Children[0].Part)
+	assert.Equal(t, "pod", tree.Children[0].Children[0].Part)
+	assert.Equal(t, "-A", tree.Children[0].Children[0].Children[0].Part)
+}
+
+func TestCTree_MergeCommandUnterminatedQuote(t *testing.T) {
+	// Arrange
+	tree, err := NewCTree(nil)
+	assert.Nil(t, err)
+
+	// Act
+	err = tree.MergeCommand("kubectl get pod -l 'app=a")
+
+	// Assert
+	assert.NotNil(t, err)
+	assert.Len(t, tree.Children, 0)
+}
+
+func TestCTree_GetCmdQuoted(t *testing.T) {
+	// Arrange
+	tree, err := NewCTree([]string{
+		"kubectl get pod -o jsonpath='{.items[*].metadata.name}'",
+	})
+	assert.Nil(t, err)
+
+	expected := NewCmd("kubectl", "get", "pod", "-o", "jsonpath={.items[*].metadata.name}")
+
+	// Act
+	result := tree.GetCmd(4)
+
+	// Assert
+	assert.Equal(t, expected, result)
+}
+
+func TestCTree_GetCommand(t *testing.T) {
+	// Arrange
+	tree, err := NewCTree([]string{
+		"kubectl -n kubeflow get pod -l 'app in (a,b)'",
+		"kubectl -n kubeflow get cronjob",
+	})
+	assert.Nil(t, err)
+
+	// Act
+	result := tree.GetCommand(5)
+
+	// Assert
+	assert.Equal(t, "kubectl -n kubeflow get pod -l 'app in (a,b)'", *result)
+}
+
+func TestCTree_GetPositionQuoted(t *testing.T) {
+	// Arrange
+	tree, err := NewCTree([]string{
+		"kubectl -n kubeflow get pod",
+		"kubectl -n kubeflow get pod -l 'app in (a,b)'",
+	})
+	assert.Nil(t, err)
+
+	// Act
+	result := tree.GetPosition("kubectl -n kubeflow get pod -l 'app in (a,b)'")
+
+	// Assert
+	assert.Equal(t, 5, *result)
+}
+
+func TestCTree_SerializeQuoted(t *testing.T) {
+	// Arrange
+	expected := []string{
+		"kubectl get pod -l 'app in (a,b)'",
+		"kubectl get pod -o jsonpath='{.items[*].metadata.name}'",
+		"kubectl get pod --field-selector \"status.phase!=Running\"",
+		"kubectl exec my-pod -- sh -c 'echo $HOME'",
+	}
+	tree, err := NewCTree(expected)
+	assert.Nil(t, err)
+
+	// Act
+	result := tree.Serialize()
+
+	// Assert
+	assert.EqualValues(t, expected, result)
+}
+
+func TestCTree_MergeCommandCanonical(t *testing.T) {
+	// Arrange
+	tree, err := NewCTree(nil)
+	assert.Nil(t, err)
+
+	// Act
+	err = tree.MergeCommand("kubectl -n kubeflow get pod")
+	assert.Nil(t, err)
+
+	err = tree.MergeCommand("kubectl get po -n kubeflow")
+	assert.Nil(t, err)
+
+	err = tree.MergeCommand("kubectl get pods --namespace=kubeflow")
+	assert.Nil(t, err)
+
+	// Assert
+	assert.EqualValues(t, []string{"kubectl -n kubeflow get pod"}, tree.Serialize())
+}
+
+func TestCTree_GetPositionCanonical(t *testing.T) {
+	// Arrange
+	tree, err := NewCTree([]string{
+		"kubectl -n kubeflow get pod",
+		"kubectl -n kubeflow get cronjob",
+	})
+	assert.Nil(t, err)
+
+	// Act
+	result := tree.GetPosition("kubectl get cj --namespace kubeflow")
+
+	// Assert
+	assert.Equal(t, 5, *result)
+}
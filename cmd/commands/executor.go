@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+)
+
+// Executor runs command lines (e.g. with the binaries installed in the machine, or against a fake cluster)
+type Executor interface {
+	// Execute runs a command line until it finishes or the context is done, writing its output to stdout and stderr.
+	// It returns the exit code of the command, and an error if the command could not run (e.g. it is not installed).
+	Execute(ctx context.Context, args []string, env []string, stdout, stderr io.Writer) (int, error)
+}
+
+// ExecExecutor runs command lines with the binaries installed in the machine
+type ExecExecutor struct{}
+
+// Check interface
+var _ Executor = ExecExecutor{}
+
+// Execute runs a command line with the binary installed in the machine
+func (ExecExecutor) Execute(ctx context.Context, args []string, env []string, stdout, stderr io.Writer) (int, error) {
+	// We cannot run the same exec.Cmd twice
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdout, cmd.Stderr, cmd.Env = stdout, stderr, env
+
+	switch err := cmd.Run().(type) {
+	case nil:
+		return 0, nil
+	case *exec.ExitError:
+		return err.ExitCode(), nil
+	default:
+		// The command could not start (e.g. its binary is not installed), or it was cancelled before it started
+		return -1, err
+	}
+}
+
+// ReadOnlyExecutor is an executor that refuses to run commands that change the cluster against read-only kube contexts
+type ReadOnlyExecutor struct {
+	// Executor runs the commands that are not refused. nil runs them with the binaries installed in the machine.
+	Executor Executor
+	// All makes every kube context read-only
+	All bool
+	// Contexts are the kube contexts that are read-only
+	Contexts []string
+}
+
+// Check interface
+var _ Executor = &ReadOnlyExecutor{}
+
+// Execute runs a command line, unless it changes the cluster and its kube context is read-only
+func (executor *ReadOnlyExecutor) Execute(ctx context.Context, args []string, env []string, stdout, stderr io.Writer) (int, error) {
+	if Mutates(args) {
+		if executor.All {
+			return -1, errors.New("Read-only mode: commands that change the cluster do not run")
+		}
+		if context := KubeContext(args); executor.readOnly(context) {
+			return -1, fmt.Errorf("Context %s is read-only: commands that change the cluster do not run", context)
+		}
+	}
+
+	inner := executor.Executor
+	if inner == nil {
+		inner = ExecExecutor{}
+	}
+	return inner.Execute(ctx, args, env, stdout, stderr)
+}
+
+// ReadOnly returns whether commands that change the cluster are refused in a kube context
+func (executor *ReadOnlyExecutor) ReadOnly(context string) bool {
+	return executor.All || executor.readOnly(context)
+}
+
+func (executor *ReadOnlyExecutor) readOnly(context string) bool {
+	for _, readOnly := range executor.Contexts {
+		if context != "" && context == readOnly {
+			return true
+		}
+	}
+	return false
+}
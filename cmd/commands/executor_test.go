@@ -0,0 +1,107 @@
+package commands
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadOnlyExecutor_Execute(t *testing.T) {
+	// Arrange
+	dir, err := writeTmpFakeCluster(map[string]string{
+		FakeFixturesFile: `{"fixtures": [
+			{"command": "kubectl get pod", "stdout": "pods.txt"},
+			{"command": "kubectl --context aks-dev delete pod nginx", "stdout": "deleted.txt"}
+		]}`,
+		"pods.txt":    "nginx\n",
+		"deleted.txt": "pod \"nginx\" deleted\n",
+	})
+	assert.Nil(t, err)
+	fake, err := NewFakeExecutor(dir)
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name     string
+		all      bool
+		args     []string
+		stdout   string
+		exitCode int
+		refused  bool
+	}{
+		{"Read", false, []string{"kubectl", "get", "pod"}, "nginx\n", 0, false},
+		{"ReadAllReadOnly", true, []string{"kubectl", "get", "pod"}, "nginx\n", 0, false},
+		{"Mutate", false, []string{"kubectl", "--context", "aks-dev", "delete", "pod", "nginx"}, "pod \"nginx\" deleted\n", 0, false},
+		{"MutateReadOnlyContext", false, []string{"kubectl", "--context", "aks-prod", "delete", "pod", "nginx"}, "", -1, true},
+		{"MutateAllReadOnly", true, []string{"kubectl", "--context", "aks-dev", "delete", "pod", "nginx"}, "", -1, true},
+		{"ScaleUpReadOnlyContext", false, []string{"kubectl", "--context", "aks-prod", "scale", "deploy", "web", "--replicas=3"}, "", -1, true},
+		{"RunReadOnlyContext", false, []string{"kubectl", "--context", "aks-prod", "run", "debug", "--image=busybox"}, "", -1, true},
+		{"ExposeReadOnlyContext", false, []string{"kubectl", "--context", "aks-prod", "expose", "deploy", "web", "--port=80"}, "", -1, true},
+		{"AutoscaleReadOnlyContext", false, []string{"kubectl", "--context", "aks-prod", "autoscale", "deploy", "web", "--max=5"}, "", -1, true},
+		{"RolloutPauseAllReadOnly", true, []string{"kubectl", "rollout", "pause", "deploy/web"}, "", -1, true},
+		{"RolloutResumeAllReadOnly", true, []string{"kubectl", "rollout", "resume", "deploy/web"}, "", -1, true},
+		{"RolloutUndoAllReadOnly", true, []string{"kubectl", "rollout", "undo", "deploy/web"}, "", -1, true},
+		{"RolloutRestartAllReadOnly", true, []string{"kubectl", "rollout", "restart", "deploy/web"}, "", -1, true},
+		{"CopyAllReadOnly", true, []string{"kubectl", "cp", "cache", "web:/tmp/cache"}, "", -1, true},
+		{"CertificateApproveAllReadOnly", true, []string{"kubectl", "certificate", "approve", "csr-1"}, "", -1, true},
+		{"CertificateDenyAllReadOnly", true, []string{"kubectl", "certificate", "deny", "csr-1"}, "", -1, true},
+		{"ExecAllReadOnly", true, []string{"kubectl", "exec", "web", "--", "rm", "-f", "/tmp/cache"}, "", -1, true},
+		{"UninstallAfterBooleanFlagAllReadOnly", true, []string{"helm", "--debug", "uninstall", "nginx"}, "", -1, true},
+		{"DeleteAfterWarningsAsErrorsAllReadOnly", true, []string{"kubectl", "--warnings-as-errors", "delete", "pod", "x"}, "", -1, true},
+		{"DeleteAfterMatchServerVersionAllReadOnly", true, []string{"kubectl", "--match-server-version", "delete", "pod", "x"}, "", -1, true},
+		{"DeleteAfterAttachedNamespaceAllReadOnly", true, []string{"kubectl", "-nkubeflow", "delete", "pod", "x"}, "", -1, true},
+		{"DeleteNestedAllReadOnly", true, []string{"az", "aks", "delete", "-n", "cluster1"}, "", -1, true},
+		{"NoVerbAllReadOnly", true, []string{"kubectl", "--context", "aks-dev"}, "", -1, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			executor := ReadOnlyExecutor{Executor: fake, All: test.all, Contexts: []string{"aks-prod"}}
+			var stdout, stderr strings.Builder
+
+			// Act
+			exitCode, err := executor.Execute(context.Background(), test.args, nil, &stdout, &stderr)
+
+			// Assert
+			assert.Equal(t, test.refused, err != nil)
+			assert.Equal(t, test.exitCode, exitCode)
+			assert.Equal(t, test.stdout, stdout.String())
+		})
+	}
+	assert.Len(t, fake.Calls(), 3)
+
+	// Cleanup
+	os.RemoveAll(dir)
+}
+
+func TestReadOnlyExecutor_ReadOnly(t *testing.T) {
+	// Arrange
+	executor := ReadOnlyExecutor{Contexts: []string{"aks-prod"}}
+
+	// Act
+	readOnly := executor.ReadOnly("aks-prod")
+	other := executor.ReadOnly("aks-dev")
+	unknown := executor.ReadOnly("")
+	all := (&ReadOnlyExecutor{All: true}).ReadOnly("aks-dev")
+
+	// Assert
+	assert.True(t, readOnly)
+	assert.False(t, other)
+	assert.False(t, unknown)
+	assert.True(t, all)
+}
+
+func TestCmd_RunReadOnly(t *testing.T) {
+	// Arrange
+	cmd := NewCmd("kubectl", "delete", "pod", "nginx")
+	cmd.SetExecutor(&ReadOnlyExecutor{All: true})
+
+	// Act
+	output := cmd.Run(false)
+
+	// Assert
+	assert.Equal(t, -1, output.ExitCode)
+	assert.Contains(t, output.Stderr, "Read-only mode")
+}
@@ -0,0 +1,236 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Exporter structure represents a format the commands in a forest can be shared in
+type Exporter struct {
+	Name       string
+	Extension  string
+	Executable bool
+	Export     func(forest *CForest) string
+}
+
+// Exporters are the available export formats
+var Exporters = []*Exporter{
+	{Name: "aliases", Extension: "sh", Export: ExportAliases},
+	{Name: "script", Extension: "sh", Executable: true, Export: ExportScript},
+	{Name: "markdown", Extension: "md", Export: ExportMarkdown},
+	{Name: "dot", Extension: "dot", Export: ExportDot},
+}
+
+var nonAliasChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// GetExporter returns the exporter of a format, or nil if there is no such format
+func GetExporter(name string) *Exporter {
+	for _, exporter := range Exporters {
+		if exporter.Name == name {
+			return exporter
+		}
+	}
+	return nil
+}
+
+// ExporterNames returns the names of the available export formats
+func ExporterNames() []string {
+	var names []string
+	for _, exporter := range Exporters {
+		names = append(names, exporter.Name)
+	}
+	return names
+}
+
+// ExportFile exports the forest to a file. Executable formats get execute permissions.
+func (exporter *Exporter) ExportFile(forest *CForest, file string) error {
+	perm := os.FileMode(0644)
+	if exporter.Executable {
+		perm = 0755
+	}
+	return writeFileAtomic(file, []byte(exporter.Export(forest)), perm)
+}
+
+// ExportAliases returns a bash/zsh file with an alias for every command, named after its parts.
+// Commands with placeholders get a function instead, with an argument per placeholder.
+// Example output:
+//   alias k_kubeflow_get_pod='kubectl -n kubeflow get pod'
+//   k_logs_pod() { kubectl logs "${1:?pod}"; }
+func ExportAliases(forest *CForest) string {
+	var builder strings.Builder
+	builder.WriteString("# Aliases exported from superk. Source this file from your .bashrc or .zshrc\n")
+
+	names := map[string]bool{}
+	for _, node := range forest.leaves() {
+		name := aliasName(node)
+		for index := 2; names[name]; index++ {
+			name = fmt.Sprintf("%s_%d", aliasName(node), index)
+		}
+		names[name] = true
+
+		// The shell cannot list the values of placeholders, so their sources are left out
+		command := stripSources(node.toCommand())
+		placeholders := Placeholders(command)
+		if len(placeholders) == 0 {
+			fmt.Fprintf(&builder, "alias %s=%s\n", name, quoteAlways(command))
+			continue
+		}
+		function := shellTemplate(command, func(placeholder string) string {
+			for index, current := range placeholders {
+				if current == placeholder {
+					return fmt.Sprintf("%d:?%s", index+1, placeholder)
+				}
+			}
+			return placeholder
+		})
+		fmt.Fprintf(&builder, "%s() { %s; }\n", name, function)
+	}
+	return builder.String()
+}
+
+// aliasName generates the name of the alias of a command from the first letter of its root and its other parts.
+// Flags that have a value are named after their value (e.g. "-n kubeflow" becomes "kubeflow").
+func aliasName(node *CTree) string {
+	var words []string
+	var current *CTree
+	for current = node; current.Parent != nil; current = current.Parent {
+		args := partArgs(stripSources(current.Part))
+		if len(args) == 2 && isFlag(args[0]) {
+			args = args[1:]
+		}
+		words = append(args, words...)
+	}
+	words = append([]string{current.Part[:1]}, words...)
+
+	var name []string
+	for _, word := range words {
+		if word = strings.Trim(nonAliasChars.ReplaceAllString(strings.ToLower(word), "_"), "_"); word != "" {
+			name = append(name, word)
+		}
+	}
+	return strings.Join(name, "_")
+}
+
+// ExportScript returns an executable runbook that runs every command, one after the other.
+// Placeholders become environment variables that must be set to run the runbook.
+// Example output:
+//   echo '+ kubectl -n kubeflow get pod'
+//   kubectl -n kubeflow get pod
+func ExportScript(forest *CForest) string {
+	var builder strings.Builder
+	builder.WriteString("#!/usr/bin/env bash\n")
+	builder.WriteString("# Runbook exported from superk\n")
+
+	var placeholders []string
+	for _, node := range forest.leaves() {
+		for _, name := range Placeholders(stripSources(node.toCommand())) {
+			if !isOneOf(name, placeholders) {
+				placeholders = append(placeholders, name)
+			}
+		}
+	}
+	if len(placeholders) > 0 {
+		fmt.Fprintf(&builder, "# Set these environment variables to run it: %s\n", strings.Join(placeholders, ", "))
+	}
+	builder.WriteString("set -u\n")
+
+	for _, node := range forest.leaves() {
+		command := stripSources(node.toCommand())
+		builder.WriteString("\n")
+		for _, line := range strings.Split(node.Meta.Notes, "\n") {
+			if line != "" {
+				fmt.Fprintf(&builder, "# %s\n", line)
+			}
+		}
+		fmt.Fprintf(&builder, "echo %s\n", quoteAlways("+ "+command))
+		fmt.Fprintf(&builder, "%s\n", shellTemplate(command, func(name string) string { return name }))
+	}
+	return builder.String()
+}
+
+// ExportMarkdown returns a cheat sheet with a section per tree and a nested list that follows the tree structure.
+// Commands are shown in full next to the last part of each command.
+// Example output:
+//   ## kubectl
+//   - `-n kubeflow`
+//     - `get`
+//       - `pod`: `kubectl -n kubeflow get pod`
+func ExportMarkdown(forest *CForest) string {
+	var builder strings.Builder
+	builder.WriteString("# Commands\n")
+
+	const tabSize = 2
+	for _, tree := range forest.Trees {
+		// ToStrings and walk visit the nodes in the same order
+		var nodes []*CTree
+		tree.walk(func(node *CTree) { nodes = append(nodes, node) })
+
+		fmt.Fprintf(&builder, "\n## %s\n\n", tree.Part)
+		for index, line := range tree.ToStrings(tabSize)[1:] {
+			node := nodes[index+1]
+			part := strings.TrimLeft(line, " ")
+			indent := strings.Repeat(" ", len(line)-len(part)-tabSize)
+			fmt.Fprintf(&builder, "%s- `%s`", indent, part)
+			if len(node.Children) == 0 {
+				fmt.Fprintf(&builder, ": `%s`", node.toCommand())
+			}
+			if node.Meta.Notes != "" {
+				fmt.Fprintf(&builder, " (%s)", strings.ReplaceAll(node.Meta.Notes, "\n", " "))
+			}
+			builder.WriteString("\n")
+		}
+	}
+	return builder.String()
+}
+
+// ExportDot returns a Graphviz rendering of the trees
+// Example output:
+//   digraph superk {
+//     n1 [label="kubectl"];
+//     n2 [label="get"];
+//     n1 -> n2;
+//   }
+func ExportDot(forest *CForest) string {
+	var builder strings.Builder
+	builder.WriteString("digraph superk {\n")
+	builder.WriteString("  rankdir=LR;\n")
+	builder.WriteString("  node [shape=box, fontname=\"monospace\"];\n")
+
+	ids := map[*CTree]int{}
+	for _, tree := range forest.Trees {
+		tree.walk(func(node *CTree) {
+			ids[node] = len(ids) + 1
+			fmt.Fprintf(&builder, "  n%d [label=%s];\n", ids[node], quoteDot(node.Part))
+			if node.Parent != nil {
+				fmt.Fprintf(&builder, "  n%d -> n%d;\n", ids[node.Parent], ids[node])
+			}
+		})
+	}
+
+	builder.WriteString("}\n")
+	return builder.String()
+}
+
+func quoteDot(label string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(label) + `"`
+}
+
+// quoteAlways single-quotes a string for the shell, even if it is safe
+func quoteAlways(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// leaves returns the nodes of the forest without children, i.e. the commands Serialize returns
+func (forest *CForest) leaves() []*CTree {
+	var leaves []*CTree
+	for _, tree := range forest.Trees {
+		tree.walk(func(node *CTree) {
+			if len(node.Children) == 0 {
+				leaves = append(leaves, node)
+			}
+		})
+	}
+	return leaves
+}
@@ -0,0 +1,226 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExport_Aliases(t *testing.T) {
+	// Arrange
+	forest, err := NewCForest(DefaultRoots, []string{
+		"kubectl -n kubeflow get pod",
+		"kubectl -n kubeflow get cronjob -l 'app in (a,b)'",
+		"kubectl get pod -n kubeflow",
+		"helm list",
+	})
+	assert.Nil(t, err)
+	forest.Trees[0].Children[0].Children[0].Children = append(forest.Trees[0].Children[0].Children[0].Children,
+		&CTree{Part: "pod -o 'wide'", Parent: forest.Trees[0].Children[0].Children[0]})
+
+	expected := "# Aliases exported from superk. Source this file from your .bashrc or .zshrc\n" +
+		"alias k_kubeflow_get_pod='kubectl -n kubeflow get pod'\n" +
+		"alias k_kubeflow_get_cronjob_app_in_a_b='kubectl -n kubeflow get cronjob -l '\\''app in (a,b)'\\'''\n" +
+		"alias k_kubeflow_get_pod_o_wide='kubectl -n kubeflow get pod -o '\\''wide'\\'''\n" +
+		"alias h_list='helm list'\n"
+
+	// Act
+	result := ExportAliases(forest)
+
+	// Assert
+	assert.Equal(t, expected, result)
+}
+
+func TestExport_AliasesDuplicateNames(t *testing.T) {
+	// Arrange
+	forest, err := NewCForest(DefaultRoots, []string{
+		"kubectl get pod",
+		"kustomize get pod",
+	})
+	assert.Nil(t, err)
+
+	// Act
+	result := ExportAliases(forest)
+
+	// Assert
+	assert.Contains(t, result, "alias k_get_pod='kubectl get pod'\n")
+	assert.Contains(t, result, "alias k_get_pod_2='kustomize get pod'\n")
+}
+
+func TestExport_Script(t *testing.T) {
+	// Arrange
+	forest, err := NewCForest(DefaultRoots, []string{
+		"kubectl -n kubeflow get pod",
+		"helm list",
+	})
+	assert.Nil(t, err)
+	forest.Trees[0].Children[0].Children[0].Children[0].Meta.Notes = "Check pipelines\nThey should be running"
+
+	expected := "#!/usr/bin/env bash\n" +
+		"# Runbook exported from superk\n" +
+		"set -u\n" +
+		"\n" +
+		"# Check pipelines\n" +
+		"# They should be running\n" +
+		"echo '+ kubectl -n kubeflow get pod'\n" +
+		"kubectl -n kubeflow get pod\n" +
+		"\n" +
+		"echo '+ helm list'\n" +
+		"helm list\n"
+
+	// Act
+	result := ExportScript(forest)
+
+	// Assert
+	assert.Equal(t, expected, result)
+}
+
+func TestExport_Markdown(t *testing.T) {
+	// Arrange
+	forest, err := NewCForest(DefaultRoots, []string{
+		"kubectl -n kubeflow get pod",
+		"kubectl -n kubeflow get cronjob",
+		"helm list",
+	})
+	assert.Nil(t, err)
+	forest.Trees[0].Children[0].Meta.Notes = "Pipelines"
+
+	expected := "# Commands\n" +
+		"\n" +
+		"## kubectl\n" +
+		"\n" +
+		"- `-n kubeflow` (Pipelines)\n" +
+		"  - `get`\n" +
+		"    - `pod`: `kubectl -n kubeflow get pod`\n" +
+		"    - `cronjob`: `kubectl -n kubeflow get cronjob`\n" +
+		"\n" +
+		"## helm\n" +
+		"\n" +
+		"- `list`: `helm list`\n"
+
+	// Act
+	result := ExportMarkdown(forest)
+
+	// Assert
+	assert.Equal(t, expected, result)
+}
+
+func TestExport_Dot(t *testing.T) {
+	// Arrange
+	forest, err := NewCForest(DefaultRoots, []string{
+		"kubectl get pod -l \"app=web\"",
+		"helm list",
+	})
+	assert.Nil(t, err)
+
+	expected := "digraph superk {\n" +
+		"  rankdir=LR;\n" +
+		"  node [shape=box, fontname=\"monospace\"];\n" +
+		"  n1 [label=\"kubectl\"];\n" +
+		"  n2 [label=\"get\"];\n" +
+		"  n1 -> n2;\n" +
+		"  n3 [label=\"pod\"];\n" +
+		"  n2 -> n3;\n" +
+		"  n4 [label=\"-l \\\"app=web\\\"\"];\n" +
+		"  n3 -> n4;\n" +
+		"  n5 [label=\"helm\"];\n" +
+		"  n6 [label=\"list\"];\n" +
+		"  n5 -> n6;\n" +
+		"}\n"
+
+	// Act
+	result := ExportDot(forest)
+
+	// Assert
+	assert.Equal(t, expected, result)
+}
+
+func TestExport_GetExporter(t *testing.T) {
+	// Arrange
+
+	// Act
+	found := GetExporter("markdown")
+	notFound := GetExporter("pdf")
+
+	// Assert
+	assert.Equal(t, "md", found.Extension)
+	assert.Nil(t, notFound)
+	assert.EqualValues(t, []string{"aliases", "script", "markdown", "dot"}, ExporterNames())
+}
+
+func TestExport_ExportFile(t *testing.T) {
+	// Arrange
+	path, err := getTmpPath("superk_test_export_")
+	assert.Nil(t, err)
+	forest, err := NewCForest(DefaultRoots, []string{"helm list"})
+	assert.Nil(t, err)
+
+	// Act
+	err = GetExporter("script").ExportFile(forest, path)
+
+	// Assert
+	assert.Nil(t, err)
+	info, err := os.Stat(path)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+	content, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, ExportScript(forest), string(content))
+
+	// Cleanup
+	err = os.Remove(path)
+	assert.Nil(t, err)
+}
+
+func TestExport_AliasesPlaceholders(t *testing.T) {
+	// Arrange
+	forest, err := NewCForest(DefaultRoots, []string{
+		"kubectl -n {{namespace}} logs {{pod}} -l 'app={{app}}'",
+	})
+	assert.Nil(t, err)
+
+	// Act
+	result := ExportAliases(forest)
+
+	// Assert
+	assert.Contains(t, result,
+		`k_namespace_logs_pod_app_app() { kubectl -n "${1:?namespace}" logs "${2:?pod}" -l 'app='"${3:?app}"''; }`+"\n")
+}
+
+func TestExport_ScriptPlaceholders(t *testing.T) {
+	// Arrange
+	forest, err := NewCForest(DefaultRoots, []string{
+		"kubectl -n {{namespace}} logs {{pod}}",
+	})
+	assert.Nil(t, err)
+
+	expected := "#!/usr/bin/env bash\n" +
+		"# Runbook exported from superk\n" +
+		"# Set these environment variables to run it: namespace, pod\n" +
+		"set -u\n" +
+		"\n" +
+		"echo '+ kubectl -n {{namespace}} logs {{pod}}'\n" +
+		"kubectl -n \"${namespace}\" logs \"${pod}\"\n"
+
+	// Act
+	result := ExportScript(forest)
+
+	// Assert
+	assert.Equal(t, expected, result)
+}
+
+func TestExport_AliasesSources(t *testing.T) {
+	// Arrange
+	forest, err := NewCForest(DefaultRoots, []string{
+		`kubectl logs {{pod from "kubectl get pod -o name"}}`,
+	})
+	assert.Nil(t, err)
+
+	// Act
+	result := ExportAliases(forest)
+
+	// Assert
+	assert.Contains(t, result, `k_logs_pod() { kubectl logs "${1:?pod}"; }`+"\n")
+}
@@ -0,0 +1,144 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FakeFixturesFile is the file of a fake cluster directory that lists the command lines the fake cluster answers
+const FakeFixturesFile = "fixtures.json"
+
+// FakeExecutor is an executor that serves canned outputs from fixture files instead of running commands,
+// for tests and offline demos. Command lines are matched in their canonical form, so "kubectl get pods"
+// serves the fixture of "kubectl get po". Command lines without a fixture fail with exit code 1.
+type FakeExecutor struct {
+	fixtures map[string]fakeFixture
+	mutex    sync.Mutex
+	calls    [][]string
+}
+
+// fakeFixture is what the fake cluster answers to a command line
+type fakeFixture struct {
+	stdout   string
+	stderr   string
+	exitCode int
+	delay    time.Duration
+}
+
+// fakeFixtures is the structure of the fixtures file. Stdout and stderr are files relative to its directory.
+type fakeFixtures struct {
+	Fixtures []struct {
+		Command  string `json:"command"`
+		Stdout   string `json:"stdout"`
+		Stderr   string `json:"stderr"`
+		ExitCode int    `json:"exitCode"`
+		Delay    string `json:"delay"`
+	} `json:"fixtures"`
+}
+
+// Check interface
+var _ Executor = &FakeExecutor{}
+
+// NewFakeExecutor creates a FakeExecutor with the fixtures of a fake cluster directory
+func NewFakeExecutor(dir string) (*FakeExecutor, error) {
+	content, err := ioutil.ReadFile(filepath.Join(dir, FakeFixturesFile))
+	if err != nil {
+		return nil, err
+	}
+	var manifest fakeFixtures
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("Invalid %s: %v", FakeFixturesFile, err)
+	}
+
+	executor := FakeExecutor{fixtures: map[string]fakeFixture{}}
+	for _, entry := range manifest.Fixtures {
+		command, err := Canonicalize(entry.Command)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid fixture command %q: %v", entry.Command, err)
+		}
+		fixture := fakeFixture{exitCode: entry.ExitCode}
+		if fixture.stdout, err = readFixture(dir, entry.Stdout); err != nil {
+			return nil, err
+		}
+		if fixture.stderr, err = readFixture(dir, entry.Stderr); err != nil {
+			return nil, err
+		}
+		if entry.Delay != "" {
+			if fixture.delay, err = time.ParseDuration(entry.Delay); err != nil {
+				return nil, fmt.Errorf("Invalid delay of fixture %q: %v", entry.Command, err)
+			}
+		}
+		executor.fixtures[command] = fixture
+	}
+	return &executor, nil
+}
+
+// readFixture returns the content of a fixture file, or nothing if there is no file
+func readFixture(dir, file string) (string, error) {
+	if file == "" {
+		return "", nil
+	}
+	content, err := ioutil.ReadFile(filepath.Join(dir, file))
+	return string(content), err
+}
+
+// Execute writes the canned output of a command line, after its delay
+func (executor *FakeExecutor) Execute(ctx context.Context, args []string, env []string, stdout, stderr io.Writer) (int, error) {
+	executor.mutex.Lock()
+	executor.calls = append(executor.calls, args)
+	executor.mutex.Unlock()
+
+	command, err := Canonicalize(commandLine(args))
+	if err != nil {
+		return -1, err
+	}
+	fixture, ok := executor.fixtures[command]
+	if !ok {
+		fmt.Fprintf(stderr, "No fixture for %s\n", command)
+		return 1, nil
+	}
+
+	if fixture.delay > 0 {
+		timer := time.NewTimer(fixture.delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return -1, ctx.Err()
+		}
+	}
+	if _, err := io.WriteString(stdout, fixture.stdout); err != nil {
+		return -1, err
+	}
+	if _, err := io.WriteString(stderr, fixture.stderr); err != nil {
+		return -1, err
+	}
+	return fixture.exitCode, nil
+}
+
+// Calls returns the command lines the fake cluster was asked to run, in order
+func (executor *FakeExecutor) Calls() []string {
+	executor.mutex.Lock()
+	defer executor.mutex.Unlock()
+	calls := make([]string, len(executor.calls))
+	for index, args := range executor.calls {
+		calls[index] = commandLine(args)
+	}
+	return calls
+}
+
+// commandLine converts the arguments of a command to a command line, quoting them where a shell would need it
+func commandLine(args []string) string {
+	quoted := make([]string, len(args))
+	for index, arg := range args {
+		quoted[index] = quote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
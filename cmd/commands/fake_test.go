@@ -0,0 +1,140 @@
+package commands
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testFakeFixtures = `{
+	"fixtures": [
+		{"command": "kubectl get po", "stdout": "pods.txt"},
+		{"command": "kubectl -n kubeflow get pod", "stderr": "forbidden.txt", "exitCode": 1},
+		{"command": "kubectl get node", "stdout": "pods.txt", "delay": "1m"}
+	]
+}`
+
+func TestFakeExecutor_Execute(t *testing.T) {
+	// Arrange
+	dir, err := writeTmpFakeCluster(map[string]string{
+		FakeFixturesFile: testFakeFixtures,
+		"pods.txt":       "NAME    READY\nnginx   1/1\n",
+		"forbidden.txt":  "Error from server (Forbidden)\n",
+	})
+	assert.Nil(t, err)
+	executor, err := NewFakeExecutor(dir)
+	assert.Nil(t, err)
+
+	tests := []struct {
+		args     []string
+		stdout   string
+		stderr   string
+		exitCode int
+	}{
+		{[]string{"kubectl", "get", "pods"}, "NAME    READY\nnginx   1/1\n", "", 0},
+		{[]string{"kubectl", "get", "pod", "--namespace=kubeflow"}, "", "Error from server (Forbidden)\n", 1},
+		{[]string{"kubectl", "get", "svc"}, "", "No fixture for kubectl get service\n", 1},
+	}
+	for _, test := range tests {
+		t.Run(strings.Join(test.args, " "), func(t *testing.T) {
+			var stdout, stderr strings.Builder
+
+			// Act
+			exitCode, err := executor.Execute(context.Background(), test.args, nil, &stdout, &stderr)
+
+			// Assert
+			assert.Nil(t, err)
+			assert.Equal(t, test.exitCode, exitCode)
+			assert.Equal(t, test.stdout, stdout.String())
+			assert.Equal(t, test.stderr, stderr.String())
+		})
+	}
+	assert.Equal(t, []string{"kubectl get pods", "kubectl get pod --namespace=kubeflow", "kubectl get svc"}, executor.Calls())
+
+	// Cleanup
+	os.RemoveAll(dir)
+}
+
+func TestFakeExecutor_ExecuteCancelled(t *testing.T) {
+	// Arrange
+	dir, err := writeTmpFakeCluster(map[string]string{FakeFixturesFile: testFakeFixtures, "pods.txt": "nginx\n", "forbidden.txt": ""})
+	assert.Nil(t, err)
+	executor, err := NewFakeExecutor(dir)
+	assert.Nil(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	var stdout, stderr strings.Builder
+
+	// Act
+	exitCode, err := executor.Execute(ctx, []string{"kubectl", "get", "nodes"}, nil, &stdout, &stderr)
+
+	// Assert
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, -1, exitCode)
+	assert.Empty(t, stdout.String())
+
+	// Cleanup
+	os.RemoveAll(dir)
+}
+
+func TestFakeExecutor_MissingFile(t *testing.T) {
+	// Arrange
+	dir, err := writeTmpFakeCluster(map[string]string{FakeFixturesFile: testFakeFixtures})
+	assert.Nil(t, err)
+
+	// Act
+	_, err = NewFakeExecutor(dir)
+
+	// Assert
+	assert.NotNil(t, err)
+
+	// Cleanup
+	os.RemoveAll(dir)
+}
+
+func TestCForest_RunCmdFakeCluster(t *testing.T) {
+	// Arrange
+	dir, err := writeTmpFakeCluster(map[string]string{FakeFixturesFile: testFakeFixtures, "pods.txt": "nginx\n", "forbidden.txt": "Forbidden\n"})
+	assert.Nil(t, err)
+	executor, err := NewFakeExecutor(dir)
+	assert.Nil(t, err)
+	forest, err := NewCForest([]string{"kubectl"}, []string{"kubectl get pods", "kubectl -n kubeflow get pods"})
+	assert.Nil(t, err)
+	forest.Executor = executor
+	first, err := forest.GetSourceCmd("kubectl get pod")
+	assert.Nil(t, err)
+	second, err := forest.GetSourceCmd("kubectl -n kubeflow get pod")
+	assert.Nil(t, err)
+
+	// Act
+	succeeded, failed := first.Run(false), second.Run(false)
+
+	// Assert
+	assert.Equal(t, "nginx\n", *succeeded.Output)
+	assert.Equal(t, 0, succeeded.ExitCode)
+	assert.Equal(t, "Forbidden\n", failed.Stderr)
+	assert.Equal(t, 1, failed.ExitCode)
+
+	// Cleanup
+	os.RemoveAll(dir)
+}
+
+// writeTmpFakeCluster creates a fake cluster directory with certain files
+func writeTmpFakeCluster(files map[string]string) (string, error) {
+	dir, err := ioutil.TempDir(os.TempDir(), "superk_test_fake_")
+	if err != nil {
+		return "", err
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			return "", err
+		}
+	}
+	return dir, nil
+}
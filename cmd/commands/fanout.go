@@ -0,0 +1,262 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Columns of kubectl get, and fields of kubectl get -o yaml, that differ between clusters
+// even when their resources are the same
+var (
+	volatileColumns = map[string]bool{"AGE": true, "LAST SEEN": true}
+	volatileFields  = []string{"uid", "resourceVersion", "creationTimestamp", "generation", "selfLink"}
+)
+
+// Headers of tables are words in upper case (e.g. NAME, CLUSTER-IP, PORT(S), LAST SEEN) separated by two spaces or more
+var (
+	tableColumn = regexp.MustCompile(`\S+( \S+)*`)
+	tableHeader = regexp.MustCompile(`^[A-Z][A-Z0-9()/_.\-]*(\s+[A-Z0-9()/_.\-]+)*$`)
+)
+
+// Fanout represents a command that runs against several kube contexts at the same time, one copy per context
+type Fanout struct {
+	Contexts []string
+	Cmds     []*Cmd
+	Batch    *Batch
+}
+
+// DiffRange represents a part of a line of output, from byte Start to byte End
+type DiffRange struct {
+	Start int
+	End   int
+}
+
+// NewFanout creates the copies of a command that run against several kube contexts, up to limit of them at once
+func NewFanout(cmd *Cmd, contexts []string, limit int) (*Fanout, error) {
+	if len(contexts) == 0 {
+		return nil, errors.New("At least one kube context is required")
+	}
+	if !TakesKubeContext(cmd.Path) {
+		return nil, fmt.Errorf("%s commands do not take a kube context", cmd.Path)
+	}
+	if _, ok := lookupFlag(cmd.Args, "--context", "--kube-context"); ok {
+		return nil, fmt.Errorf("%s already runs against a kube context of its own", cmd.ToString())
+	}
+	if cmd.IsStreaming() {
+		return nil, fmt.Errorf("%s streams its output, so it never finishes", cmd.ToString())
+	}
+
+	fanout := Fanout{Contexts: contexts}
+	for _, context := range contexts {
+		fanout.Cmds = append(fanout.Cmds, cmd.copyFor(context))
+	}
+	fanout.Batch = NewBatch(fanout.Cmds, limit)
+	return &fanout, nil
+}
+
+// copyFor returns a copy of the command that runs the same way against another kube context, with no output yet
+func (cmd *Cmd) copyFor(context string) *Cmd {
+	cmd.mutex.Lock()
+	defer cmd.mutex.Unlock()
+	return &Cmd{
+		Path:        cmd.Path,
+		Args:        append([]string{}, cmd.Args...),
+		policy:      cmd.policy,
+		executor:    cmd.executor,
+		streaming:   cmd.streaming,
+		kubeContext: context,
+	}
+}
+
+// CompareOutputs compares the outputs of a command in several clusters, and returns the parts of each line
+// of each output that disagree with another output (e.g. a different image tag or number of replicas).
+// Tables (e.g. the output of kubectl get) are compared row by row, matching rows by the name of their resource,
+// and cell by cell, matching cells by their column. Other outputs (e.g. YAML) are compared line by line.
+// Lines that are not in every output disagree entirely. Columns and fields that always differ (e.g. AGE) are left out.
+func CompareOutputs(outputs []string) [][][]DiffRange {
+	parsed := make([]*parsedOutput, len(outputs))
+	for index, output := range outputs {
+		parsed[index] = parseOutput(output)
+	}
+
+	diffs := make([][][]DiffRange, len(outputs))
+	for index, output := range parsed {
+		diffs[index] = make([][]DiffRange, len(output.lines))
+		for line := range output.lines {
+			for other, compared := range parsed {
+				if other != index {
+					diffs[index][line] = mergeRanges(diffs[index][line], output.compare(line, compared))
+				}
+			}
+		}
+	}
+	return diffs
+}
+
+// parsedOutput represents an output split in lines, and in cells if it is a table
+type parsedOutput struct {
+	lines []string
+	// columns are the names of the columns of a table, and starts where they start in each line. They are nil otherwise.
+	columns []string
+	starts  []int
+	// rows are the lines by their key: the name (and namespace) of their resource in a table, or the line itself
+	rows map[string]int
+}
+
+func parseOutput(output string) *parsedOutput {
+	parsed := parsedOutput{rows: map[string]int{}}
+	if output = strings.TrimRight(output, "\n"); output != "" {
+		parsed.lines = strings.Split(output, "\n")
+	}
+	if len(parsed.lines) > 0 && tableHeader.MatchString(strings.TrimSpace(parsed.lines[0])) {
+		for _, span := range tableColumn.FindAllStringIndex(parsed.lines[0], -1) {
+			parsed.columns = append(parsed.columns, parsed.lines[0][span[0]:span[1]])
+			parsed.starts = append(parsed.starts, span[0])
+		}
+		// A single column may be a sentence in upper case rather than a table
+		if len(parsed.columns) < 2 {
+			parsed.columns, parsed.starts = nil, nil
+		}
+	}
+
+	for line := range parsed.lines {
+		if key := parsed.key(line); key != "" {
+			if _, ok := parsed.rows[key]; !ok {
+				parsed.rows[key] = line
+			}
+		}
+	}
+	return &parsed
+}
+
+// key returns what matches a line with the lines of other outputs, or nothing if the line is not compared
+func (output *parsedOutput) key(line int) string {
+	if output.columns == nil {
+		text := strings.TrimRight(output.lines[line], " \t\r")
+		if text == "" || isVolatileField(text) {
+			return ""
+		}
+		return text
+	}
+	if line == 0 {
+		// Headers are compared column by column, not as rows
+		return ""
+	}
+
+	namespace, _ := output.cell(line, "NAMESPACE")
+	name, ok := output.cell(line, "NAME")
+	if !ok {
+		name, _ = output.cell(line, output.columns[0])
+	}
+	return namespace + "/" + name
+}
+
+// cell returns the text in a column of a line of a table, and whether the table has that column
+func (output *parsedOutput) cell(line int, column string) (string, bool) {
+	start, end, ok := output.span(line, column)
+	if !ok {
+		return "", false
+	}
+	return output.lines[line][start:end], true
+}
+
+// span returns where the text in a column of a line of a table starts and ends, without the spaces around it
+func (output *parsedOutput) span(line int, column string) (int, int, bool) {
+	for index, name := range output.columns {
+		if name != column {
+			continue
+		}
+		text := output.lines[line]
+		start, end := output.starts[index], len(text)
+		if index+1 < len(output.starts) {
+			end = output.starts[index+1]
+		}
+		if start > len(text) {
+			start = len(text)
+		}
+		if end > len(text) {
+			end = len(text)
+		}
+		for start < end && text[start] == ' ' {
+			start++
+		}
+		for end > start && text[end-1] == ' ' {
+			end--
+		}
+		return start, end, true
+	}
+	return 0, 0, false
+}
+
+// compare returns the parts of a line of this output that disagree with another output
+func (output *parsedOutput) compare(line int, other *parsedOutput) []DiffRange {
+	key := output.key(line)
+	if key == "" {
+		return nil
+	}
+	whole := []DiffRange{{Start: 0, End: len(strings.TrimRight(output.lines[line], " \t\r"))}}
+	otherLine, ok := other.rows[key]
+	if !ok {
+		return whole
+	}
+	if output.columns == nil {
+		return nil
+	}
+
+	var ranges []DiffRange
+	for _, column := range output.columns {
+		if volatileColumns[column] {
+			continue
+		}
+		start, end, _ := output.span(line, column)
+		value, ok := other.cell(otherLine, column)
+		if ok && value != output.lines[line][start:end] && start < end {
+			ranges = append(ranges, DiffRange{Start: start, End: end})
+		}
+	}
+	return ranges
+}
+
+// isVolatileField returns whether a line of YAML or JSON is a field that differs between clusters
+func isVolatileField(line string) bool {
+	line = strings.TrimLeft(strings.TrimSpace(line), "- ")
+	for _, field := range volatileFields {
+		if strings.HasPrefix(line, field+":") || strings.HasPrefix(line, `"`+field+`":`) {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeRanges adds ranges to sorted ranges that do not overlap, keeping them sorted and without overlaps
+func mergeRanges(ranges, added []DiffRange) []DiffRange {
+	for _, add := range added {
+		var merged []DiffRange
+		inserted := false
+		for _, current := range ranges {
+			switch {
+			case current.End < add.Start:
+				merged = append(merged, current)
+			case add.End < current.Start:
+				if !inserted {
+					merged, inserted = append(merged, add), true
+				}
+				merged = append(merged, current)
+			default:
+				if current.Start < add.Start {
+					add.Start = current.Start
+				}
+				if current.End > add.End {
+					add.End = current.End
+				}
+			}
+		}
+		if !inserted {
+			merged = append(merged, add)
+		}
+		ranges = merged
+	}
+	return ranges
+}
@@ -0,0 +1,149 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFanout(t *testing.T) {
+	// Arrange
+	cmd := NewCmd("kubectl", "get", "pod")
+	cmd.SetPolicy(Policy{Retry: RetryPolicy{MaxAttempts: 2}})
+
+	// Act
+	fanout, err := NewFanout(cmd, []string{"aks-dev", "aks-prod"}, 4)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Len(t, fanout.Cmds, 2)
+	assert.EqualValues(t, []string{"kubectl", "--context", "aks-dev", "get", "pod"}, fanout.Cmds[0].ExecArgs())
+	assert.EqualValues(t, []string{"kubectl", "--context", "aks-prod", "get", "pod"}, fanout.Cmds[1].ExecArgs())
+	assert.Equal(t, cmd.policy, fanout.Cmds[1].policy)
+	assert.EqualValues(t, []string{"kubectl", "get", "pod"}, cmd.ExecArgs())
+}
+
+func TestNewFanout_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		cmd      *Cmd
+		contexts []string
+	}{
+		{"no contexts", NewCmd("kubectl", "get", "pod"), nil},
+		{"binary without contexts", NewCmd("docker", "ps"), []string{"aks-dev"}},
+		{"context of its own", NewCmd("kubectl", "--context", "aks-dev", "get", "pod"), []string{"aks-prod"}},
+		{"streaming", NewCmd("kubectl", "logs", "-f", "nginx"), []string{"aks-prod"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			fanout, err := NewFanout(test.cmd, test.contexts, 4)
+
+			// Assert
+			assert.Nil(t, fanout)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestFanout_Run(t *testing.T) {
+	// Arrange
+	dir, err := writeTmpFakeCluster(map[string]string{
+		"fixtures.json": `{"fixtures": [
+			{"command": "kubectl --context aks-dev get ns", "stdout": "dev.txt"},
+			{"command": "kubectl --context aks-prod get ns", "stdout": "prod.txt"}
+		]}`,
+		"dev.txt":  "dev\n",
+		"prod.txt": "prod\n",
+	})
+	assert.Nil(t, err)
+	executor, err := NewFakeExecutor(dir)
+	assert.Nil(t, err)
+	cmd := NewCmd("kubectl", "get", "ns")
+	cmd.SetExecutor(executor)
+	fanout, err := NewFanout(cmd, []string{"aks-dev", "aks-prod"}, 1)
+	assert.Nil(t, err)
+
+	// Act
+	done := make(chan struct{}, 2)
+	fanout.Batch.Run(func(entry BatchEntry) {
+		if entry.Status == BatchDone {
+			done <- struct{}{}
+		}
+	})
+	<-done
+	<-done
+
+	// Assert
+	assert.Equal(t, "dev\n", *fanout.Cmds[0].GetOutput().Output)
+	assert.Equal(t, "prod\n", *fanout.Cmds[1].GetOutput().Output)
+	assert.Nil(t, cmd.GetOutput().Output)
+}
+
+func TestCompareOutputs_Table(t *testing.T) {
+	// Arrange
+	dev := strings.Join([]string{
+		"NAME    READY   UP-TO-DATE   AVAILABLE   AGE",
+		"api     3/3     3            3           12d",
+		"web     2/2     2            2           3d",
+		"worker  1/1     1            1           5h",
+	}, "\n")
+	prod := strings.Join([]string{
+		"NAME   READY   UP-TO-DATE   AVAILABLE   AGE",
+		"web    5/5     5            5           40d",
+		"api    3/3     3            3           90d",
+	}, "\n")
+
+	// Act
+	result := CompareOutputs([]string{dev, prod})
+
+	// Assert
+	assert.Len(t, result, 2)
+	assert.EqualValues(t, [][]DiffRange{
+		nil,
+		nil,
+		{{8, 11}, {16, 17}, {29, 30}},
+		{{0, 43}},
+	}, result[0])
+	assert.EqualValues(t, [][]DiffRange{
+		nil,
+		{{7, 10}, {15, 16}, {28, 29}},
+		nil,
+	}, result[1])
+}
+
+func TestCompareOutputs_Lines(t *testing.T) {
+	// Arrange
+	dev := "metadata:\n  name: web\n  uid: 1234\nspec:\n  image: nginx:1.21\n"
+	prod := "metadata:\n  name: web\n  uid: 5678\nspec:\n  image: nginx:1.23\n"
+
+	// Act
+	result := CompareOutputs([]string{dev, prod, dev})
+
+	// Assert
+	assert.EqualValues(t, [][]DiffRange{nil, nil, nil, nil, {{0, 19}}}, result[0])
+	assert.EqualValues(t, [][]DiffRange{nil, nil, nil, nil, {{0, 19}}}, result[1])
+}
+
+func TestMergeRanges(t *testing.T) {
+	tests := []struct {
+		ranges   []DiffRange
+		added    []DiffRange
+		expected []DiffRange
+	}{
+		{nil, []DiffRange{{2, 4}}, []DiffRange{{2, 4}}},
+		{[]DiffRange{{2, 4}}, []DiffRange{{6, 8}, {0, 1}}, []DiffRange{{0, 1}, {2, 4}, {6, 8}}},
+		{[]DiffRange{{2, 4}, {6, 8}}, []DiffRange{{3, 7}}, []DiffRange{{2, 8}}},
+		{[]DiffRange{{2, 4}}, nil, []DiffRange{{2, 4}}},
+	}
+
+	for _, test := range tests {
+		// Act
+		result := mergeRanges(test.ranges, test.added)
+
+		// Assert
+		assert.EqualValues(t, test.expected, result)
+	}
+}
@@ -0,0 +1,318 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MutatingCommands are the commands that change the cluster, so they need confirmation before they run.
+// Each one matches the commands that start with its words, after the binary and the global flags, and have all its flags.
+// The words of nested roots may come after any of their command groups (e.g. "delete" matches "az aks delete").
+var MutatingCommands = []string{
+	"delete",
+	"drain",
+	"scale",
+	"autoscale",
+	"apply",
+	"replace",
+	"create",
+	"patch",
+	"edit",
+	"label",
+	"annotate",
+	"cordon",
+	"uncordon",
+	"taint",
+	"set",
+	"run",
+	"expose",
+	"exec",
+	"cp",
+	"certificate approve",
+	"certificate deny",
+	"rollout restart",
+	"rollout undo",
+	"rollout pause",
+	"rollout resume",
+	"install",
+	"upgrade",
+	"uninstall",
+	"rollback",
+}
+
+// ChangingCommands are the commands that change the cluster, so they do not run against read-only kube contexts.
+// Unlike the mutating commands, which only decide what needs confirmation, they are not meant to be relaxed
+// and include anything that may change the cluster (e.g. exec runs anything in a container).
+var ChangingCommands = []string{
+	"delete",
+	"drain",
+	"scale",
+	"autoscale",
+	"apply",
+	"replace",
+	"create",
+	"patch",
+	"edit",
+	"label",
+	"annotate",
+	"cordon",
+	"uncordon",
+	"taint",
+	"set",
+	"run",
+	"expose",
+	"exec",
+	"attach",
+	"cp",
+	"debug",
+	"certificate approve",
+	"certificate deny",
+	"auth reconcile",
+	"rollout restart",
+	"rollout undo",
+	"rollout pause",
+	"rollout resume",
+	"install",
+	"upgrade",
+	"uninstall",
+	"rollback",
+}
+
+// globalValueFlags are the flags that take a value before the verb of a command (e.g. kubectl --context prod delete pod x).
+// Any other flag before the verb is taken as a boolean flag.
+var globalValueFlags = map[string]bool{
+	// kubectl and oc
+	"-n":                      true,
+	"--namespace":             true,
+	"--kubeconfig":            true,
+	"--context":               true,
+	"--cluster":               true,
+	"--user":                  true,
+	"-s":                      true,
+	"--server":                true,
+	"--as":                    true,
+	"--as-group":              true,
+	"--as-uid":                true,
+	"--request-timeout":       true,
+	"--token":                 true,
+	"--username":              true,
+	"--password":              true,
+	"--certificate-authority": true,
+	"--client-certificate":    true,
+	"--client-key":            true,
+	"--tls-server-name":       true,
+	"--cache-dir":             true,
+	"--profile":               true,
+	"--profile-output":        true,
+	"-v":                      true,
+	"--v":                     true,
+	"--vmodule":               true,
+	"--log-file":              true,
+	"--log-dir":               true,
+	"--log-file-max-size":     true,
+	"--log-flush-frequency":   true,
+	"--log-backtrace-at":      true,
+	"--stderrthreshold":       true,
+	// Flags of the verbs that are often written before them
+	"-o":          true,
+	"--output":    true,
+	"-l":          true,
+	"--selector":  true,
+	"-f":          true,
+	"--filename":  true,
+	"-c":          true,
+	"--container": true,
+	// helm
+	"--kube-context":         true,
+	"--kube-token":           true,
+	"--kube-apiserver":       true,
+	"--kube-as-user":         true,
+	"--kube-as-group":        true,
+	"--kube-ca-file":         true,
+	"--kube-tls-server-name": true,
+	"--burst-limit":          true,
+	"--qps":                  true,
+	"--registry-config":      true,
+	"--repository-cache":     true,
+	"--repository-config":    true,
+	// az
+	"--subscription":   true,
+	"-g":               true,
+	"--resource-group": true,
+	"--query":          true,
+}
+
+// nestedRoots are the binaries whose commands are nested in groups (e.g. az aks nodepool delete),
+// so their verb may be any positional argument before the first flag.
+// The verb of the other binaries is their first positional argument (e.g. kubectl delete).
+var nestedRoots = map[string]bool{
+	"az": true,
+}
+
+// Guard decides which commands need confirmation before they run:
+// the mutating commands and the denied ones, except the allowed ones.
+// Commands that are both allowed and denied need confirmation.
+type Guard struct {
+	mutating [][]string
+	allow    [][]string
+	deny     [][]string
+}
+
+// NewGuard creates a guard with the commands that run without confirmation even if they are mutating (e.g. "apply"),
+// and the commands that need confirmation even if they are not (e.g. "-n production")
+func NewGuard(allow, deny []string) (*Guard, error) {
+	var guard Guard
+	var err error
+	if guard.mutating, err = guardEntries(MutatingCommands); err != nil {
+		return nil, err
+	}
+	if guard.allow, err = guardEntries(allow); err != nil {
+		return nil, err
+	}
+	if guard.deny, err = guardEntries(deny); err != nil {
+		return nil, err
+	}
+	return &guard, nil
+}
+
+// DefaultGuard creates a guard for the mutating commands
+func DefaultGuard() *Guard {
+	// The mutating commands are valid entries
+	guard, _ := NewGuard(nil, nil)
+	return guard
+}
+
+func guardEntries(commands []string) ([][]string, error) {
+	entries := make([][]string, 0, len(commands))
+	for _, command := range commands {
+		words, err := tokenize(command)
+		if err != nil || len(words) == 0 {
+			return nil, fmt.Errorf("Invalid guarded command %q", command)
+		}
+		entries = append(entries, partArgs(command))
+	}
+	return entries, nil
+}
+
+// Guards returns whether a command needs confirmation before it runs. A nil guard guards the mutating commands.
+func (guard *Guard) Guards(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	if guard == nil {
+		guard = DefaultGuard()
+	}
+	if guardMatches(guard.deny, args) {
+		return true
+	}
+	return guardMatches(guard.mutating, args) && !guardMatches(guard.allow, args)
+}
+
+// Mutates returns whether a command changes the cluster, according to the commands that change it.
+// Commands without a verb may change it too.
+func Mutates(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	if positionals, _, _ := guardWords(args); len(positionals) == 0 {
+		return true
+	}
+	// The commands that change the cluster are valid entries
+	changing, _ := guardEntries(ChangingCommands)
+	return (&Guard{mutating: changing}).Guards(args)
+}
+
+// guardMatches returns whether the arguments of a command match any of the entries.
+// Entries and commands are compared in their canonical form, so "delete po" also matches "kubectl delete pods".
+func guardMatches(entries [][]string, args []string) bool {
+	positionals, flags, path := guardWords(args)
+	if !nestedRoots[args[0]] {
+		path = 1
+	}
+	for _, entry := range entries {
+		entryPositionals, entryFlags, _ := guardWords(append([]string{args[0]}, entry...))
+		if !containsAll(flags, entryFlags) {
+			continue
+		}
+		// The entry may match any command group of the subcommand path (e.g. "delete" in "az aks delete")
+		for depth := 0; depth == 0 || depth < path; depth++ {
+			if hasPrefix(positionals[depth:], entryPositionals) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// guardWords returns the positional arguments and the flags of a command, without its binary and anything after "--",
+// and how many positional arguments come before the first flag that follows them, which are the subcommand path of nested roots.
+// Before the verb only the global flags that take a value take the next argument, so unknown boolean flags
+// never hide the verb (e.g. helm --debug uninstall nginx).
+// Flags are written as name=value, so "--replicas 0" and "--replicas=0" are the same flag.
+// The flags and resources of kubectl and oc are in their canonical form, so "--namespace=x" and "-nx" are the same flag.
+func guardWords(args []string) ([]string, []string, int) {
+	canonical := canonicalRoots[args[0]]
+	var positionals, flags []string
+	path := -1
+	for index := 1; index < len(args); index++ {
+		arg := args[index]
+		if arg == "--" {
+			break
+		}
+		if !isFlag(arg) {
+			positionals = append(positionals, arg)
+			continue
+		}
+		if path < 0 && len(positionals) > 0 {
+			path = len(positionals)
+		}
+		takes := globalValueFlags[arg]
+		if len(positionals) > 0 {
+			takes = takesValue(arg, positionals[0])
+		}
+		flag := arg
+		if takes && index+1 < len(args) && !isFlag(args[index+1]) {
+			index++
+			flag += " " + quote(args[index])
+		}
+		if canonical {
+			_, flag = canonicalFlag(flag)
+		}
+		flags = append(flags, strings.Replace(flag, " ", "=", 1))
+	}
+	if path < 0 {
+		path = len(positionals)
+	}
+	if canonical {
+		canonicalizeResources(positionals)
+	}
+	return positionals, flags, path
+}
+
+func hasPrefix(words, prefix []string) bool {
+	if len(prefix) > len(words) {
+		return false
+	}
+	for index, word := range prefix {
+		if words[index] != word {
+			return false
+		}
+	}
+	return true
+}
+
+func containsAll(words, wanted []string) bool {
+	for _, want := range wanted {
+		found := false
+		for _, word := range words {
+			if word == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuard_Guards(t *testing.T) {
+	// Arrange
+	guard, err := NewGuard([]string{"apply", "-n dev delete"}, []string{"-n production", "rollout status"})
+	assert.Nil(t, err)
+	tests := []struct {
+		args     []string
+		expected bool
+	}{
+		{[]string{"kubectl", "get", "pod"}, false},
+		{[]string{"kubectl", "delete", "pod", "nginx"}, true},
+		{[]string{"kubectl", "-n", "kubeflow", "delete", "po", "nginx"}, true},
+		{[]string{"kubectl", "drain", "node1", "--ignore-daemonsets"}, true},
+		{[]string{"kubectl", "scale", "deploy", "nginx", "--replicas=0"}, true},
+		{[]string{"kubectl", "scale", "deploy", "nginx", "--replicas", "0"}, true},
+		{[]string{"kubectl", "scale", "deploy", "nginx", "--replicas=3"}, true},
+		{[]string{"kubectl", "scale", "deploy/nginx", "--replicas=1"}, true},
+		{[]string{"kubectl", "autoscale", "deploy", "nginx", "--max=5"}, true},
+		{[]string{"kubectl", "run", "debug", "--image=busybox"}, true},
+		{[]string{"kubectl", "expose", "deploy", "nginx", "--port=80"}, true},
+		{[]string{"kubectl", "rollout", "pause", "deploy/nginx"}, true},
+		{[]string{"kubectl", "rollout", "resume", "deploy/nginx"}, true},
+		{[]string{"kubectl", "cp", "nginx:/tmp/cache", "cache"}, true},
+		{[]string{"kubectl", "certificate", "approve", "csr-1"}, true},
+		{[]string{"kubectl", "replace", "-f", "app.yaml"}, true},
+		{[]string{"kubectl", "rollout", "restart", "deploy/nginx"}, true},
+		{[]string{"kubectl", "config", "delete-context", "old"}, false},
+		{[]string{"kubectl", "logs", "delete"}, false},
+		{[]string{"kubectl", "exec", "nginx", "--", "rm", "-f", "/tmp/cache"}, true},
+		{[]string{"helm", "uninstall", "nginx"}, true},
+		{[]string{"helm", "--debug", "uninstall", "nginx"}, true},
+		{[]string{"helm", "--kube-context", "prod", "uninstall", "nginx"}, true},
+		{[]string{"kubectl", "--warnings-as-errors", "delete", "pod", "nginx"}, true},
+		{[]string{"kubectl", "--match-server-version", "delete", "pod", "nginx"}, true},
+		{[]string{"kubectl", "--context", "prod", "--insecure-skip-tls-verify", "delete", "pod", "nginx"}, true},
+		{[]string{"kubectl", "-nkubeflow", "delete", "pod", "nginx"}, true},
+		{[]string{"az", "aks", "delete", "-n", "cluster1"}, true},
+		{[]string{"az", "aks", "nodepool", "delete", "--name", "pool1"}, true},
+		{[]string{"az", "--debug", "aks", "delete", "-n", "cluster1"}, true},
+		{[]string{"az", "aks", "show", "-n", "delete"}, false},
+		{[]string{"kubectl", "apply", "-f", "app.yaml"}, false},
+		{[]string{"kubectl", "--namespace=dev", "delete", "pod", "nginx"}, false},
+		{[]string{"kubectl", "get", "pod", "-n", "production"}, true},
+		{[]string{"kubectl", "-n", "production", "apply", "-f", "app.yaml"}, true},
+		{[]string{"kubectl", "rollout", "status", "deploy/nginx"}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(strings.Join(test.args, " "), func(t *testing.T) {
+			// Act
+			result := guard.Guards(test.args)
+
+			// Assert
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func TestGuard_Default(t *testing.T) {
+	// Arrange
+	guard := DefaultGuard()
+
+	// Act
+	deleted := guard.Guards([]string{"kubectl", "delete", "ns", "kubeflow"})
+	applied := guard.Guards([]string{"kubectl", "apply", "-f", "app.yaml"})
+	listed := guard.Guards([]string{"kubectl", "get", "ns"})
+
+	// Assert
+	assert.True(t, deleted)
+	assert.True(t, applied)
+	assert.False(t, listed)
+}
+
+func TestGuard_NewInvalid(t *testing.T) {
+	// Act
+	guard, err := NewGuard(nil, []string{"delete 'unclosed"})
+
+	// Assert
+	assert.Nil(t, guard)
+	assert.NotNil(t, err)
+}
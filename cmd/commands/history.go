@@ -0,0 +1,328 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// HistoryFormat represents the format of a shell history file
+type HistoryFormat string
+
+// Supported shell history formats
+const (
+	BashHistory HistoryFormat = "bash"
+	ZshHistory  HistoryFormat = "zsh"
+	FishHistory HistoryFormat = "fish"
+)
+
+// Commands that can run another command, and their flags that take a value.
+// They are stripped from history commands (e.g. "sudo -E kubectl get pod" becomes "kubectl get pod").
+var prefixCommands = map[string][]string{
+	"sudo":    {"-u", "-g", "-p", "-C"},
+	"env":     {"-u", "-C", "-S"},
+	"time":    nil,
+	"command": nil,
+	"nohup":   nil,
+	"exec":    nil,
+}
+
+// DefaultHistoryFiles returns the history files of the supported shells that exist for the current user
+func DefaultHistoryFiles() []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	fishDir := os.Getenv("XDG_DATA_HOME")
+	if fishDir == "" {
+		fishDir = filepath.Join(home, ".local", "share")
+	}
+
+	var files []string
+	for _, file := range []string{
+		filepath.Join(home, ".bash_history"),
+		filepath.Join(home, ".zsh_history"),
+		filepath.Join(fishDir, "fish", "fish_history"),
+	} {
+		if _, err := os.Stat(file); err == nil {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// ReadHistory returns the command lines in a shell history file, oldest first
+func ReadHistory(file string) ([]string, error) {
+	bytes, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	content := string(bytes)
+	return ParseHistory(content, DetectHistoryFormat(file, content)), nil
+}
+
+// DetectHistoryFormat guesses the format of a shell history file from its name and content
+func DetectHistoryFormat(file, content string) HistoryFormat {
+	name := filepath.Base(file)
+	switch {
+	case strings.Contains(name, "fish") || strings.HasPrefix(content, "- cmd: "):
+		return FishHistory
+	case strings.Contains(name, "zsh") || strings.HasPrefix(content, ": "):
+		return ZshHistory
+	default:
+		return BashHistory
+	}
+}
+
+// ParseHistory returns the command lines in the content of a shell history file, oldest first
+func ParseHistory(content string, format HistoryFormat) []string {
+	switch format {
+	case ZshHistory:
+		return parseZshHistory(content)
+	case FishHistory:
+		return parseFishHistory(content)
+	default:
+		return parseBashHistory(content)
+	}
+}
+
+// parseBashHistory parses one command per line, skipping the timestamps bash writes when HISTTIMEFORMAT is set
+// Example:
+//   #1580000000
+//   kubectl get pod
+func parseBashHistory(content string) []string {
+	var lines []string
+	for _, line := range joinContinuations(strings.Split(content, "\n")) {
+		if isBashTimestamp(line) || strings.TrimSpace(line) == "" {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func isBashTimestamp(line string) bool {
+	return len(line) > 1 && line[0] == '#' && strings.Trim(line[1:], "0123456789") == ""
+}
+
+// parseZshHistory parses the extended format of zsh, where every command has a timestamp and a duration
+// Example:
+//   : 1580000000:0;kubectl get pod
+func parseZshHistory(content string) []string {
+	var lines []string
+	for _, line := range joinContinuations(strings.Split(unmetafy(content), "\n")) {
+		if strings.HasPrefix(line, ": ") {
+			if index := strings.Index(line, ";"); index >= 0 {
+				line = line[index+1:]
+			}
+		}
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// unmetafy decodes the non-ASCII characters that zsh escapes in its history file
+func unmetafy(content string) string {
+	const meta = 0x83
+	if strings.IndexByte(content, meta) < 0 {
+		return content
+	}
+	decoded := make([]byte, 0, len(content))
+	for index := 0; index < len(content); index++ {
+		if content[index] == meta && index+1 < len(content) {
+			index++
+			decoded = append(decoded, content[index]^32)
+			continue
+		}
+		decoded = append(decoded, content[index])
+	}
+	return string(decoded)
+}
+
+// parseFishHistory parses the YAML-like format of fish
+// Example:
+//   - cmd: kubectl get pod
+//     when: 1580000000
+func parseFishHistory(content string) []string {
+	var lines []string
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(line, "- cmd: ") {
+			line = strings.TrimPrefix(line, "- cmd: ")
+			line = strings.NewReplacer(`\\`, `\`, `\n`, "\n").Replace(line)
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// joinContinuations joins the lines that end with a backslash with the line that follows them
+func joinContinuations(lines []string) []string {
+	var joined []string
+	current := ""
+	for _, line := range lines {
+		if strings.HasSuffix(line, `\`) && !strings.HasSuffix(line, `\\`) {
+			current += strings.TrimSuffix(line, `\`)
+			continue
+		}
+		joined = append(joined, current+line)
+		current = ""
+	}
+	if current != "" {
+		joined = append(joined, current)
+	}
+	return joined
+}
+
+// CleanHistoryCommand extracts the command for one of the allowed roots from a shell command line.
+// Prefixes like sudo or environment variables, and anything after a pipe, list operator or redirection, are stripped.
+// It returns false if the command line is not for one of the roots, or superk could not run it without a shell.
+// Example: "sudo KUBECONFIG=x kubectl get pod | grep web" becomes "kubectl get pod"
+func CleanHistoryCommand(line string, roots []string) (string, bool) {
+	line, ok := cutCommand(line)
+	if !ok {
+		return "", false
+	}
+	words, err := tokenize(line)
+	if err != nil {
+		return "", false
+	}
+
+	words = stripPrefixes(words)
+	if len(words) == 0 {
+		return "", false
+	}
+
+	root := filepath.Base(words[0].value)
+	if !isOneOf(root, roots) {
+		return "", false
+	}
+
+	parts := []string{root}
+	for _, word := range words[1:] {
+		parts = append(parts, word.raw)
+	}
+	return strings.Join(parts, " "), true
+}
+
+// cutCommand returns the first command of a shell command line, before any pipe, list operator or redirection.
+// It returns false if the command uses variables or command substitution, which only a shell can expand.
+func cutCommand(line string) (string, bool) {
+	var quote rune
+	escaped := false
+	for index, char := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case char == '\\' && quote != '\'':
+			escaped = true
+		case quote == '\'':
+			if char == quote {
+				quote = 0
+			}
+		case char == '$' || char == '`':
+			return "", false
+		case quote == '"':
+			if char == quote {
+				quote = 0
+			}
+		case char == '\'' || char == '"':
+			quote = char
+		case strings.ContainsRune("|;&<>", char):
+			command := line[:index]
+			// Drop the file descriptor of redirections like 2>&1
+			if char == '<' || char == '>' {
+				if trimmed := strings.TrimRight(command, "0123456789"); strings.HasSuffix(trimmed, " ") {
+					command = trimmed
+				}
+			}
+			return strings.TrimSpace(command), true
+		}
+	}
+	return strings.TrimSpace(line), true
+}
+
+// stripPrefixes removes the commands that run another command, and environment variable assignments
+func stripPrefixes(words []word) []word {
+	for len(words) > 0 {
+		if isAssignment(words[0].value) {
+			words = words[1:]
+			continue
+		}
+
+		valueFlags, ok := prefixCommands[words[0].value]
+		if !ok {
+			return words
+		}
+		words = words[1:]
+		for len(words) > 0 && isFlag(words[0].value) {
+			flag := words[0].value
+			words = words[1:]
+			for _, valueFlag := range valueFlags {
+				if flag == valueFlag && len(words) > 0 {
+					words = words[1:]
+				}
+			}
+		}
+	}
+	return words
+}
+
+func isOneOf(value string, values []string) bool {
+	for _, current := range values {
+		if current == value {
+			return true
+		}
+	}
+	return false
+}
+
+// isAssignment returns whether a word sets an environment variable (e.g. KUBECONFIG=~/.kube/dev)
+func isAssignment(value string) bool {
+	index := strings.Index(value, "=")
+	if index <= 0 {
+		return false
+	}
+	for position, char := range value[:index] {
+		letter := char == '_' || (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z')
+		digit := char >= '0' && char <= '9'
+		if !letter && !(digit && position > 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// PreviewImport returns the commands of some shell command lines that importing them would add to the forest.
+// Commands that are already in the forest, or that are duplicates of each other, are left out.
+func (forest *CForest) PreviewImport(lines []string) []string {
+	preview, err := NewCForest(forest.Roots, forest.Serialize())
+	if err != nil {
+		return nil
+	}
+
+	var commands []string
+	for _, line := range lines {
+		command, ok := CleanHistoryCommand(line, forest.Roots)
+		if !ok || preview.GetPosition(command) != nil {
+			continue
+		}
+		if err := preview.MergeCommand(command); err != nil {
+			continue
+		}
+		commands = append(commands, command)
+	}
+	return commands
+}
+
+// Import merges commands into the forest, usually the ones returned by PreviewImport
+func (forest *CForest) Import(commands []string) error {
+	for _, command := range commands {
+		if err := forest.MergeCommand(command); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistory_ParseBash(t *testing.T) {
+	// Arrange
+	content := "#1580000000\nkubectl get pod\nls -la\n\nkubectl -n kubeflow \\\nget cronjob\n"
+
+	// Act
+	result := ParseHistory(content, BashHistory)
+
+	// Assert
+	assert.EqualValues(t, []string{"kubectl get pod", "ls -la", "kubectl -n kubeflow get cronjob"}, result)
+}
+
+func TestHistory_ParseZsh(t *testing.T) {
+	// Arrange
+	content := ": 1580000000:0;kubectl get pod\n: 1580000010:2;helm list;echo done\n: 1580000020:0;kubectl \\\nget cronjob\n"
+
+	// Act
+	result := ParseHistory(content, ZshHistory)
+
+	// Assert
+	assert.EqualValues(t, []string{"kubectl get pod", "helm list;echo done", "kubectl get cronjob"}, result)
+}
+
+func TestHistory_ParseZshMetafied(t *testing.T) {
+	// Arrange
+	content := ": 1580000000:0;echo caf\xc3\x83\x89\n"
+
+	// Act
+	result := ParseHistory(content, ZshHistory)
+
+	// Assert
+	assert.EqualValues(t, []string{"echo café"}, result)
+}
+
+func TestHistory_ParseFish(t *testing.T) {
+	// Arrange
+	content := "- cmd: kubectl get pod\n  when: 1580000000\n- cmd: kubectl get pod -l 'a\\\\b'\n  when: 1580000010\n  paths:\n    - pod\n"
+
+	// Act
+	result := ParseHistory(content, FishHistory)
+
+	// Assert
+	assert.EqualValues(t, []string{"kubectl get pod", `kubectl get pod -l 'a\b'`}, result)
+}
+
+func TestHistory_DetectFormat(t *testing.T) {
+	// Arrange
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		expected HistoryFormat
+	}{
+		{"Bash", "/home/user/.bash_history", "kubectl get pod\n", BashHistory},
+		{"Zsh by name", "/home/user/.zsh_history", "kubectl get pod\n", ZshHistory},
+		{"Zsh by content", "/home/user/.histfile", ": 1580000000:0;kubectl get pod\n", ZshHistory},
+		{"Fish", "/home/user/.local/share/fish/fish_history", "- cmd: kubectl get pod\n", FishHistory},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			result := DetectHistoryFormat(test.file, test.content)
+
+			// Assert
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func TestHistory_CleanCommand(t *testing.T) {
+	// Arrange
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		ok       bool
+	}{
+		{"Plain", "kubectl get pod", "kubectl get pod", true},
+		{"Sudo", "sudo -E -u admin kubectl get pod", "kubectl get pod", true},
+		{"Env", "KUBECONFIG=~/.kube/dev env -i FOO=bar kubectl get pod", "kubectl get pod", true},
+		{"Pipe", "kubectl get pod -o json | jq .items", "kubectl get pod -o json", true},
+		{"List", "kubectl get pod && echo done", "kubectl get pod", true},
+		{"Redirection", "kubectl logs web 2>&1 > out.log", "kubectl logs web", true},
+		{"Quoted pipe", "kubectl get pod -l 'app in (a|b)'", "kubectl get pod -l 'app in (a|b)'", true},
+		{"Path", "/usr/local/bin/helm list", "helm list", true},
+		{"Other root", "ls -la", "", false},
+		{"Variable", "kubectl -n $NAMESPACE get pod", "", false},
+		{"Command substitution", "kubectl delete pod `kubectl get pod -o name`", "", false},
+		{"Quoted variable", "kubectl get pod -l '$app'", "kubectl get pod -l '$app'", true},
+		{"Unterminated quote", "kubectl get pod -l 'app", "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			result, ok := CleanHistoryCommand(test.input, []string{"kubectl", "helm"})
+
+			// Assert
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func TestHistory_PreviewImport(t *testing.T) {
+	// Arrange
+	forest, err := NewCForest([]string{"kubectl", "helm"}, []string{"kubectl -n kubeflow get pod"})
+	assert.Nil(t, err)
+	lines := []string{
+		"kubectl get pod --namespace kubeflow",
+		"kubectl -n kubeflow get",
+		"sudo helm list",
+		"kubectl get cronjob | grep nightly",
+		"kubectl get cronjobs",
+		"ls -la",
+	}
+
+	// Act
+	result := forest.PreviewImport(lines)
+
+	// Assert
+	assert.EqualValues(t, []string{"helm list", "kubectl get cronjob"}, result)
+	assert.EqualValues(t, []string{"kubectl -n kubeflow get pod"}, forest.Serialize())
+}
+
+func TestHistory_Import(t *testing.T) {
+	// Arrange
+	forest, err := NewCForest([]string{"kubectl", "helm"}, []string{"kubectl -n kubeflow get pod"})
+	assert.Nil(t, err)
+
+	// Act
+	err = forest.Import([]string{"helm list", "kubectl get cronjob"})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"kubectl -n kubeflow get pod", "kubectl get cronjob", "helm list"}, forest.Serialize())
+}
+
+func TestHistory_Read(t *testing.T) {
+	// Arrange
+	dir, err := ioutil.TempDir(os.TempDir(), "superk_test_history_")
+	assert.Nil(t, err)
+	path := filepath.Join(dir, ".zsh_history")
+	err = ioutil.WriteFile(path, []byte(": 1580000000:0;kubectl get pod\n"), 0600)
+	assert.Nil(t, err)
+
+	// Act
+	result, err := ReadHistory(path)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"kubectl get pod"}, result)
+
+	// Cleanup
+	err = os.RemoveAll(dir)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,290 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Changes that can be recorded in a journal
+const (
+	JournalMerge   string = "merge"
+	JournalRemove  string = "remove"
+	JournalRun     string = "run"
+	JournalValues  string = "values"
+	JournalTimeout string = "timeout"
+	JournalStream  string = "stream"
+	JournalContext string = "context"
+	JournalReplace string = "replace"
+	// JournalSnapshot marks that the changes up to its sequence number are in a snapshot
+	JournalSnapshot string = "snapshot"
+)
+
+// JournalEntry represents a change made to a forest of command trees
+type JournalEntry struct {
+	Seq     int       `json:"seq"`
+	Op      string    `json:"op"`
+	Command string    `json:"command"`
+	Time    time.Time `json:"time"`
+	// Instance identifies the superk process that made the change
+	Instance string `json:"instance,omitempty"`
+	// Values are the values of the placeholders of the command, for JournalValues changes
+	Values map[string]string `json:"values,omitempty"`
+	// Timeout is the timeout of the command, for JournalTimeout changes
+	Timeout string `json:"timeout,omitempty"`
+	// Stream is whether the command streams its output, for JournalStream changes
+	Stream bool `json:"stream,omitempty"`
+	// Context is the kube context of the commands of a tree, for JournalContext changes
+	Context string `json:"context,omitempty"`
+	// Replacement is what replaces the text in the command field in the parts of every command, for JournalReplace changes
+	Replacement string `json:"replacement,omitempty"`
+	// ExitCode is the exit code of the command, for JournalRun changes
+	ExitCode *int `json:"exitCode,omitempty"`
+}
+
+// Journal structure represents an append-only file with the changes made to a forest since its last snapshot.
+// Several superk instances can share a journal: sequence numbers are assigned under a file lock.
+type Journal struct {
+	File     string
+	instance string
+	seen     int
+}
+
+// NewJournal creates a new journal structure
+func NewJournal(file string) *Journal {
+	return &Journal{File: file, instance: fmt.Sprintf("%d-%d", os.Getpid(), time.Now().UnixNano())}
+}
+
+// withLock runs a function while holding the lock shared by all the instances that use the journal
+func (journal *Journal) withLock(f func() error) error {
+	unlock, err := lockFile(journal.File + ".lock")
+	if err != nil {
+		return err
+	}
+	err = f()
+	if unlockErr := unlock(); err == nil {
+		err = unlockErr
+	}
+	return err
+}
+
+// Append writes a change at the end of the journal and flushes it to disk right away
+func (journal *Journal) Append(op, command string) error {
+	return journal.append(JournalEntry{Op: op, Command: command})
+}
+
+// AppendValues writes a change of the values of the placeholders of a command at the end of the journal
+func (journal *Journal) AppendValues(command string, values map[string]string) error {
+	return journal.append(JournalEntry{Op: JournalValues, Command: command, Values: values})
+}
+
+// AppendTimeout writes a change of the timeout of a command at the end of the journal
+func (journal *Journal) AppendTimeout(command string, timeout time.Duration) error {
+	return journal.append(JournalEntry{Op: JournalTimeout, Command: command, Timeout: timeout.String()})
+}
+
+// AppendStream writes a change of whether a command streams its output at the end of the journal
+func (journal *Journal) AppendStream(command string, stream bool) error {
+	return journal.append(JournalEntry{Op: JournalStream, Command: command, Stream: stream})
+}
+
+// AppendContext writes a change of the kube context of the commands of a tree at the end of the journal
+func (journal *Journal) AppendContext(root string, context string) error {
+	return journal.append(JournalEntry{Op: JournalContext, Command: root, Context: context})
+}
+
+// AppendReplace writes a replacement of some text in the parts of every command at the end of the journal
+func (journal *Journal) AppendReplace(old, new string) error {
+	return journal.append(JournalEntry{Op: JournalReplace, Command: old, Replacement: new})
+}
+
+// AppendRun writes an execution of a command and its exit code at the end of the journal
+func (journal *Journal) AppendRun(command string, exitCode int) error {
+	return journal.append(JournalEntry{Op: JournalRun, Command: command, ExitCode: &exitCode})
+}
+
+func (journal *Journal) append(entry JournalEntry) error {
+	return journal.withLock(func() error {
+		entries, err := journal.Entries()
+		if err != nil {
+			return err
+		}
+		entry.Seq, entry.Time, entry.Instance = lastSeq(entries)+1, time.Now(), journal.instance
+		bytes, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+
+		if err := os.MkdirAll(filepath.Dir(journal.File), 0700); err != nil {
+			return err
+		}
+		file, err := os.OpenFile(journal.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return err
+		}
+		_, err = file.Write(append(bytes, '\n'))
+		if err == nil {
+			err = file.Sync()
+		}
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		return err
+	})
+}
+
+// Entries returns the changes in the journal.
+// Lines that cannot be read (e.g. the last line, if the app crashed while writing it) are ignored.
+func (journal *Journal) Entries() ([]JournalEntry, error) {
+	content, err := ioutil.ReadFile(journal.File)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err == nil {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// lastSeq returns the highest sequence number in a list of changes
+func lastSeq(entries []JournalEntry) int {
+	seq := 0
+	for _, entry := range entries {
+		if entry.Seq > seq {
+			seq = entry.Seq
+		}
+	}
+	return seq
+}
+
+// Replay applies the changes in the journal that are newer than a snapshot to the forest of that snapshot
+func (journal *Journal) Replay(forest *CForest, snapshotSeq int) error {
+	entries, err := journal.Entries()
+	if err != nil {
+		return err
+	}
+	if err := replay(forest, entries, snapshotSeq); err != nil {
+		return err
+	}
+	journal.see(snapshotSeq, entries)
+	return nil
+}
+
+func replay(forest *CForest, entries []JournalEntry, snapshotSeq int) error {
+	for _, entry := range entries {
+		if entry.Seq <= snapshotSeq {
+			continue
+		}
+		if err := entry.apply(forest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// see records that the changes up to the snapshot and the entries are already in the forest of this instance
+func (journal *Journal) see(snapshotSeq int, entries []JournalEntry) {
+	journal.seen = snapshotSeq
+	if seq := lastSeq(entries); seq > journal.seen {
+		journal.seen = seq
+	}
+}
+
+// changedByOthers returns whether other instances changed the forest since this instance last saw it
+func (journal *Journal) changedByOthers(snapshotSeq int, entries []JournalEntry) bool {
+	if snapshotSeq > journal.seen {
+		return true
+	}
+	for _, entry := range entries {
+		if entry.Seq > journal.seen && entry.Instance != journal.instance {
+			return true
+		}
+	}
+	return false
+}
+
+// Reset empties the journal once its changes are in a snapshot.
+// The journal keeps a snapshot mark so sequence numbers keep growing.
+func (journal *Journal) Reset(snapshotSeq int) error {
+	entry := JournalEntry{Seq: snapshotSeq, Op: JournalSnapshot, Time: time.Now(), Instance: journal.instance}
+	bytes, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(journal.File, append(bytes, '\n'), 0600)
+}
+
+// Delete deletes the journal and its lock file
+func (journal *Journal) Delete() error {
+	for _, file := range []string{journal.File, journal.File + ".lock"} {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// apply applies a change to a forest without recording it again in a journal
+func (entry *JournalEntry) apply(forest *CForest) error {
+	switch entry.Op {
+	case JournalMerge:
+		// Commands of roots that are no longer allowed are not lost
+		if root := forest.rootOf(entry.Command); root != "" && !forest.IsRoot(root) {
+			forest.Roots = append(forest.Roots, root)
+		}
+		return forest.mergeCommand(entry.Command)
+	case JournalRemove:
+		if node := forest.find(entry.Command); node != nil {
+			forest.removeTree(node)
+		}
+	case JournalRun:
+		if node := forest.find(entry.Command); node != nil {
+			runTime := entry.Time
+			node.Meta.LastRun = &runTime
+			node.Meta.RunCount++
+			node.Meta.LastExitCode = entry.ExitCode
+		}
+	case JournalValues:
+		if node := forest.find(entry.Command); node != nil {
+			node.setValues(entry.Values)
+		}
+	case JournalTimeout:
+		if node := forest.find(entry.Command); node != nil {
+			timeout, err := time.ParseDuration(entry.Timeout)
+			if err != nil {
+				return err
+			}
+			node.Meta.Timeout = timeout
+		}
+	case JournalStream:
+		if node := forest.find(entry.Command); node != nil {
+			node.Meta.Stream = entry.Stream
+		}
+	case JournalContext:
+		if node := forest.find(entry.Command); node != nil {
+			node.Meta.Context = entry.Context
+		}
+	case JournalReplace:
+		_, err := forest.replacePart(entry.Command, entry.Replacement)
+		return err
+	case JournalSnapshot:
+	default:
+		return fmt.Errorf("Unknown journal change %q", entry.Op)
+	}
+	return nil
+}
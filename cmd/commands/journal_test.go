@@ -0,0 +1,269 @@
+package commands
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournal_Append(t *testing.T) {
+	// Arrange
+	path, err := getTmpPath("superk_test_journal_")
+	assert.Nil(t, err)
+	journal := NewJournal(path)
+
+	// Act
+	err1 := journal.Append(JournalMerge, "kubectl get pod")
+	err2 := journal.Append(JournalRemove, "kubectl get")
+
+	// Assert
+	assert.Nil(t, err1)
+	assert.Nil(t, err2)
+	entries, err := journal.Entries()
+	assert.Nil(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, 1, entries[0].Seq)
+	assert.Equal(t, JournalMerge, entries[0].Op)
+	assert.Equal(t, "kubectl get pod", entries[0].Command)
+	assert.Equal(t, 2, entries[1].Seq)
+	assert.Equal(t, JournalRemove, entries[1].Op)
+	assert.Equal(t, "kubectl get", entries[1].Command)
+
+	// Cleanup
+	err = journal.Delete()
+	assert.Nil(t, err)
+}
+
+func TestJournal_EntriesMissing(t *testing.T) {
+	// Arrange
+	journal := NewJournal("/tmp/superk_test_missing.journal")
+
+	// Act
+	entries, err := journal.Entries()
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Len(t, entries, 0)
+}
+
+func TestJournal_EntriesTruncated(t *testing.T) {
+	// Arrange
+	path, err := getTmpPath("superk_test_journal_")
+	assert.Nil(t, err)
+	content := `{"seq":1,"op":"merge","command":"kubectl get pod","time":"2020-02-20T10:30:00Z"}
+{"seq":2,"op":"merge","comm`
+	err = ioutil.WriteFile(path, []byte(content), 0600)
+	assert.Nil(t, err)
+	journal := NewJournal(path)
+
+	// Act
+	entries, err := journal.Entries()
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "kubectl get pod", entries[0].Command)
+
+	// Cleanup
+	err = journal.Delete()
+	assert.Nil(t, err)
+}
+
+func TestJournal_Replay(t *testing.T) {
+	// Arrange
+	path, err := getTmpPath("superk_test_journal_")
+	assert.Nil(t, err)
+	journal := NewJournal(path)
+	for _, entry := range []struct{ op, command string }{
+		{JournalMerge, "kubectl -n kubeflow get pod"},
+		{JournalMerge, "kubectl -n kubeflow get cronjob"},
+		{JournalMerge, "helm list"},
+		{JournalRun, "kubectl -n kubeflow get pod"},
+		{JournalRemove, "kubectl -n kubeflow get cronjob"},
+	} {
+		err = journal.Append(entry.op, entry.command)
+		assert.Nil(t, err)
+	}
+	err = journal.AppendValues("kubectl -n kubeflow get pod", map[string]string{"app": "web"})
+	assert.Nil(t, err)
+	err = journal.AppendTimeout("kubectl -n kubeflow get pod", time.Minute)
+	assert.Nil(t, err)
+	err = journal.AppendRun("kubectl -n kubeflow get pod", 2)
+	assert.Nil(t, err)
+	err = journal.AppendStream("kubectl -n kubeflow get pod", true)
+	assert.Nil(t, err)
+	err = journal.AppendContext("kubectl", "aks-prod")
+	assert.Nil(t, err)
+
+	forest, err := NewCForest([]string{"kubectl"}, []string{"kubectl -n pipelines get pod"})
+	assert.Nil(t, err)
+
+	// Act
+	err = NewJournal(path).Replay(forest, 0)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{
+		"kubectl -n pipelines get pod",
+		"kubectl -n kubeflow get pod",
+		"helm list",
+	}, forest.Serialize())
+	node := forest.find("kubectl -n kubeflow get pod")
+	assert.Equal(t, 2, node.Meta.RunCount)
+	assert.NotNil(t, node.Meta.LastRun)
+	assert.Equal(t, 2, *node.Meta.LastExitCode)
+	assert.EqualValues(t, map[string]string{"app": "web"}, node.Meta.Values)
+	assert.Equal(t, time.Minute, node.Meta.Timeout)
+	assert.True(t, node.Meta.Stream)
+	assert.Equal(t, "aks-prod", forest.Trees[0].Meta.Context)
+
+	// Cleanup
+	err = journal.Delete()
+	assert.Nil(t, err)
+}
+
+func TestJournal_ReplayCloneAndReplace(t *testing.T) {
+	// Arrange
+	path, err := getTmpPath("superk_test_journal_")
+	assert.Nil(t, err)
+	forest, err := NewCForest([]string{"kubectl"}, nil)
+	assert.Nil(t, err)
+	forest.journal = NewJournal(path)
+	err = forest.MergeCommand("kubectl -n team-a logs {{pod}}")
+	assert.Nil(t, err)
+	err = forest.SetValues(4, map[string]string{"pod": "web"})
+	assert.Nil(t, err)
+	_, err = forest.CloneCommand(2, "team-a", "team-b")
+	assert.Nil(t, err)
+	_, err = forest.ReplacePart("team-a", "team-c")
+	assert.Nil(t, err)
+
+	replayed, err := NewCForest([]string{"kubectl"}, nil)
+	assert.Nil(t, err)
+
+	// Act
+	err = NewJournal(path).Replay(replayed, 0)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{
+		"kubectl -n team-c logs {{pod}}",
+		"kubectl -n team-b logs {{pod}}",
+	}, replayed.Serialize())
+	assert.EqualValues(t, map[string]string{"pod": "web"}, replayed.find("kubectl -n team-b logs {{pod}}").Meta.Values)
+
+	// Cleanup
+	err = forest.journal.Delete()
+	assert.Nil(t, err)
+}
+
+func TestJournal_ReplayAfterSnapshot(t *testing.T) {
+	// Arrange
+	path, err := getTmpPath("superk_test_journal_")
+	assert.Nil(t, err)
+	journal := NewJournal(path)
+	err = journal.Append(JournalMerge, "kubectl get pod")
+	assert.Nil(t, err)
+	err = journal.Append(JournalMerge, "kubectl get cronjob")
+	assert.Nil(t, err)
+
+	forest, err := NewCForest(DefaultRoots, nil)
+	assert.Nil(t, err)
+	replayed := NewJournal(path)
+
+	// Act
+	err = replayed.Replay(forest, 1)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"kubectl get cronjob"}, forest.Serialize())
+	assert.Equal(t, 2, replayed.seen)
+
+	// Cleanup
+	err = journal.Delete()
+	assert.Nil(t, err)
+}
+
+func TestJournal_Reset(t *testing.T) {
+	// Arrange
+	path, err := getTmpPath("superk_test_journal_")
+	assert.Nil(t, err)
+	journal := NewJournal(path)
+	err = journal.Append(JournalMerge, "kubectl get pod")
+	assert.Nil(t, err)
+
+	// Act
+	err = journal.Reset(1)
+
+	// Assert
+	assert.Nil(t, err)
+	err = journal.Append(JournalMerge, "kubectl get cronjob")
+	assert.Nil(t, err)
+	entries, err := journal.Entries()
+	assert.Nil(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, JournalSnapshot, entries[0].Op)
+	assert.Equal(t, 1, entries[0].Seq)
+	assert.Equal(t, 2, entries[1].Seq)
+
+	// Cleanup
+	err = journal.Delete()
+	assert.Nil(t, err)
+}
+
+func TestJournal_AppendSharedSeq(t *testing.T) {
+	// Arrange
+	path, err := getTmpPath("superk_test_journal_")
+	assert.Nil(t, err)
+	first := NewJournal(path)
+	second := NewJournal(path)
+
+	// Act
+	err1 := first.Append(JournalMerge, "kubectl get pod")
+	err2 := second.Append(JournalMerge, "kubectl get cronjob")
+
+	// Assert
+	assert.Nil(t, err1)
+	assert.Nil(t, err2)
+	entries, err := first.Entries()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, entries[0].Seq)
+	assert.Equal(t, 2, entries[1].Seq)
+	assert.NotEqual(t, entries[0].Instance, entries[1].Instance)
+
+	// Cleanup
+	err = first.Delete()
+	assert.Nil(t, err)
+}
+
+func TestJournal_ChangedByOthers(t *testing.T) {
+	// Arrange
+	path, err := getTmpPath("superk_test_journal_")
+	assert.Nil(t, err)
+	first := NewJournal(path)
+	second := NewJournal(path)
+	err = first.Append(JournalMerge, "kubectl get pod")
+	assert.Nil(t, err)
+	own, err := first.Entries()
+	assert.Nil(t, err)
+	err = second.Append(JournalMerge, "kubectl get cronjob")
+	assert.Nil(t, err)
+	all, err := first.Entries()
+	assert.Nil(t, err)
+
+	// Act
+	ownChanges := first.changedByOthers(0, own)
+	otherChanges := first.changedByOthers(0, all)
+	newerSnapshot := first.changedByOthers(5, own)
+
+	// Assert
+	assert.False(t, ownChanges)
+	assert.True(t, otherChanges)
+	assert.True(t, newerSnapshot)
+
+	// Cleanup
+	err = first.Delete()
+	assert.Nil(t, err)
+}
@@ -0,0 +1,231 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// defaultNamespace is the namespace of the contexts that do not set one
+const defaultNamespace = "default"
+
+// Flags that select the kube context of the commands of each binary
+var contextFlags = map[string]string{
+	"kubectl": "--context",
+	"oc":      "--context",
+	"helm":    "--kube-context",
+}
+
+// Kubeconfig represents the kubeconfig files kubectl reads, merged the way kubectl merges them:
+// the first file that sets the current context, or that has a context, wins
+type Kubeconfig struct {
+	CurrentContext string
+	Contexts       map[string]KubeContextInfo
+}
+
+// KubeContextInfo represents a context of a kubeconfig file
+type KubeContextInfo struct {
+	Cluster   string
+	User      string
+	Namespace string
+}
+
+// kubeconfigFile is the part of a kubeconfig file the app reads
+type kubeconfigFile struct {
+	CurrentContext string `yaml:"current-context"`
+	Contexts       []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster   string `yaml:"cluster"`
+			User      string `yaml:"user"`
+			Namespace string `yaml:"namespace"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+}
+
+// kubeconfigCache keeps the kubeconfig files that were read, until they change
+var kubeconfigCache = struct {
+	sync.Mutex
+	files map[string]cachedKubeconfig
+}{files: map[string]cachedKubeconfig{}}
+
+type cachedKubeconfig struct {
+	modTime time.Time
+	size    int64
+	file    *kubeconfigFile
+}
+
+// LoadKubeconfig reads the kubeconfig files kubectl reads: the ones in $KUBECONFIG, or else ~/.kube/config.
+// Files that do not exist are skipped.
+func LoadKubeconfig() (*Kubeconfig, error) {
+	return loadKubeconfig(kubeconfigPaths(""))
+}
+
+func loadKubeconfig(paths []string) (*Kubeconfig, error) {
+	kubeconfig := Kubeconfig{Contexts: map[string]KubeContextInfo{}}
+	for _, path := range paths {
+		file, err := readKubeconfigFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if file == nil {
+			continue
+		}
+		if kubeconfig.CurrentContext == "" {
+			kubeconfig.CurrentContext = file.CurrentContext
+		}
+		for _, context := range file.Contexts {
+			if _, ok := kubeconfig.Contexts[context.Name]; !ok {
+				kubeconfig.Contexts[context.Name] = KubeContextInfo{
+					Cluster:   context.Context.Cluster,
+					User:      context.Context.User,
+					Namespace: context.Context.Namespace,
+				}
+			}
+		}
+	}
+	return &kubeconfig, nil
+}
+
+// readKubeconfigFile parses a kubeconfig file, or returns nothing if it does not exist
+func readKubeconfigFile(path string) (*kubeconfigFile, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	kubeconfigCache.Lock()
+	defer kubeconfigCache.Unlock()
+	if cached, ok := kubeconfigCache.files[path]; ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.file, nil
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file kubeconfigFile
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return nil, err
+	}
+	kubeconfigCache.files[path] = cachedKubeconfig{modTime: info.ModTime(), size: info.Size(), file: &file}
+	return &file, nil
+}
+
+// ContextNames returns the names of the contexts, sorted
+func (kubeconfig *Kubeconfig) ContextNames() []string {
+	names := make([]string, 0, len(kubeconfig.Contexts))
+	for name := range kubeconfig.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Namespace returns the namespace commands run in by default in a context
+func (kubeconfig *Kubeconfig) Namespace(context string) string {
+	if namespace := kubeconfig.Contexts[context].Namespace; namespace != "" {
+		return namespace
+	}
+	return defaultNamespace
+}
+
+// KubeContext returns the kube context a command runs against: the one in its flags,
+// or else the current context of its kubeconfig files. It is empty if there is none.
+func KubeContext(args []string) string {
+	if context, ok := lookupFlag(args, "--context", "--kube-context"); ok {
+		return context
+	}
+
+	kubeconfig, err := commandKubeconfig(args)
+	if err != nil {
+		return ""
+	}
+	return kubeconfig.CurrentContext
+}
+
+// KubeNamespace returns the namespace a command runs in: the one in its flags,
+// or else the namespace of its kube context
+func KubeNamespace(args []string) string {
+	if namespace, ok := lookupFlag(args, "-n", "--namespace"); ok {
+		return namespace
+	}
+
+	kubeconfig, err := commandKubeconfig(args)
+	if err != nil {
+		return defaultNamespace
+	}
+	return kubeconfig.Namespace(KubeContext(args))
+}
+
+// commandKubeconfig reads the kubeconfig files of a command: the one in its flags, or else the default ones
+func commandKubeconfig(args []string) (*Kubeconfig, error) {
+	path, _ := lookupFlag(args, "--kubeconfig")
+	return loadKubeconfig(kubeconfigPaths(path))
+}
+
+// TakesKubeContext returns whether the commands of a binary can run against a kube context other than the current one
+func TakesKubeContext(root string) bool {
+	_, ok := contextFlags[root]
+	return ok
+}
+
+// withKubeContext returns the arguments of a command with the flag that makes it run against a kube context,
+// unless the command already has a context of its own or its binary does not take one
+func withKubeContext(args []string, context string) []string {
+	if len(args) == 0 {
+		return args
+	}
+	flag, ok := contextFlags[args[0]]
+	if context == "" || !ok {
+		return args
+	}
+	if _, ok := lookupFlag(args, flag); ok {
+		return args
+	}
+	return append([]string{args[0], flag, context}, args[1:]...)
+}
+
+// kubeconfigPaths returns the kubeconfig files kubectl reads, in order: the one in the flags,
+// or else the ones in $KUBECONFIG, or else ~/.kube/config
+func kubeconfigPaths(kubeconfig string) []string {
+	if kubeconfig != "" {
+		return []string{kubeconfig}
+	}
+	if env := os.Getenv("KUBECONFIG"); env != "" {
+		return filepath.SplitList(env)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	return []string{filepath.Join(home, ".kube", "config")}
+}
+
+// lookupFlag returns the value of the first of some flags in the arguments of a command, either as --flag=value or --flag value.
+// Arguments after "--" are not flags of the command.
+func lookupFlag(args []string, flags ...string) (string, bool) {
+	for index, arg := range args {
+		if arg == "--" {
+			break
+		}
+		for _, flag := range flags {
+			if strings.HasPrefix(arg, flag+"=") {
+				return arg[len(flag)+1:], true
+			}
+			if arg == flag && index+1 < len(args) {
+				return args[index+1], true
+			}
+		}
+	}
+	return "", false
+}
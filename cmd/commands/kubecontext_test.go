@@ -0,0 +1,180 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKubeContext(t *testing.T) {
+	// Arrange
+	empty, err := getTmpPath("superk_test_kubeconfig_")
+	assert.Nil(t, err)
+	kubeconfig, err := writeTmpStore("apiVersion: v1\nclusters: []\ncurrent-context: \"kind-superk\"\nkind: Config\n")
+	assert.Nil(t, err)
+	previous, set := os.LookupEnv("KUBECONFIG")
+	os.Setenv("KUBECONFIG", strings.Join([]string{empty, kubeconfig}, string(filepath.ListSeparator)))
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"kubectl", "get", "pod"}, "kind-superk"},
+		{[]string{"kubectl", "--context", "aks-prod", "delete", "pod", "nginx"}, "aks-prod"},
+		{[]string{"kubectl", "delete", "pod", "nginx", "--context=aks-dev"}, "aks-dev"},
+		{[]string{"helm", "uninstall", "nginx", "--kube-context", "aks-prod"}, "aks-prod"},
+		{[]string{"kubectl", "--kubeconfig", empty, "get", "pod"}, ""},
+		{[]string{"kubectl", "exec", "nginx", "--", "sh", "--context", "other"}, "kind-superk"},
+	}
+
+	for _, test := range tests {
+		t.Run(strings.Join(test.args, " "), func(t *testing.T) {
+			// Act
+			result := KubeContext(test.args)
+
+			// Assert
+			assert.Equal(t, test.expected, result)
+		})
+	}
+
+	// Cleanup
+	if set {
+		os.Setenv("KUBECONFIG", previous)
+	} else {
+		os.Unsetenv("KUBECONFIG")
+	}
+	os.Remove(empty)
+	os.Remove(kubeconfig)
+}
+
+func TestLoadKubeconfig(t *testing.T) {
+	// Arrange
+	first, err := writeTmpStore(`apiVersion: v1
+kind: Config
+contexts:
+- name: aks-prod
+  context:
+    cluster: prod
+    user: admin
+    namespace: kubeflow
+`)
+	assert.Nil(t, err)
+	second, err := writeTmpStore(`apiVersion: v1
+kind: Config
+current-context: kind-superk
+contexts:
+- name: kind-superk
+  context:
+    cluster: kind-superk
+    user: kind-superk
+- name: aks-prod
+  context:
+    cluster: other
+    namespace: other
+`)
+	assert.Nil(t, err)
+	missing, err := getTmpPath("superk_test_kubeconfig_")
+	assert.Nil(t, err)
+	os.Remove(missing)
+
+	// Act
+	kubeconfig, err := loadKubeconfig([]string{missing, first, second})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "kind-superk", kubeconfig.CurrentContext)
+	assert.EqualValues(t, []string{"aks-prod", "kind-superk"}, kubeconfig.ContextNames())
+	assert.Equal(t, KubeContextInfo{Cluster: "prod", User: "admin", Namespace: "kubeflow"}, kubeconfig.Contexts["aks-prod"])
+	assert.Equal(t, "kubeflow", kubeconfig.Namespace("aks-prod"))
+	assert.Equal(t, "default", kubeconfig.Namespace("kind-superk"))
+	assert.Equal(t, "default", kubeconfig.Namespace("unknown"))
+
+	// Cleanup
+	os.Remove(first)
+	os.Remove(second)
+}
+
+func TestLoadKubeconfig_Invalid(t *testing.T) {
+	// Arrange
+	path, err := writeTmpStore("contexts: {")
+	assert.Nil(t, err)
+
+	// Act
+	_, err = loadKubeconfig([]string{path})
+
+	// Assert
+	assert.NotNil(t, err)
+
+	// Cleanup
+	os.Remove(path)
+}
+
+func TestKubeNamespace(t *testing.T) {
+	// Arrange
+	kubeconfig, err := writeTmpStore(`current-context: kind-superk
+contexts:
+- name: kind-superk
+  context:
+    namespace: kubeflow
+- name: aks-prod
+  context: {}
+`)
+	assert.Nil(t, err)
+	previous, set := os.LookupEnv("KUBECONFIG")
+	os.Setenv("KUBECONFIG", kubeconfig)
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"kubectl", "get", "pod"}, "kubeflow"},
+		{[]string{"kubectl", "-n", "kube-system", "get", "pod"}, "kube-system"},
+		{[]string{"kubectl", "get", "pod", "--namespace=monitoring"}, "monitoring"},
+		{[]string{"kubectl", "--context", "aks-prod", "get", "pod"}, "default"},
+	}
+
+	for _, test := range tests {
+		t.Run(strings.Join(test.args, " "), func(t *testing.T) {
+			// Act
+			result := KubeNamespace(test.args)
+
+			// Assert
+			assert.Equal(t, test.expected, result)
+		})
+	}
+
+	// Cleanup
+	if set {
+		os.Setenv("KUBECONFIG", previous)
+	} else {
+		os.Unsetenv("KUBECONFIG")
+	}
+	os.Remove(kubeconfig)
+}
+
+func TestWithKubeContext(t *testing.T) {
+	tests := []struct {
+		args     []string
+		context  string
+		expected []string
+	}{
+		{[]string{"kubectl", "get", "pod"}, "aks-prod", []string{"kubectl", "--context", "aks-prod", "get", "pod"}},
+		{[]string{"helm", "list"}, "aks-prod", []string{"helm", "--kube-context", "aks-prod", "list"}},
+		{[]string{"kubectl", "get", "pod"}, "", []string{"kubectl", "get", "pod"}},
+		{[]string{"kubectl", "get", "pod", "--context=aks-dev"}, "aks-prod", []string{"kubectl", "get", "pod", "--context=aks-dev"}},
+		{[]string{"docker", "ps"}, "aks-prod", []string{"docker", "ps"}},
+	}
+
+	for _, test := range tests {
+		t.Run(strings.Join(test.args, " "), func(t *testing.T) {
+			// Act
+			result := withKubeContext(test.args, test.context)
+
+			// Assert
+			assert.EqualValues(t, test.expected, result)
+		})
+	}
+}
@@ -0,0 +1,37 @@
+//go:build !windows
+// +build !windows
+
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// lockFile takes an exclusive advisory lock on a file, waiting until other processes release it.
+// It returns the function that releases the lock.
+func lockFile(path string) (func() error, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		if closeErr := file.Close(); closeErr != nil {
+			return nil, closeErr
+		}
+		return nil, err
+	}
+
+	return func() error {
+		err := syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		return err
+	}, nil
+}
@@ -0,0 +1,10 @@
+//go:build windows
+// +build windows
+
+package commands
+
+// lockFile does nothing on Windows, where superk is not supported yet.
+// It returns the function that would release the lock.
+func lockFile(path string) (func() error, error) {
+	return func() error { return nil }, nil
+}
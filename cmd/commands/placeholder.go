@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Placeholders in commands are written as {{name}} (e.g. kubectl -n {{namespace}} logs {{pod}}).
+// Names are valid shell variable names, so exported commands can use them as variables.
+// A placeholder may declare the command whose output lists its potential values,
+// e.g. {{pod from "kubectl -n {{namespace}} get pod -o name"}}
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)(?:\s+from\s+"((?:[^"\\]|\\.)*)")?\s*\}\}`)
+
+// Placeholders returns the names of the placeholders in a command, in order and without duplicates.
+// Placeholders in the source of another placeholder come before it, as they need a value first.
+func Placeholders(command string) []string {
+	var names []string
+	for _, match := range placeholderPattern.FindAllStringSubmatch(command, -1) {
+		for _, name := range append(Placeholders(unescapeSource(match[2])), match[1]) {
+			if !isOneOf(name, names) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// Sources returns the commands that list the potential values of the placeholders in a command, by name
+func Sources(command string) map[string]string {
+	sources := map[string]string{}
+	for _, match := range placeholderPattern.FindAllStringSubmatch(command, -1) {
+		if source := unescapeSource(match[2]); source != "" {
+			for name, nested := range Sources(source) {
+				sources[name] = nested
+			}
+			if _, ok := sources[match[1]]; !ok {
+				sources[match[1]] = source
+			}
+		}
+	}
+	return sources
+}
+
+func unescapeSource(source string) string {
+	return strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(source)
+}
+
+// SourceValues returns the values listed in the output of the source of a placeholder, one per line.
+// When the output is a table (e.g. kubectl get pod), values are in its first column and the header is skipped.
+func SourceValues(output string) []string {
+	var values []string
+	for index, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || index == 0 && fields[0] == "NAME" {
+			continue
+		}
+		if !isOneOf(fields[0], values) {
+			values = append(values, fields[0])
+		}
+	}
+	return values
+}
+
+// stripSources removes the sources of the placeholders in a command, leaving only their names
+// Example: {{pod from "kubectl get pod -o name"}} becomes {{pod}}
+func stripSources(command string) string {
+	return placeholderPattern.ReplaceAllString(command, "{{$1}}")
+}
+
+// Expand replaces the placeholders in a string with their values.
+// Placeholders without a value are left as they are.
+func Expand(value string, values map[string]string) string {
+	return placeholderPattern.ReplaceAllStringFunc(value, func(placeholder string) string {
+		name := placeholderPattern.FindStringSubmatch(placeholder)[1]
+		if value, ok := values[name]; ok {
+			return value
+		}
+		return placeholder
+	})
+}
+
+// shellTemplate replaces the placeholders in a command with shell expansions (e.g. ${namespace}),
+// quoting them so the shell expands them into a single argument wherever they are in the command
+// Example: "kubectl get pod -l 'app={{app}}'" becomes "kubectl get pod -l 'app='"${app}"''"
+func shellTemplate(command string, expansion func(name string) string) string {
+	var builder strings.Builder
+	var quote rune
+	escaped := false
+	for index := 0; index < len(command); {
+		if !escaped {
+			if match := placeholderPattern.FindStringSubmatchIndex(command[index:]); match != nil && match[0] == 0 {
+				value := "${" + expansion(command[index+match[2]:index+match[3]]) + "}"
+				switch quote {
+				case '"':
+					builder.WriteString(value)
+				case '\'':
+					fmt.Fprintf(&builder, `'"%s"'`, value)
+				default:
+					fmt.Fprintf(&builder, `"%s"`, value)
+				}
+				index += match[1]
+				continue
+			}
+		}
+
+		char := rune(command[index])
+		switch {
+		case escaped:
+			escaped = false
+		case char == '\\' && quote != '\'':
+			escaped = true
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			}
+		case char == '\'' || char == '"':
+			quote = char
+		}
+		builder.WriteByte(command[index])
+		index++
+	}
+	return builder.String()
+}
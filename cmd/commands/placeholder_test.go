@@ -0,0 +1,126 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlaceholder_Placeholders(t *testing.T) {
+	// Arrange
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"None", "kubectl get pod", nil},
+		{"Some", "kubectl -n {{namespace}} logs {{pod}}", []string{"namespace", "pod"}},
+		{"Duplicate", "kubectl -n {{ns}} get pod -l 'ns={{ns}}'", []string{"ns"}},
+		{"Spaces", "kubectl logs {{ pod }}", []string{"pod"}},
+		{"Invalid name", "kubectl logs {{my pod}}", nil},
+		{"Source", `kubectl logs {{pod from "kubectl -n {{ns}} get pod -o name"}} -n {{ns}}`, []string{"ns", "pod"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			result := Placeholders(test.input)
+
+			// Assert
+			assert.EqualValues(t, test.expected, result)
+		})
+	}
+}
+
+func TestPlaceholder_Expand(t *testing.T) {
+	// Arrange
+	values := map[string]string{"namespace": "kubeflow", "app": "web server"}
+
+	// Act
+	result := Expand("-n {{namespace}} -l app={{ app }} {{pod}}", values)
+
+	// Assert
+	assert.Equal(t, "-n kubeflow -l app=web server {{pod}}", result)
+}
+
+func TestPlaceholder_Sources(t *testing.T) {
+	// Arrange
+	command := `kubectl -n {{ns from "kubectl get ns -o name"}} logs {{pod from "kubectl -n {{ns}} get pod -l \"app=a\""}} {{container}}`
+	expected := map[string]string{"ns": "kubectl get ns -o name", "pod": `kubectl -n {{ns}} get pod -l "app=a"`}
+
+	// Act
+	result := Sources(command)
+
+	// Assert
+	assert.EqualValues(t, expected, result)
+}
+
+func TestPlaceholder_ExpandSource(t *testing.T) {
+	// Arrange
+	values := map[string]string{"pod": "pod/web"}
+
+	// Act
+	result := Expand(`logs {{pod from "kubectl get pod -o name"}}`, values)
+
+	// Assert
+	assert.Equal(t, "logs pod/web", result)
+}
+
+func TestPlaceholder_SourceValues(t *testing.T) {
+	// Arrange
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"Names", "pod/web\npod/db\n\n", []string{"pod/web", "pod/db"}},
+		{"Table", "NAME   READY   STATUS\nweb    1/1     Running\ndb     0/1     Pending\n", []string{"web", "db"}},
+		{"Duplicates", "a\nb\na\n", []string{"a", "b"}},
+		{"Empty", "", nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			result := SourceValues(test.input)
+
+			// Assert
+			assert.EqualValues(t, test.expected, result)
+		})
+	}
+}
+
+func TestPlaceholder_StripSources(t *testing.T) {
+	// Arrange
+
+	// Act
+	result := stripSources(`kubectl logs {{pod from "kubectl get pod -o name"}} -c {{container}}`)
+
+	// Assert
+	assert.Equal(t, "kubectl logs {{pod}} -c {{container}}", result)
+}
+
+func TestPlaceholder_ShellTemplate(t *testing.T) {
+	// Arrange
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Unquoted", "kubectl logs {{pod}}", `kubectl logs "${pod}"`},
+		{"Single quoted", "kubectl get pod -l 'app={{app}}'", `kubectl get pod -l 'app='"${app}"''`},
+		{"Double quoted", `kubectl get pod -l "app={{app}}"`, `kubectl get pod -l "app=${app}"`},
+		{"Escaped", `kubectl logs \{{pod}}`, `kubectl logs \{{pod}}`},
+		{"Source", `kubectl logs {{pod from "kubectl get pod"}} -c '{{c}}'`, `kubectl logs "${pod}" -c ''"${c}"''`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			result := shellTemplate(test.input, func(name string) string { return name })
+
+			// Assert
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"regexp"
+	"time"
+)
+
+// maxRetryBackoff is the longest wait between two attempts of a command, however many attempts failed before
+const maxRetryBackoff = time.Minute
+
+// Policy represents how commands run: how long they may take, and whether they run again when they fail
+type Policy struct {
+	// Timeout is how long each attempt of a command may take. 0 means there is no limit.
+	Timeout time.Duration
+	Retry   RetryPolicy
+}
+
+// RetryPolicy represents when and how commands that fail run again
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times a command runs. Commands don't run again if it is 0 or 1.
+	MaxAttempts int
+	// Backoff is how long to wait before the second attempt. The wait doubles after every attempt.
+	Backoff time.Duration
+	// Patterns match the standard error of the failures that are worth retrying (e.g. "connection refused")
+	Patterns []*regexp.Regexp
+	// ExitCodes are the exit codes of the failures that are worth retrying.
+	// With no patterns and no exit codes, every failure is worth retrying.
+	ExitCodes []int
+}
+
+// NewRetryPolicy creates a retry policy, compiling its patterns
+func NewRetryPolicy(maxAttempts int, backoff time.Duration, patterns []string, exitCodes []int) (RetryPolicy, error) {
+	retry := RetryPolicy{MaxAttempts: maxAttempts, Backoff: backoff, ExitCodes: exitCodes}
+	for _, pattern := range patterns {
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return RetryPolicy{}, err
+		}
+		retry.Patterns = append(retry.Patterns, compiled)
+	}
+	return retry, nil
+}
+
+// attempts returns how many times a command may run
+func (retry *RetryPolicy) attempts() int {
+	if retry.MaxAttempts < 1 {
+		return 1
+	}
+	return retry.MaxAttempts
+}
+
+// retries returns whether a failed attempt is worth retrying
+func (retry *RetryPolicy) retries(attempt *CmdAttempt) bool {
+	if attempt.ExitCode == 0 && !attempt.TimedOut {
+		return false
+	}
+	if len(retry.Patterns) == 0 && len(retry.ExitCodes) == 0 {
+		return true
+	}
+	for _, exitCode := range retry.ExitCodes {
+		if attempt.ExitCode == exitCode {
+			return true
+		}
+	}
+	for _, pattern := range retry.Patterns {
+		if pattern.MatchString(attempt.Stderr) {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns how long to wait after a failed attempt (1 for the first one) before the next one
+func (retry *RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := retry.Backoff
+	for index := 1; index < attempt && backoff < maxRetryBackoff; index++ {
+		backoff *= 2
+	}
+	if backoff > maxRetryBackoff {
+		return maxRetryBackoff
+	}
+	return backoff
+}
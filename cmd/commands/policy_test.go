@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy_NewRetryPolicy(t *testing.T) {
+	// Arrange
+
+	// Act
+	retry, err := NewRetryPolicy(3, time.Second, []string{"connection refused", "TLS handshake timeout"}, []int{1})
+	_, invalidErr := NewRetryPolicy(3, time.Second, []string{"(unclosed"}, nil)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Len(t, retry.Patterns, 2)
+	assert.NotNil(t, invalidErr)
+}
+
+func TestPolicy_Retries(t *testing.T) {
+	// Arrange
+	filtered, err := NewRetryPolicy(3, time.Second, []string{"connection refused"}, []int{2})
+	assert.Nil(t, err)
+	unfiltered := RetryPolicy{MaxAttempts: 3}
+
+	tests := []struct {
+		name     string
+		retry    RetryPolicy
+		attempt  CmdAttempt
+		expected bool
+	}{
+		{"Success", unfiltered, CmdAttempt{ExitCode: 0}, false},
+		{"Any failure", unfiltered, CmdAttempt{ExitCode: 1}, true},
+		{"Timeout", unfiltered, CmdAttempt{ExitCode: -1, TimedOut: true}, true},
+		{"Matching pattern", filtered, CmdAttempt{ExitCode: 1, Stderr: "dial tcp: connection refused"}, true},
+		{"Matching exit code", filtered, CmdAttempt{ExitCode: 2, Stderr: "error"}, true},
+		{"No match", filtered, CmdAttempt{ExitCode: 1, Stderr: "pods \"web\" not found"}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			result := test.retry.retries(&test.attempt)
+
+			// Assert
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func TestPolicy_Backoff(t *testing.T) {
+	// Arrange
+	retry := RetryPolicy{MaxAttempts: 10, Backoff: time.Second}
+
+	// Act
+	first := retry.backoff(1)
+	third := retry.backoff(3)
+	last := retry.backoff(9)
+
+	// Assert
+	assert.Equal(t, time.Second, first)
+	assert.Equal(t, 4*time.Second, third)
+	assert.Equal(t, maxRetryBackoff, last)
+	assert.Equal(t, 1, (&RetryPolicy{}).attempts())
+}
@@ -0,0 +1,257 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Change represents a command of a tree before and after it is rewritten
+type Change struct {
+	Before string
+	After  string
+	node   *CTree
+}
+
+// Clone copies the command at a certain position, and all its children commands, with a value replaced
+// in their arguments (e.g. the namespace team-a by team-b, see rewriteArg), and merges the copies into the tree
+// with the settings of the commands they copy. It returns the nodes of the copies.
+func (tree *CTree) Clone(position int, old, new string) ([]*CTree, error) {
+	node := tree.getTree(&position)
+	if node == nil {
+		return nil, errors.New("Command not found")
+	}
+	changes, err := node.cloneChanges(old, new)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, change := range changes {
+		if len(change.node.Children) == 0 {
+			if err := tree.MergeCommand(change.After); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var copies []*CTree
+	for _, change := range changes {
+		position := tree.GetPosition(change.After)
+		if position == nil {
+			continue
+		}
+		copied := tree.getTree(position)
+		values := map[string]string{}
+		for name, value := range change.node.Meta.Values {
+			values[name] = rewriteArg(value, old, new)
+		}
+		copied.Meta.merge(CMeta{
+			Notes:   change.node.Meta.Notes,
+			Pinned:  change.node.Meta.Pinned,
+			Values:  values,
+			Timeout: change.node.Meta.Timeout,
+			Stream:  change.node.Meta.Stream,
+		})
+		copied.Cmd = nil
+		copies = append(copies, copied)
+	}
+	return copies, nil
+}
+
+// cloneChanges returns the commands of this node and its children that change when a value is replaced
+// in their arguments, in depth-first order
+func (tree *CTree) cloneChanges(old, new string) ([]Change, error) {
+	if old == "" || old == new {
+		return nil, errors.New("The value to replace and its replacement must be different")
+	}
+
+	var changes []Change
+	tree.walk(func(node *CTree) {
+		if after := node.rewrite(old, new); after != node.toCommand() {
+			changes = append(changes, Change{Before: node.toCommand(), After: after, node: node})
+		}
+	})
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("%s is not in %s", old, tree.toCommand())
+	}
+	return changes, nil
+}
+
+// rewrite returns the command of this node with a value replaced in its arguments. Parts that do not change
+// are kept exactly as they were typed.
+func (tree *CTree) rewrite(old, new string) string {
+	var parts []string
+	var current *CTree
+	for current = tree; current.Parent != nil; current = current.Parent {
+		part := current.Part
+		args := partArgs(part)
+		changed := false
+		for index, arg := range args {
+			if rewritten := rewriteArg(arg, old, new); rewritten != arg {
+				args[index], changed = quote(rewritten), true
+			} else {
+				args[index] = quote(arg)
+			}
+		}
+		if changed {
+			part = strings.Join(args, " ")
+		}
+		parts = append([]string{part}, parts...)
+	}
+	return strings.Join(append([]string{current.Part}, parts...), " ")
+}
+
+// rewriteArg replaces a value in an argument of a command: the whole argument (e.g. a namespace or a resource name),
+// the value of a key or flag in it (e.g. team-a in app=web,team=team-a or in --namespace=team-a),
+// or the name of a resource after its type (e.g. web in deployment/web)
+func rewriteArg(arg, old, new string) string {
+	switch {
+	case arg == old:
+		return new
+	case strings.Contains(arg, ","):
+		items := strings.Split(arg, ",")
+		for index, item := range items {
+			items[index] = rewriteArg(item, old, new)
+		}
+		return strings.Join(items, ",")
+	case strings.Contains(arg, "="):
+		index := strings.Index(arg, "=")
+		return arg[:index+1] + rewriteArg(arg[index+1:], old, new)
+	case strings.Contains(arg, "/"):
+		index := strings.LastIndex(arg, "/")
+		return arg[:index+1] + rewriteArg(arg[index+1:], old, new)
+	default:
+		return arg
+	}
+}
+
+// ReplacePart replaces some text in the parts of every command of the tree but its root.
+// Commands that end up the same are merged, with their children commands and their metadata.
+// It returns how many parts changed.
+func (tree *CTree) ReplacePart(old, new string) (int, error) {
+	parts, err := tree.replacements(old, new)
+	if err != nil {
+		return 0, err
+	}
+	tree.replaceParts(parts)
+	return len(parts), nil
+}
+
+// replacements returns the new parts of the commands of the tree that contain some text, by node
+func (tree *CTree) replacements(old, new string) (map[*CTree]string, error) {
+	if old == "" || old == new {
+		return nil, errors.New("The text to replace and its replacement must be different")
+	}
+
+	parts := map[*CTree]string{}
+	var err error
+	for _, child := range tree.Children {
+		child.walk(func(node *CTree) {
+			if err != nil || !strings.Contains(node.Part, old) {
+				return
+			}
+			part := strings.Replace(node.Part, old, new, -1)
+			if split, splitErr := split(part); splitErr != nil || len(split) != 1 || split[0] != part {
+				err = fmt.Errorf("%q would not be a single part of a command", part)
+				return
+			}
+			parts[node] = part
+		})
+	}
+	if err != nil || len(parts) == 0 {
+		return parts, err
+	}
+
+	// Parts are kept in their canonical form (e.g. deployment for deploy), so commands merge into them
+	for _, change := range tree.previewReplace(parts) {
+		var nodes []*CTree
+		for current := change.node; current != nil; current = current.Parent {
+			nodes = append([]*CTree{current}, nodes...)
+		}
+		path := change.node.replacedPath(parts)
+		canonical := canonicalize(append([]string{}, path...))
+		for index, node := range nodes {
+			if canonical[index] == path[index] {
+				continue
+			}
+			if _, ok := parts[node]; !ok || flagName(canonical[index]) != flagName(path[index]) {
+				return nil, fmt.Errorf("%q would move to another place in %s", path[index], strings.Join(canonical, " "))
+			}
+			parts[node] = canonical[index]
+		}
+	}
+	return parts, nil
+}
+
+// replaceParts sets new parts to some nodes of the tree, then merges the commands that end up the same
+func (tree *CTree) replaceParts(parts map[*CTree]string) {
+	if len(parts) == 0 {
+		return
+	}
+	for node, part := range parts {
+		node.Part = part
+		node.invalidate()
+	}
+	tree.mergeDuplicates()
+}
+
+// previewReplace returns the commands of the leaves of the tree that change when some text is replaced in their parts
+func (tree *CTree) previewReplace(parts map[*CTree]string) []Change {
+	var changes []Change
+	tree.walk(func(node *CTree) {
+		if len(node.Children) > 0 {
+			return
+		}
+		if command := strings.Join(node.replacedPath(parts), " "); command != node.toCommand() {
+			changes = append(changes, Change{Before: node.toCommand(), After: command, node: node})
+		}
+	})
+	return changes
+}
+
+// replacedPath returns the parts of the command of this node, from its root, with some of them replaced
+func (tree *CTree) replacedPath(parts map[*CTree]string) []string {
+	var path []string
+	for current := tree; current != nil; current = current.Parent {
+		part := current.Part
+		if replaced, ok := parts[current]; ok {
+			part = replaced
+		}
+		path = append([]string{part}, path...)
+	}
+	return path
+}
+
+// invalidate drops the cached commands of this node and its children, which were built from their previous parts
+func (tree *CTree) invalidate() {
+	tree.walk(func(node *CTree) {
+		node.dropCmd()
+	})
+}
+
+// mergeDuplicates merges the children of every node of the tree that have the same part
+func (tree *CTree) mergeDuplicates() {
+	var children []*CTree
+	for _, child := range tree.Children {
+		var kept *CTree
+		for _, other := range children {
+			if other.Part == child.Part {
+				kept = other
+				break
+			}
+		}
+		if kept == nil {
+			children = append(children, child)
+			continue
+		}
+		kept.Meta.merge(child.Meta)
+		kept.stale = append(kept.stale, child.stale...)
+		for _, grandchild := range child.Children {
+			kept.addChild(grandchild)
+		}
+	}
+	tree.Children = children
+	for _, child := range tree.Children {
+		child.mergeDuplicates()
+	}
+}
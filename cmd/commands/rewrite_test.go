@@ -0,0 +1,163 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRewriteArg(t *testing.T) {
+	tests := []struct {
+		name, arg, old, new, expected string
+	}{
+		{"Whole argument", "team-a", "team-a", "team-b", "team-b"},
+		{"Other argument", "team-ab", "team-a", "team-b", "team-ab"},
+		{"Flag value", "--namespace=team-a", "team-a", "team-b", "--namespace=team-b"},
+		{"Label value", "app=web,team=team-a", "team-a", "team-b", "app=web,team=team-b"},
+		{"Label key", "team-a=true", "team-a", "team-b", "team-a=true"},
+		{"Selector flag", "--selector=app=web", "web", "api", "--selector=app=api"},
+		{"Resource name", "deployment/web", "web", "api", "deployment/api"},
+		{"Resource type", "deployment/web", "deployment", "pod", "deployment/web"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			rewritten := rewriteArg(test.arg, test.old, test.new)
+
+			// Assert
+			assert.Equal(t, test.expected, rewritten)
+		})
+	}
+}
+
+func TestCTree_Clone(t *testing.T) {
+	// Arrange
+	tree, err := NewCTree([]string{
+		"kubectl -n team-a get pod -l team=team-a",
+		"kubectl -n team-a logs {{pod}}",
+		"kubectl -n team-c get pod",
+	})
+	assert.Nil(t, err)
+	node := tree.getTree(intPtr(7))
+	node.setValues(map[string]string{"pod": "team-a"})
+	node.Meta.Timeout = time.Minute
+	node.Meta.RunCount = 3
+	tree.GetCmd(7)
+
+	// Act
+	copies, err := tree.Clone(2, "team-a", "team-b")
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Len(t, copies, 6)
+	assert.EqualValues(t, []string{
+		"kubectl -n team-a get pod -l team=team-a",
+		"kubectl -n team-a logs {{pod}}",
+		"kubectl -n team-c get pod",
+		"kubectl -n team-b get pod -l team=team-b",
+		"kubectl -n team-b logs {{pod}}",
+	}, tree.Serialize())
+	copied := copies[5]
+	assert.Equal(t, "kubectl -n team-b logs {{pod}}", copied.toCommand())
+	assert.EqualValues(t, map[string]string{"pod": "team-b"}, copied.Meta.Values)
+	assert.Equal(t, time.Minute, copied.Meta.Timeout)
+	assert.Equal(t, 0, copied.Meta.RunCount)
+	assert.Equal(t, []string{"kubectl", "-n", "team-b", "logs", "team-b"}, tree.GetCmd(16).Args)
+	assert.Equal(t, []string{"kubectl", "-n", "team-a", "logs", "team-a"}, tree.GetCmd(7).Args)
+}
+
+func TestCTree_CloneInvalid(t *testing.T) {
+	// Arrange
+	tree, err := NewCTree([]string{"kubectl -n team-a get pod"})
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name          string
+		position      int
+		old, new      string
+		expectedError string
+	}{
+		{"Not found", 10, "team-a", "team-b", "Command not found"},
+		{"Missing value", 2, "team-c", "team-b", "team-c is not in kubectl -n team-a"},
+		{"Same value", 2, "team-a", "team-a", "The value to replace and its replacement must be different"},
+		{"Empty value", 2, "", "team-b", "The value to replace and its replacement must be different"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			copies, err := tree.Clone(test.position, test.old, test.new)
+
+			// Assert
+			assert.Nil(t, copies)
+			assert.EqualError(t, err, test.expectedError)
+			assert.EqualValues(t, []string{"kubectl -n team-a get pod"}, tree.Serialize())
+		})
+	}
+}
+
+func TestCTree_ReplacePart(t *testing.T) {
+	// Arrange
+	tree, err := NewCTree([]string{
+		"kubectl -n team-a get pod",
+		"kubectl -n team-a get cronjob",
+		"kubectl -n team-b get pod",
+		"kubectl -n team-b logs web",
+	})
+	assert.Nil(t, err)
+	tree.getTree(intPtr(2)).Meta.Notes = "Team A"
+	tree.getTree(intPtr(6)).Meta.Notes = "Team B"
+	tree.GetCmd(4)
+
+	// Act
+	count, err := tree.ReplacePart("team-a", "team-b")
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+	assert.EqualValues(t, []string{
+		"kubectl -n team-b get pod",
+		"kubectl -n team-b get cronjob",
+		"kubectl -n team-b logs web",
+	}, tree.Serialize())
+	assert.Equal(t, "Team A\nTeam B", tree.getTree(intPtr(2)).Meta.Notes)
+	assert.Equal(t, []string{"kubectl", "-n", "team-b", "get", "pod"}, tree.GetCmd(4).Args)
+}
+
+func TestCTree_ReplacePartCanonical(t *testing.T) {
+	// Arrange
+	tree, err := NewCTree([]string{"kubectl get deployment", "kubectl get pod"})
+	assert.Nil(t, err)
+
+	// Act
+	count, err := tree.ReplacePart("deployment", "po")
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+	assert.EqualValues(t, []string{"kubectl get pod"}, tree.Serialize())
+}
+
+func TestCTree_ReplacePartInvalid(t *testing.T) {
+	// Arrange
+	tree, err := NewCTree([]string{"kubectl -n team-a get pod"})
+	assert.Nil(t, err)
+
+	// Act
+	_, emptyErr := tree.ReplacePart("", "x")
+	_, partsErr := tree.ReplacePart("get", "get pod")
+	_, orderErr := tree.ReplacePart("-n team-a", "-o wide")
+	count, noneErr := tree.ReplacePart("team-c", "team-b")
+
+	// Assert
+	assert.NotNil(t, emptyErr)
+	assert.EqualError(t, partsErr, `"get pod" would not be a single part of a command`)
+	assert.EqualError(t, orderErr, `"-o wide" would move to another place in kubectl get pod -o wide`)
+	assert.Nil(t, noneErr)
+	assert.Equal(t, 0, count)
+	assert.EqualValues(t, []string{"kubectl -n team-a get pod"}, tree.Serialize())
+}
+
+func intPtr(value int) *int {
+	return &value
+}
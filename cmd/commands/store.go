@@ -0,0 +1,351 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// StoreVersion is the version of the schema of the store files this version of the app writes
+	StoreVersion int = 1
+)
+
+// Migrations that upgrade a store document from a version (the key) to the next one.
+// Version 0 is the plain-text backup, which is migrated by reading it with Backup.
+var storeMigrations = map[int]func(document map[string]interface{}) error{}
+
+// Store structure represents a versioned JSON file that persists command trees and their metadata.
+// The file is a snapshot of the trees. Changes made after the snapshot are kept in a journal.
+// Several superk instances can use the same store: access is serialized with a file lock,
+// and every instance merges the changes of the others before it writes a snapshot.
+type Store struct {
+	File    string
+	Legacy  *Backup
+	Journal *Journal
+	synced  string
+}
+
+type storeDocument struct {
+	Version    int          `json:"version"`
+	JournalSeq int          `json:"journalSeq,omitempty"`
+	Trees      []*storeNode `json:"trees"`
+}
+
+type storeNode struct {
+	Part         string            `json:"part"`
+	Notes        string            `json:"notes,omitempty"`
+	Pinned       bool              `json:"pinned,omitempty"`
+	LastRun      *time.Time        `json:"lastRun,omitempty"`
+	RunCount     int               `json:"runCount,omitempty"`
+	LastExitCode *int              `json:"lastExitCode,omitempty"`
+	Values       map[string]string `json:"values,omitempty"`
+	Timeout      string            `json:"timeout,omitempty"`
+	Stream       bool              `json:"stream,omitempty"`
+	Context      string            `json:"context,omitempty"`
+	Children     []*storeNode      `json:"children,omitempty"`
+}
+
+// NewStore creates a new store structure. Commands are read from the legacy backup until the store file exists.
+// The journal is kept next to the store file (e.g. commands.journal for commands.json).
+func NewStore(file string, legacy *Backup) *Store {
+	journal := NewJournal(strings.TrimSuffix(file, filepath.Ext(file)) + ".journal")
+	return &Store{File: file, Legacy: legacy, Journal: journal}
+}
+
+// Commands returns the commands in the store: the snapshot plus the changes in the journal.
+// Every change made to the returned forest is written to the journal right away.
+// Roots default to DefaultRoots. Roots found in the store are allowed too, so no command is lost.
+func (store *Store) Commands(roots ...string) (*CForest, error) {
+	var forest *CForest
+	err := store.Journal.withLock(func() error {
+		var err error
+		forest, err = store.commands(roots)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return forest, nil
+}
+
+func (store *Store) commands(roots []string) (*CForest, error) {
+	document, err := store.read()
+	if os.IsNotExist(err) {
+		return store.migrateLegacy(roots)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	forest := newCForestFromDocument(rootsOrDefault(roots), document)
+	if err := store.Journal.Replay(forest, document.JournalSeq); err != nil {
+		return nil, err
+	}
+	forest.journal = store.Journal
+	return forest, nil
+}
+
+func newCForestFromDocument(roots []string, document *storeDocument) *CForest {
+	forest := CForest{Roots: append([]string{}, roots...)}
+	for _, node := range document.Trees {
+		if !forest.IsRoot(node.Part) {
+			forest.Roots = append(forest.Roots, node.Part)
+		}
+		forest.addTree(node.toCTree(nil))
+	}
+	return &forest
+}
+
+func (store *Store) migrateLegacy(roots []string) (*CForest, error) {
+	forest, err := NewCForest(rootsOrDefault(roots), nil)
+	if store.Legacy != nil {
+		forest, err = store.Legacy.Commands(roots...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// There may be changes in the journal if the app crashed before it could write the first snapshot
+	if err := store.Journal.Replay(forest, 0); err != nil {
+		return nil, err
+	}
+	if len(forest.Trees) > 0 {
+		if err := store.write(forest); err != nil {
+			return nil, err
+		}
+	}
+	forest.journal = store.Journal
+	return forest, nil
+}
+
+func rootsOrDefault(roots []string) []string {
+	if len(roots) == 0 {
+		return DefaultRoots
+	}
+	return roots
+}
+
+// read reads the store file, migrating its content to the current schema version if needed
+func (store *Store) read() (*storeDocument, error) {
+	bytes, err := ioutil.ReadFile(store.File)
+	if err != nil {
+		return nil, err
+	}
+
+	var header struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(bytes, &header); err != nil {
+		return nil, err
+	}
+	if header.Version > StoreVersion {
+		return nil, fmt.Errorf("%s was created by a newer version of superk (schema version %d)", store.File, header.Version)
+	}
+
+	if header.Version < StoreVersion {
+		if bytes, err = migrateStoreDocument(bytes, header.Version); err != nil {
+			return nil, err
+		}
+	}
+
+	var document storeDocument
+	if err := json.Unmarshal(bytes, &document); err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+// migrateStoreDocument upgrades a store document from a schema version to the current one
+func migrateStoreDocument(bytes []byte, version int) ([]byte, error) {
+	var document map[string]interface{}
+	if err := json.Unmarshal(bytes, &document); err != nil {
+		return nil, err
+	}
+
+	for ; version < StoreVersion; version++ {
+		migration, ok := storeMigrations[version]
+		if !ok {
+			return nil, fmt.Errorf("Cannot migrate store from schema version %d", version)
+		}
+		if err := migration(document); err != nil {
+			return nil, err
+		}
+	}
+
+	document["version"] = StoreVersion
+	return json.Marshal(document)
+}
+
+// SetCommands writes a new snapshot of the store with the provided commands and empties the journal.
+// Changes that other instances made to the store are merged into the commands first.
+func (store *Store) SetCommands(commands *CForest) error {
+	return store.Journal.withLock(func() error {
+		if _, err := store.sync(commands); err != nil {
+			return err
+		}
+		return store.write(commands)
+	})
+}
+
+// Sync updates a forest with the changes that other instances made to the store since this instance last saw it.
+// It returns whether the forest changed. It is cheap to call when nothing changed on disk.
+func (store *Store) Sync(commands *CForest) (bool, error) {
+	stamp := store.stamp()
+	if stamp == store.synced {
+		return false, nil
+	}
+
+	changed := false
+	err := store.Journal.withLock(func() error {
+		var err error
+		changed, err = store.sync(commands)
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+	store.synced = stamp
+	return changed, nil
+}
+
+// stamp returns a value that changes whenever the store file or its journal change
+func (store *Store) stamp() string {
+	stamp := ""
+	for _, file := range []string{store.File, store.Journal.File} {
+		if info, err := os.Stat(file); err == nil {
+			stamp += fmt.Sprintf("%d:%d;", info.ModTime().UnixNano(), info.Size())
+		} else {
+			stamp += "-;"
+		}
+	}
+	return stamp
+}
+
+// sync rebuilds a forest from the store if other instances changed the store since this instance last saw it.
+// The store must be locked.
+func (store *Store) sync(commands *CForest) (bool, error) {
+	document, err := store.read()
+	if os.IsNotExist(err) {
+		document, err = &storeDocument{Version: StoreVersion}, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	entries, err := store.Journal.Entries()
+	if err != nil {
+		return false, err
+	}
+
+	changed := store.Journal.changedByOthers(document.JournalSeq, entries)
+	if changed {
+		// The store has every change made by every instance (this one included), so it replaces the forest
+		latest := newCForestFromDocument(commands.Roots, document)
+		if err := replay(latest, entries, document.JournalSeq); err != nil {
+			return false, err
+		}
+		commands.replaceTrees(latest)
+	}
+	store.Journal.see(document.JournalSeq, entries)
+	return changed, nil
+}
+
+// write writes a new snapshot of the store and empties the journal. The store must be locked.
+func (store *Store) write(commands *CForest) error {
+	document := storeDocument{Version: StoreVersion, JournalSeq: store.Journal.seen, Trees: []*storeNode{}}
+	for _, tree := range commands.Trees {
+		document.Trees = append(document.Trees, newStoreNode(tree))
+	}
+
+	bytes, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(store.File, bytes, 0600); err != nil {
+		return err
+	}
+	return store.Journal.Reset(document.JournalSeq)
+}
+
+// Migrate rewrites the store with the canonical form of its commands, merging the metadata of duplicates.
+// It returns the number of commands before and after the migration.
+func (store *Store) Migrate(roots ...string) (int, int, error) {
+	forest, err := store.Commands(roots...)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	commands := forest.Serialize()
+	canonical, err := NewCForest(forest.Roots, commands)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, tree := range forest.Trees {
+		tree.walk(func(node *CTree) {
+			if target := canonical.find(node.toCommand()); target != nil {
+				target.Meta.merge(node.Meta)
+			}
+		})
+	}
+
+	return len(commands), len(canonical.Serialize()), store.SetCommands(canonical)
+}
+
+// Delete deletes the store file and its journal
+func (store *Store) Delete() error {
+	if err := os.Remove(store.File); err != nil {
+		return err
+	}
+	return store.Journal.Delete()
+}
+
+func newStoreNode(tree *CTree) *storeNode {
+	node := storeNode{
+		Part:         tree.Part,
+		Notes:        tree.Meta.Notes,
+		Pinned:       tree.Meta.Pinned,
+		LastRun:      tree.Meta.LastRun,
+		RunCount:     tree.Meta.RunCount,
+		LastExitCode: tree.Meta.LastExitCode,
+		Values:       tree.Meta.Values,
+		Stream:       tree.Meta.Stream,
+		Context:      tree.Meta.Context,
+	}
+	if tree.Meta.Timeout > 0 {
+		node.Timeout = tree.Meta.Timeout.String()
+	}
+	for _, child := range tree.Children {
+		node.Children = append(node.Children, newStoreNode(child))
+	}
+	return &node
+}
+
+func (node *storeNode) toCTree(parent *CTree) *CTree {
+	tree := CTree{
+		Part: node.Part,
+		Meta: CMeta{
+			Notes:        node.Notes,
+			Pinned:       node.Pinned,
+			LastRun:      node.LastRun,
+			RunCount:     node.RunCount,
+			LastExitCode: node.LastExitCode,
+			Values:       node.Values,
+			Stream:       node.Stream,
+			Context:      node.Context,
+		},
+		Parent: parent,
+	}
+	// Commands with an invalid timeout take the default one
+	if timeout, err := time.ParseDuration(node.Timeout); err == nil && timeout > 0 {
+		tree.Meta.Timeout = timeout
+	}
+	for _, child := range node.Children {
+		tree.Children = append(tree.Children, child.toCTree(&tree))
+	}
+	return &tree
+}
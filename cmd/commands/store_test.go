@@ -0,0 +1,412 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStore_SetCommands(t *testing.T) {
+	// Arrange
+	path, err := getTmpPath("superk_test_store_")
+	assert.Nil(t, err)
+	err = os.Remove(path)
+	assert.Nil(t, err)
+	store := NewStore(path, nil)
+
+	expected := []string{
+		"kubectl -n kubeflow get pod",
+		"kubectl -n kubeflow get cronjob -l 'app in (a,b)'",
+		"helm list",
+	}
+	forest, err := NewCForest(DefaultRoots, expected)
+	assert.Nil(t, err)
+
+	lastRun := time.Date(2020, 2, 20, 10, 30, 0, 0, time.UTC)
+	exitCode := 1
+	node := forest.Trees[0].Children[0].Children[0].Children[0]
+	node.Meta = CMeta{
+		Notes:        "Kubeflow pods",
+		Pinned:       true,
+		LastRun:      &lastRun,
+		RunCount:     3,
+		LastExitCode: &exitCode,
+		Values:       map[string]string{"app": "web"},
+		Timeout:      90 * time.Second,
+		Stream:       true,
+	}
+	forest.Trees[0].Meta.Context = "aks-prod"
+
+	// Act
+	err = store.SetCommands(forest)
+
+	// Assert
+	assert.Nil(t, err)
+	result, err := store.Commands()
+	assert.Nil(t, err)
+	assert.EqualValues(t, expected, result.Serialize())
+	resultNode := result.Trees[0].Children[0].Children[0].Children[0]
+	assert.Equal(t, "pod", resultNode.Part)
+	assert.Equal(t, "Kubeflow pods", resultNode.Meta.Notes)
+	assert.True(t, resultNode.Meta.Pinned)
+	assert.True(t, lastRun.Equal(*resultNode.Meta.LastRun))
+	assert.Equal(t, 3, resultNode.Meta.RunCount)
+	assert.Equal(t, 1, *resultNode.Meta.LastExitCode)
+	assert.EqualValues(t, map[string]string{"app": "web"}, resultNode.Meta.Values)
+	assert.Equal(t, 90*time.Second, resultNode.Meta.Timeout)
+	assert.True(t, resultNode.Meta.Stream)
+	assert.Equal(t, "aks-prod", result.Trees[0].Meta.Context)
+	assert.Equal(t, result.Trees[0].Children[0].Children[0], resultNode.Parent)
+
+	// Cleanup
+	err = store.Delete()
+	assert.Nil(t, err)
+}
+
+func TestStore_CommandsFromLegacyBackup(t *testing.T) {
+	// Arrange
+	path, err := getTmpPath("superk_test_store_")
+	assert.Nil(t, err)
+	err = os.Remove(path)
+	assert.Nil(t, err)
+
+	backupPath, err := getTmpPath("superk_test_")
+	assert.Nil(t, err)
+	backup := NewBackup(filepath.Base(backupPath))
+	expected := []string{
+		"kubectl -n kubeflow get pod",
+		"kubectl -n pipelines get pod",
+	}
+	err = backup.create(expected)
+	assert.Nil(t, err)
+
+	store := NewStore(path, backup)
+
+	// Act
+	result, err := store.Commands()
+
+	// Assert
+	assert.Nil(t, err)
+	assert.EqualValues(t, expected, result.Serialize())
+	_, err = os.Stat(path)
+	assert.Nil(t, err)
+
+	// Cleanup
+	err = store.Delete()
+	assert.Nil(t, err)
+	err = backup.Delete()
+	assert.Nil(t, err)
+}
+
+func TestStore_CommandsAfterCrash(t *testing.T) {
+	// Arrange
+	path, err := getTmpPath("superk_test_store_")
+	assert.Nil(t, err)
+	err = os.Remove(path)
+	assert.Nil(t, err)
+	store := NewStore(path, nil)
+	forest, err := NewCForest(DefaultRoots, []string{"kubectl -n kubeflow get pod"})
+	assert.Nil(t, err)
+	err = store.SetCommands(forest)
+	assert.Nil(t, err)
+
+	forest, err = store.Commands()
+	assert.Nil(t, err)
+	err = forest.MergeCommand("kubectl -n kubeflow get cronjob")
+	assert.Nil(t, err)
+	err = forest.RemoveCommand(4)
+	assert.Nil(t, err)
+
+	// Act
+	result, err := NewStore(path, nil).Commands()
+
+	// Assert
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"kubectl -n kubeflow get cronjob"}, result.Serialize())
+
+	// Cleanup
+	err = store.Delete()
+	assert.Nil(t, err)
+}
+
+func TestStore_SetCommandsResetsJournal(t *testing.T) {
+	// Arrange
+	path, err := getTmpPath("superk_test_store_")
+	assert.Nil(t, err)
+	err = os.Remove(path)
+	assert.Nil(t, err)
+	store := NewStore(path, nil)
+	forest, err := store.Commands()
+	assert.Nil(t, err)
+	err = forest.MergeCommand("kubectl -n kubeflow get pod")
+	assert.Nil(t, err)
+
+	// Act
+	err = store.SetCommands(forest)
+
+	// Assert
+	assert.Nil(t, err)
+	entries, err := store.Journal.Entries()
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, JournalSnapshot, entries[0].Op)
+	result, err := NewStore(path, nil).Commands()
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"kubectl -n kubeflow get pod"}, result.Serialize())
+
+	// Cleanup
+	err = store.Delete()
+	assert.Nil(t, err)
+}
+
+func TestStore_CommandsMissing(t *testing.T) {
+	// Arrange
+	path := filepath.Join(os.TempDir(), "superk_test_missing_store.json")
+	store := NewStore(path, nil)
+
+	// Act
+	result, err := store.Commands("kubectl", "helm")
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Len(t, result.Trees, 0)
+	assert.EqualValues(t, []string{"kubectl", "helm"}, result.Roots)
+
+	// Cleanup
+	err = store.Journal.Delete()
+	assert.Nil(t, err)
+}
+
+func TestStore_CommandsUnknownRoot(t *testing.T) {
+	// Arrange
+	path, err := writeTmpStore(`{"version": 1, "trees": [{"part": "kind", "children": [{"part": "get"}]}]}`)
+	assert.Nil(t, err)
+	store := NewStore(path, nil)
+
+	// Act
+	result, err := store.Commands("kubectl")
+
+	// Assert
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"kubectl", "kind"}, result.Roots)
+	assert.EqualValues(t, []string{"kind get"}, result.Serialize())
+
+	// Cleanup
+	err = store.Delete()
+	assert.Nil(t, err)
+}
+
+func TestStore_CommandsNewerVersion(t *testing.T) {
+	// Arrange
+	path, err := writeTmpStore(`{"version": 1000, "trees": []}`)
+	assert.Nil(t, err)
+	store := NewStore(path, nil)
+
+	// Act
+	result, err := store.Commands()
+
+	// Assert
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+
+	// Cleanup
+	err = store.Delete()
+	assert.Nil(t, err)
+}
+
+func TestStore_CommandsOlderVersion(t *testing.T) {
+	// Arrange
+	path, err := writeTmpStore(`{"version": 0, "commands": ["kubectl get pod"]}`)
+	assert.Nil(t, err)
+	store := NewStore(path, nil)
+
+	storeMigrations[0] = func(document map[string]interface{}) error {
+		document["trees"] = []interface{}{
+			map[string]interface{}{
+				"part":     "kubectl",
+				"children": []interface{}{map[string]interface{}{"part": "get"}},
+			},
+		}
+		delete(document, "commands")
+		return nil
+	}
+	defer delete(storeMigrations, 0)
+
+	// Act
+	result, err := store.Commands()
+
+	// Assert
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"kubectl get"}, result.Serialize())
+
+	// Cleanup
+	err = store.Delete()
+	assert.Nil(t, err)
+}
+
+func TestStore_CommandsOlderVersionWithoutMigration(t *testing.T) {
+	// Arrange
+	path, err := writeTmpStore(`{"version": 0}`)
+	assert.Nil(t, err)
+	store := NewStore(path, nil)
+
+	// Act
+	result, err := store.Commands()
+
+	// Assert
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+
+	// Cleanup
+	err = store.Delete()
+	assert.Nil(t, err)
+}
+
+func TestStore_Migrate(t *testing.T) {
+	// Arrange
+	path, err := writeTmpStore(`{"version": 1, "trees": [{"part": "kubectl", "children": [
+		{"part": "get", "children": [{"part": "po", "runCount": 2, "children": [{"part": "--namespace=kubeflow", "runCount": 1}]}]},
+		{"part": "-n kubeflow", "children": [{"part": "get", "children": [{"part": "pod", "runCount": 3, "pinned": true}]}]}
+	]}]}`)
+	assert.Nil(t, err)
+	store := NewStore(path, nil)
+
+	// Act
+	before, after, err := store.Migrate()
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 2, before)
+	assert.Equal(t, 1, after)
+	result, err := store.Commands()
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"kubectl -n kubeflow get pod"}, result.Serialize())
+	node := result.Trees[0].Children[0].Children[0].Children[0]
+	assert.Equal(t, 4, node.Meta.RunCount)
+	assert.True(t, node.Meta.Pinned)
+
+	// Cleanup
+	err = store.Delete()
+	assert.Nil(t, err)
+}
+
+func TestStore_SetCommandsMergesOtherInstances(t *testing.T) {
+	// Arrange
+	path, err := writeTmpStore(`{"version": 1, "trees": [{"part": "kubectl", "children": [{"part": "get", "children": [
+		{"part": "pod"}, {"part": "cronjob"}
+	]}]}]}`)
+	assert.Nil(t, err)
+	first := NewStore(path, nil)
+	second := NewStore(path, nil)
+	firstForest, err := first.Commands()
+	assert.Nil(t, err)
+	secondForest, err := second.Commands()
+	assert.Nil(t, err)
+
+	err = firstForest.MergeCommand("helm list")
+	assert.Nil(t, err)
+	err = secondForest.MergeCommand("kind get clusters")
+	assert.Nil(t, err)
+	err = secondForest.RemoveCommand(4)
+	assert.Nil(t, err)
+	err = second.SetCommands(secondForest)
+	assert.Nil(t, err)
+
+	// Act
+	err = first.SetCommands(firstForest)
+
+	// Assert
+	assert.Nil(t, err)
+	expected := []string{"kubectl get pod", "helm list", "kind get clusters"}
+	assert.EqualValues(t, expected, firstForest.Serialize())
+	result, err := NewStore(path, nil).Commands()
+	assert.Nil(t, err)
+	assert.EqualValues(t, expected, result.Serialize())
+
+	// Cleanup
+	err = first.Delete()
+	assert.Nil(t, err)
+}
+
+func TestStore_Sync(t *testing.T) {
+	// Arrange
+	path, err := writeTmpStore(`{"version": 1, "trees": [{"part": "kubectl", "children": [{"part": "get"}]}]}`)
+	assert.Nil(t, err)
+	first := NewStore(path, nil)
+	second := NewStore(path, nil)
+	firstForest, err := first.Commands()
+	assert.Nil(t, err)
+	secondForest, err := second.Commands()
+	assert.Nil(t, err)
+	firstForest.Trees[0].Cmd = NewCmd("kubectl")
+	err = firstForest.MergeCommand("kubectl get pod")
+	assert.Nil(t, err)
+
+	// Act
+	unchanged, err1 := first.Sync(firstForest)
+	err = secondForest.MergeCommand("helm list")
+	assert.Nil(t, err)
+	changed, err2 := first.Sync(firstForest)
+
+	// Assert
+	assert.Nil(t, err1)
+	assert.Nil(t, err2)
+	assert.False(t, unchanged)
+	assert.True(t, changed)
+	assert.EqualValues(t, []string{"kubectl get pod", "helm list"}, firstForest.Serialize())
+	assert.NotNil(t, firstForest.Trees[0].Cmd)
+
+	// Cleanup
+	err = first.Delete()
+	assert.Nil(t, err)
+}
+
+func TestStore_SyncStaleCmd(t *testing.T) {
+	// Arrange
+	path, err := writeTmpStore(`{"version": 1, "trees": [{"part": "kubectl", "children": [{"part": "get"}]}]}`)
+	assert.Nil(t, err)
+	first := NewStore(path, nil)
+	second := NewStore(path, nil)
+	firstForest, err := first.Commands()
+	assert.Nil(t, err)
+	secondForest, err := second.Commands()
+	assert.Nil(t, err)
+	stale := NewCmd("sleep", "0.2")
+	done := make(chan *CmdOutput, 1)
+	stale.RunAsync(func(output *CmdOutput) { done <- output })
+	firstForest.Trees[0].Children[0].stale = []*Cmd{stale}
+	err = secondForest.MergeCommand("helm list")
+	assert.Nil(t, err)
+
+	// Act
+	changed, err := first.Sync(firstForest)
+	recorded := firstForest.RecordRun(stale, <-done)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.True(t, changed)
+	assert.True(t, recorded)
+	assert.Equal(t, 1, firstForest.Trees[0].Children[0].Meta.RunCount)
+
+	// Cleanup
+	err = first.Delete()
+	assert.Nil(t, err)
+}
+
+func writeTmpStore(content string) (string, error) {
+	tmpFile, err := ioutil.TempFile(os.TempDir(), "superk_test_store_")
+	if err != nil {
+		return "", err
+	}
+	if _, err := tmpFile.WriteString(content); err != nil {
+		return "", err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return "", err
+	}
+	return tmpFile.Name(), nil
+}
@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+)
+
+const (
+	// StreamMaxLines is how many lines of the output of a streaming command are kept.
+	// Older lines are dropped, so memory stays flat however long the command runs.
+	StreamMaxLines = 10000
+	// streamMaxLineLength is how long a line can get before it is cut, for commands that never write a new line
+	streamMaxLineLength = 64 * 1024
+)
+
+// LineBuffer is a writer that keeps the last lines written to it, so they can be read while they are being written
+type LineBuffer struct {
+	mutex   sync.Mutex
+	max     int
+	lines   []string
+	partial []byte
+	total   int
+}
+
+// NewLineBuffer creates a new LineBuffer that keeps up to max lines
+func NewLineBuffer(max int) *LineBuffer {
+	return &LineBuffer{max: max}
+}
+
+// Write adds the complete lines in the data to the buffer. The last line waits for the rest of it.
+func (buffer *LineBuffer) Write(data []byte) (int, error) {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+
+	buffer.partial = append(buffer.partial, data...)
+	for {
+		index := bytes.IndexByte(buffer.partial, '\n')
+		if index < 0 {
+			break
+		}
+		buffer.add(string(buffer.partial[:index]))
+		buffer.partial = buffer.partial[index+1:]
+	}
+	if len(buffer.partial) > streamMaxLineLength {
+		buffer.add(string(buffer.partial))
+		buffer.partial = nil
+	}
+	return len(data), nil
+}
+
+func (buffer *LineBuffer) add(line string) {
+	buffer.lines = append(buffer.lines, line)
+	buffer.total++
+	if len(buffer.lines) > buffer.max {
+		// Appending eventually moves the lines to a new array, so dropped lines do not stay in memory
+		buffer.lines = buffer.lines[len(buffer.lines)-buffer.max:]
+	}
+}
+
+// Lines returns the complete lines written to the buffer from a certain line on (0 being the first line ever written),
+// and the number of lines written so far. Lines that were dropped from the buffer are not returned.
+func (buffer *LineBuffer) Lines(from int) ([]string, int) {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+
+	first := buffer.total - len(buffer.lines)
+	if from < first {
+		from = first
+	}
+	if from >= buffer.total {
+		return nil, buffer.total
+	}
+	lines := make([]string, buffer.total-from)
+	copy(lines, buffer.lines[from-first:])
+	return lines, buffer.total
+}
+
+// String returns the lines kept in the buffer, including the last one even if it is not complete
+func (buffer *LineBuffer) String() string {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+
+	var builder strings.Builder
+	for _, line := range buffer.lines {
+		builder.WriteString(line)
+		builder.WriteByte('\n')
+	}
+	builder.Write(buffer.partial)
+	return builder.String()
+}
+
+// streams returns whether a command keeps running and writing output until it is stopped
+// (e.g. kubectl logs -f, kubectl get pod -w or kubectl rollout status)
+func streams(args []string) bool {
+	var logs, rollout, follow bool
+	for _, arg := range args {
+		switch arg {
+		case "logs":
+			logs = true
+		case "rollout":
+			rollout = true
+		case "-f", "--follow", "--follow=true":
+			// -f is also the file of commands like kubectl apply
+			follow = true
+		case "-w", "--watch", "--watch=true", "--watch-only", "--watch-only=true", "attach", "port-forward":
+			return true
+		case "status":
+			if rollout {
+				return true
+			}
+		}
+	}
+	return logs && follow
+}
@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStream_LineBuffer(t *testing.T) {
+	// Arrange
+	buffer := NewLineBuffer(3)
+
+	// Act
+	_, err := buffer.Write([]byte("one\ntw"))
+	first, firstTotal := buffer.Lines(0)
+	_, _ = buffer.Write([]byte("o\nthree\nfour\nfi"))
+	all, total := buffer.Lines(0)
+	newer, _ := buffer.Lines(3)
+	none, _ := buffer.Lines(4)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"one"}, first)
+	assert.Equal(t, 1, firstTotal)
+	assert.EqualValues(t, []string{"two", "three", "four"}, all)
+	assert.Equal(t, 4, total)
+	assert.EqualValues(t, []string{"four"}, newer)
+	assert.Empty(t, none)
+	assert.Equal(t, "two\nthree\nfour\nfi", buffer.String())
+}
+
+func TestStream_LineBufferLongLine(t *testing.T) {
+	// Arrange
+	buffer := NewLineBuffer(3)
+
+	// Act
+	_, _ = buffer.Write(make([]byte, streamMaxLineLength+1))
+	lines, total := buffer.Lines(0)
+
+	// Assert
+	assert.Equal(t, 1, total)
+	assert.Len(t, lines[0], streamMaxLineLength+1)
+}
+
+func TestStream_Streams(t *testing.T) {
+	for _, test := range []struct {
+		command  string
+		expected bool
+	}{
+		{"kubectl logs -f web", true},
+		{"kubectl -n kubeflow logs web --follow", true},
+		{"kubectl logs web", false},
+		{"kubectl apply -f web.yaml", false},
+		{"kubectl get pod -w", true},
+		{"kubectl get pod --watch-only", true},
+		{"kubectl rollout status deployment/web", true},
+		{"kubectl rollout history deployment/web", false},
+		{"kubectl port-forward svc/web 8080:80", true},
+		{"kubectl get pod", false},
+	} {
+		t.Run(test.command, func(t *testing.T) {
+			// Arrange
+			parts, err := split(test.command)
+			assert.Nil(t, err)
+
+			// Act
+			result := streams(parts)
+
+			// Assert
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package commands
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Ages of resources (e.g. 5d3h or 45s) and numbers at the start of cells (e.g. 3 in "3 (5m ago)" or in 1/2)
+var (
+	tableAge    = regexp.MustCompile(`^(\d+y)?(\d+d)?(\d+h)?(\d+m)?(\d+s)?$`)
+	tableNumber = regexp.MustCompile(`^-?\d+(\.\d+)?`)
+)
+
+// Table represents the output of kubectl get: the cells of its rows, under its columns
+type Table struct {
+	Columns []string
+	Rows    [][]string
+}
+
+// TableView represents how a table is shown: sorted by one of its columns, with only some of its rows and columns.
+// Settings refer to columns by name, so they still apply to the next output of the same command.
+type TableView struct {
+	SortColumn string
+	Descending bool
+	// Filters are what the cells of some columns must contain, by column. A filter that starts with ! excludes them instead.
+	Filters map[string]string
+	Hidden  map[string]bool
+}
+
+// PrintsTable returns whether a command prints a table: kubectl get with its default or wide output
+func (cmd *Cmd) PrintsTable() bool {
+	if cmd.Path != "kubectl" {
+		return false
+	}
+	if output, ok := lookupFlag(cmd.Args, "-o", "--output"); ok && output != "wide" {
+		return false
+	}
+
+	verb := ""
+	for index := 1; index < len(cmd.Args); index++ {
+		arg := cmd.Args[index]
+		if arg == "--" {
+			break
+		}
+		if !isFlag(arg) {
+			verb = arg
+			break
+		}
+		if takesValue(arg, "") && index+1 < len(cmd.Args) {
+			index++
+		}
+	}
+	return verb == "get"
+}
+
+// ParseTable parses the output of kubectl get into a table. Cells are where the columns of the header are,
+// so they may contain spaces (e.g. "3 (5m ago)"). It returns nil if the output is not a single table.
+func ParseTable(output string) *Table {
+	parsed := parseOutput(output)
+	if parsed.columns == nil {
+		return nil
+	}
+
+	table := Table{Columns: parsed.columns}
+	for line := 1; line < len(parsed.lines); line++ {
+		// Outputs of several kinds of resources (e.g. kubectl get pod,svc) are several tables
+		if strings.TrimSpace(parsed.lines[line]) == "" {
+			return nil
+		}
+		row := make([]string, len(parsed.columns))
+		for index, column := range parsed.columns {
+			row[index], _ = parsed.cell(line, column)
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return &table
+}
+
+// Column returns the position of a column in the table, or -1 if the table does not have it
+func (table *Table) Column(name string) int {
+	for index, column := range table.Columns {
+		if column == name {
+			return index
+		}
+	}
+	return -1
+}
+
+// NewTableView creates a view that shows a whole table as it is
+func NewTableView() *TableView {
+	return &TableView{Filters: map[string]string{}, Hidden: map[string]bool{}}
+}
+
+// Columns returns the positions of the columns of a table that are not hidden
+func (view *TableView) Columns(table *Table) []int {
+	var columns []int
+	for index, column := range table.Columns {
+		if !view.Hidden[column] {
+			columns = append(columns, index)
+		}
+	}
+	return columns
+}
+
+// Rows returns the positions of the rows of a table that pass the filters, sorted
+func (view *TableView) Rows(table *Table) []int {
+	var rows []int
+	for index, row := range table.Rows {
+		if view.matches(table, row) {
+			rows = append(rows, index)
+		}
+	}
+
+	if column := table.Column(view.SortColumn); column >= 0 {
+		sort.SliceStable(rows, func(i, j int) bool {
+			a, b := table.Rows[rows[i]][column], table.Rows[rows[j]][column]
+			if view.Descending {
+				a, b = b, a
+			}
+			return compareCells(a, b) < 0
+		})
+	}
+	return rows
+}
+
+// matches returns whether a row of a table passes the filters
+func (view *TableView) matches(table *Table, row []string) bool {
+	for column, filter := range view.Filters {
+		index := table.Column(column)
+		if index < 0 || filter == "" {
+			continue
+		}
+		exclude := strings.HasPrefix(filter, "!")
+		filter = strings.ToLower(strings.TrimPrefix(filter, "!"))
+		if strings.Contains(strings.ToLower(row[index]), filter) == exclude {
+			return false
+		}
+	}
+	return true
+}
+
+// Sort sorts the rows by a column: ascending first, then descending, then not sorted
+func (view *TableView) Sort(column string) {
+	switch {
+	case view.SortColumn != column:
+		view.SortColumn, view.Descending = column, false
+	case !view.Descending:
+		view.Descending = true
+	default:
+		view.SortColumn, view.Descending = "", false
+	}
+}
+
+// SetFilter sets what the cells of a column must contain. An empty filter shows every row again.
+func (view *TableView) SetFilter(column, filter string) {
+	if filter == "" {
+		delete(view.Filters, column)
+		return
+	}
+	view.Filters[column] = filter
+}
+
+// Widths returns how wide some columns of the table are for some of its rows, given how their headers are shown
+func (table *Table) Widths(columns []int, headers []string, rows []int) []int {
+	widths := make([]int, len(columns))
+	for index, header := range headers {
+		widths[index] = len([]rune(header))
+	}
+	for _, row := range rows {
+		for index, column := range columns {
+			if width := len([]rune(table.Rows[row][column])); width > widths[index] {
+				widths[index] = width
+			}
+		}
+	}
+	return widths
+}
+
+// compareCells compares two cells of the same column: as ages (e.g. 5d3h), as numbers (e.g. restarts), or as text
+func compareCells(a, b string) int {
+	if ageA, ok := parseAge(a); ok {
+		if ageB, ok := parseAge(b); ok {
+			return compareNumbers(float64(ageA), float64(ageB))
+		}
+	}
+	if numberA := tableNumber.FindString(a); numberA != "" {
+		if numberB := tableNumber.FindString(b); numberB != "" {
+			x, _ := strconv.ParseFloat(numberA, 64)
+			y, _ := strconv.ParseFloat(numberB, 64)
+			if x != y {
+				return compareNumbers(x, y)
+			}
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+func compareNumbers(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// parseAge parses the age of a resource as kubectl prints it (e.g. 2y3d, 5d3h, 12m or 45s)
+func parseAge(age string) (time.Duration, bool) {
+	matches := tableAge.FindStringSubmatch(age)
+	if age == "" || matches == nil {
+		return 0, false
+	}
+
+	units := []time.Duration{365 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var duration time.Duration
+	for index, match := range matches[1:] {
+		if match != "" {
+			value, _ := strconv.Atoi(match[:len(match)-1])
+			duration += time.Duration(value) * units[index]
+		}
+	}
+	return duration, true
+}
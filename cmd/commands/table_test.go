@@ -0,0 +1,192 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const tablePods = `NAME                     READY   STATUS             RESTARTS      AGE
+web-5d5c9f8b7-kp2lw      1/1     Running            0             5d3h
+api-7c9d8f6b5-x2v9q      0/1     CrashLoopBackOff   12 (2m ago)   3h
+worker-6b7c8d9e0-q1w2e   1/1     Running            2 (1d ago)    45s
+`
+
+func TestCmd_PrintsTable(t *testing.T) {
+	tests := []struct {
+		name     string
+		cmd      *Cmd
+		expected bool
+	}{
+		{"Get", NewCmd("kubectl", "get", "pod"), true},
+		{"Get with flags", NewCmd("kubectl", "-n", "kubeflow", "--context=aks-prod", "get", "pod", "-l", "app=web"), true},
+		{"Wide", NewCmd("kubectl", "get", "pod", "-o", "wide"), true},
+		{"YAML", NewCmd("kubectl", "get", "pod", "-o", "yaml"), false},
+		{"Other verb", NewCmd("kubectl", "-n", "get", "describe", "pod"), false},
+		{"Other binary", NewCmd("helm", "get", "values", "web"), false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			printsTable := test.cmd.PrintsTable()
+
+			// Assert
+			assert.Equal(t, test.expected, printsTable)
+		})
+	}
+}
+
+func TestParseTable(t *testing.T) {
+	// Act
+	table := ParseTable(tablePods)
+
+	// Assert
+	assert.NotNil(t, table)
+	assert.Equal(t, []string{"NAME", "READY", "STATUS", "RESTARTS", "AGE"}, table.Columns)
+	assert.Equal(t, [][]string{
+		{"web-5d5c9f8b7-kp2lw", "1/1", "Running", "0", "5d3h"},
+		{"api-7c9d8f6b5-x2v9q", "0/1", "CrashLoopBackOff", "12 (2m ago)", "3h"},
+		{"worker-6b7c8d9e0-q1w2e", "1/1", "Running", "2 (1d ago)", "45s"},
+	}, table.Rows)
+	assert.Equal(t, 3, table.Column("RESTARTS"))
+	assert.Equal(t, -1, table.Column("IP"))
+}
+
+func TestParseTable_NotTable(t *testing.T) {
+	tests := []struct {
+		name, output string
+	}{
+		{"Empty", ""},
+		{"YAML", "apiVersion: v1\nkind: Pod\n"},
+		{"Sentence", "NO RESOURCES FOUND\n"},
+		{"Several tables", "NAME   READY\nweb    1/1\n\nNAME   TYPE\nweb    ClusterIP\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			table := ParseTable(test.output)
+
+			// Assert
+			assert.Nil(t, table)
+		})
+	}
+}
+
+func TestTableView_Rows(t *testing.T) {
+	table := ParseTable(tablePods)
+	tests := []struct {
+		name       string
+		sortColumn string
+		descending bool
+		filters    map[string]string
+		expected   []int
+	}{
+		{"As is", "", false, nil, []int{0, 1, 2}},
+		{"By name", "NAME", false, nil, []int{1, 0, 2}},
+		{"By age", "AGE", false, nil, []int{2, 1, 0}},
+		{"By restarts descending", "RESTARTS", true, nil, []int{1, 2, 0}},
+		{"Filtered", "", false, map[string]string{"STATUS": "running"}, []int{0, 2}},
+		{"Excluded", "", false, map[string]string{"STATUS": "!Running"}, []int{1}},
+		{"Filtered and sorted", "AGE", false, map[string]string{"READY": "1/1"}, []int{2, 0}},
+		{"Filter of a missing column", "", false, map[string]string{"IP": "10.0"}, []int{0, 1, 2}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Arrange
+			view := NewTableView()
+			view.SortColumn, view.Descending = test.sortColumn, test.descending
+			for column, filter := range test.filters {
+				view.SetFilter(column, filter)
+			}
+
+			// Act
+			rows := view.Rows(table)
+
+			// Assert
+			assert.Equal(t, test.expected, rows)
+		})
+	}
+}
+
+func TestTableView_Columns(t *testing.T) {
+	// Arrange
+	table := ParseTable(tablePods)
+	view := NewTableView()
+	view.Hidden["READY"], view.Hidden["AGE"] = true, true
+
+	// Act
+	columns := view.Columns(table)
+
+	// Assert
+	assert.Equal(t, []int{0, 2, 3}, columns)
+}
+
+func TestTableView_Sort(t *testing.T) {
+	// Arrange
+	view := NewTableView()
+
+	// Act & Assert
+	view.Sort("AGE")
+	assert.Equal(t, "AGE", view.SortColumn)
+	assert.False(t, view.Descending)
+	view.Sort("AGE")
+	assert.Equal(t, "AGE", view.SortColumn)
+	assert.True(t, view.Descending)
+	view.Sort("AGE")
+	assert.Equal(t, "", view.SortColumn)
+	view.Sort("AGE")
+	view.Sort("NAME")
+	assert.Equal(t, "NAME", view.SortColumn)
+	assert.False(t, view.Descending)
+}
+
+func TestTableView_SetFilter(t *testing.T) {
+	// Arrange
+	view := NewTableView()
+
+	// Act
+	view.SetFilter("STATUS", "Running")
+	view.SetFilter("NAME", "web")
+	view.SetFilter("NAME", "")
+
+	// Assert
+	assert.Equal(t, map[string]string{"STATUS": "Running"}, view.Filters)
+}
+
+func TestTable_Widths(t *testing.T) {
+	// Arrange
+	table := ParseTable(tablePods)
+
+	// Act
+	widths := table.Widths([]int{1, 2}, []string{"READY", "STATUS ↑"}, []int{0, 2})
+
+	// Assert
+	assert.Equal(t, []int{5, 8}, widths)
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		age      string
+		expected time.Duration
+		ok       bool
+	}{
+		{"45s", 45 * time.Second, true},
+		{"12m", 12 * time.Minute, true},
+		{"5d3h", 123 * time.Hour, true},
+		{"2y3d", (2*365 + 3) * 24 * time.Hour, true},
+		{"", 0, false},
+		{"<unknown>", 0, false},
+		{"1/1", 0, false},
+	}
+	for _, test := range tests {
+		t.Run(test.age, func(t *testing.T) {
+			// Act
+			age, ok := parseAge(test.age)
+
+			// Assert
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, test.expected, age)
+		})
+	}
+}
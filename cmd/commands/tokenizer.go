@@ -0,0 +1,256 @@
+package commands
+
+import (
+	"errors"
+	"strings"
+)
+
+// word represents a shell word, both as the user typed it and after removing quotes and escapes
+type word struct {
+	raw   string
+	value string
+}
+
+// Flags that never take a value, so the word after them is not glued to them
+var booleanFlags = map[string]bool{
+	"-A":                         true,
+	"--all-namespaces":           true,
+	"-h":                         true,
+	"--help":                     true,
+	"-i":                         true,
+	"--stdin":                    true,
+	"-t":                         true,
+	"--tty":                      true,
+	"-it":                        true,
+	"-ti":                        true,
+	"-R":                         true,
+	"--recursive":                true,
+	"-w":                         true,
+	"--watch":                    true,
+	"--all":                      true,
+	"--all-containers":           true,
+	"--delete-emptydir-data":     true,
+	"--delete-local-data":        true,
+	"--dry-run":                  true,
+	"--follow":                   true,
+	"--force":                    true,
+	"--ignore-daemonsets":        true,
+	"--ignore-not-found":         true,
+	"--insecure-skip-tls-verify": true,
+	"--no-headers":               true,
+	"--now":                      true,
+	"--overwrite":                true,
+	"--previous":                 true,
+	"--record":                   true,
+	"--server-side":              true,
+	"--show-kind":                true,
+	"--show-labels":              true,
+	"--timestamps":               true,
+	"--wait":                     true,
+	"--watch-only":               true,
+}
+
+// Flags that only behave as boolean flags for some verbs (e.g. "logs -f" vs "apply -f file.yaml")
+var verbBooleanFlags = map[string]map[string]bool{
+	"logs": {"-f": true, "-p": true},
+}
+
+// tokenize splits a command into shell words following POSIX quoting rules:
+//   - Single quotes preserve every character until the closing quote
+//   - Double quotes preserve every character but \, which escapes $ ` " \ and newline
+//   - Outside quotes, \ escapes the next character
+//
+// Placeholders are kept as they are, even if they contain spaces or quotes (e.g. {{pod from "kubectl get pod"}}).
+func tokenize(command string) ([]word, error) {
+	var words []word
+	var raw, value strings.Builder
+	inWord := false
+
+	flush := func() {
+		if inWord {
+			words = append(words, word{raw: raw.String(), value: value.String()})
+			raw.Reset()
+			value.Reset()
+			inWord = false
+		}
+	}
+
+	runes := []rune(command)
+	for i := 0; i < len(runes); i++ {
+		ch := runes[i]
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n':
+			flush()
+		case ch == '\'':
+			end := indexRune(runes, '\'', i+1)
+			if end < 0 {
+				return nil, errors.New("Unterminated single quote")
+			}
+			raw.WriteString(string(runes[i : end+1]))
+			value.WriteString(string(runes[i+1 : end]))
+			inWord, i = true, end
+		case ch == '"':
+			end, err := readDoubleQuoted(runes, i+1, &value)
+			if err != nil {
+				return nil, err
+			}
+			raw.WriteString(string(runes[i : end+1]))
+			inWord, i = true, end
+		case ch == '\\':
+			if i+1 >= len(runes) {
+				return nil, errors.New("Unterminated escape")
+			}
+			raw.WriteString(string(runes[i : i+2]))
+			value.WriteRune(runes[i+1])
+			inWord, i = true, i+1
+		case placeholderEnd(runes, i) > 0:
+			end := placeholderEnd(runes, i)
+			raw.WriteString(string(runes[i : end+1]))
+			value.WriteString(string(runes[i : end+1]))
+			inWord, i = true, end
+		default:
+			raw.WriteRune(ch)
+			value.WriteRune(ch)
+			inWord = true
+		}
+	}
+	flush()
+
+	return words, nil
+}
+
+func indexRune(runes []rune, ch rune, from int) int {
+	for i := from; i < len(runes); i++ {
+		if runes[i] == ch {
+			return i
+		}
+	}
+	return -1
+}
+
+// placeholderEnd returns the index of the last brace of the placeholder that starts at from, or -1 if there is none.
+// Placeholders may contain other placeholders (e.g. {{pod from "kubectl -n {{namespace}} get pod"}}).
+func placeholderEnd(runes []rune, from int) int {
+	if from+1 >= len(runes) || runes[from] != '{' || runes[from+1] != '{' {
+		return -1
+	}
+	depth := 0
+	for i := from; i+1 < len(runes); i++ {
+		switch {
+		case runes[i] == '{' && runes[i+1] == '{':
+			depth++
+			i++
+		case runes[i] == '}' && runes[i+1] == '}':
+			depth--
+			i++
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// readDoubleQuoted writes the value of a double quoted string into value and
+// returns the index of the closing quote
+func readDoubleQuoted(runes []rune, from int, value *strings.Builder) (int, error) {
+	for i := from; i < len(runes); i++ {
+		switch ch := runes[i]; {
+		case ch == '"':
+			return i, nil
+		case ch == '\\' && i+1 < len(runes) && strings.ContainsRune("$`\"\\\n", runes[i+1]):
+			value.WriteRune(runes[i+1])
+			i++
+		default:
+			value.WriteRune(ch)
+		}
+	}
+	return -1, errors.New("Unterminated double quote")
+}
+
+// split splits a command into the parts of a command tree. A flag and its value are a single part.
+// Potential ways to specify flags:
+//
+//	--flag
+//	-f
+//	--flag value
+//	--flag=value
+//	-f value
+//	-f=value
+//	-fvalue
+//
+// Everything after "--" is passed as is to the command, so each word is a part.
+func split(command string) ([]string, error) {
+	words, err := tokenize(command)
+	if err != nil {
+		return nil, err
+	}
+
+	var parts []string
+	verb := ""
+	for i, max := 0, len(words); i < max; i++ {
+		current := words[i].value
+		switch {
+		case current == "--":
+			for _, w := range words[i:] {
+				parts = append(parts, w.raw)
+			}
+			return parts, nil
+		case i+1 < max && takesValue(current, verb) && !isFlag(words[i+1].value):
+			parts = append(parts, strings.Join([]string{words[i].raw, words[i+1].raw}, " "))
+			i++
+		default:
+			if i > 0 && verb == "" && !isFlag(current) {
+				verb = current
+			}
+			parts = append(parts, words[i].raw)
+		}
+	}
+
+	return parts, nil
+}
+
+func isFlag(value string) bool {
+	return len(value) > 1 && strings.HasPrefix(value, "-")
+}
+
+func takesValue(flag, verb string) bool {
+	if !isFlag(flag) || flag == "--" || strings.Contains(flag, "=") {
+		return false
+	}
+	// Short flags with their value attached (e.g. -nfoo or -ojson) are complete
+	if !strings.HasPrefix(flag, "--") && len([]rune(flag)) > 2 {
+		return false
+	}
+	return !booleanFlags[flag] && !verbBooleanFlags[verb][flag]
+}
+
+// partArgs returns the arguments a command part represents once quotes and escapes are removed
+func partArgs(part string) []string {
+	words, err := tokenize(part)
+	if err != nil {
+		return strings.Fields(part)
+	}
+	args := make([]string, 0, len(words))
+	for _, w := range words {
+		args = append(args, w.value)
+	}
+	return args
+}
+
+// quote returns arg in a form a POSIX shell will read back as a single word
+func quote(arg string) string {
+	if arg == "" {
+		return "''"
+	}
+	safe := func(ch rune) bool {
+		return ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9' ||
+			strings.ContainsRune("@%+=:,./_-", ch)
+	}
+	for _, ch := range arg {
+		if !safe(ch) {
+			return "'" + strings.Replace(arg, "'", `'\''`, -1) + "'"
+		}
+	}
+	return arg
+}
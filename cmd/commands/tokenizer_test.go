@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenizer_Tokenize(t *testing.T) {
+	// Arrange
+	tests := []struct {
+		name     string
+		input    string
+		expected []word
+	}{
+		{"Plain words", "kubectl  get\tpod", []word{{"kubectl", "kubectl"}, {"get", "get"}, {"pod", "pod"}}},
+		{"Single quotes", "-l 'app in (a,b)'", []word{{"-l", "-l"}, {"'app in (a,b)'", "app in (a,b)"}}},
+		{"Double quotes", `--field-selector "status.phase!=Running"`, []word{{"--field-selector", "--field-selector"}, {`"status.phase!=Running"`, "status.phase!=Running"}}},
+		{"Quotes inside a word", "jsonpath='{.items[*].metadata.name}'", []word{{"jsonpath='{.items[*].metadata.name}'", "jsonpath={.items[*].metadata.name}"}}},
+		{"Escaped space", `my\ pod`, []word{{`my\ pod`, "my pod"}}},
+		{"Escapes inside double quotes", `"a \"b\" \c"`, []word{{`"a \"b\" \c"`, `a "b" \c`}}},
+		{"Empty quotes", `''`, []word{{"''", ""}}},
+		{"Placeholder with source", `logs {{pod from "kubectl -n {{ns}} get pod"}}`, []word{{"logs", "logs"}, {`{{pod from "kubectl -n {{ns}} get pod"}}`, `{{pod from "kubectl -n {{ns}} get pod"}}`}}},
+		{"Unterminated placeholder", "logs {{pod", []word{{"logs", "logs"}, {"{{pod", "{{pod"}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			result, err := tokenize(test.input)
+
+			// Assert
+			assert.Nil(t, err)
+			assert.EqualValues(t, test.expected, result)
+		})
+	}
+}
+
+func TestTokenizer_TokenizeInvalid(t *testing.T) {
+	// Arrange
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"Unterminated single quote", "kubectl get pod -l 'app=a"},
+		{"Unterminated double quote", `kubectl get pod -l "app=a`},
+		{"Unterminated escape", `kubectl get pod \`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			result, err := tokenize(test.input)
+
+			// Assert
+			assert.Nil(t, result)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestTokenizer_Split(t *testing.T) {
+	// Arrange
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"Flag with value", "kubectl -n kubeflow get pod", []string{"kubectl", "-n kubeflow", "get", "pod"}},
+		{"Flag with equals", "kubectl --namespace=kubeflow get pod", []string{"kubectl", "--namespace=kubeflow", "get", "pod"}},
+		{"Short flag with equals", "kubectl -n=kubeflow get pod", []string{"kubectl", "-n=kubeflow", "get", "pod"}},
+		{"Short flag with attached value", "kubectl -nfoo get po", []string{"kubectl", "-nfoo", "get", "po"}},
+		{"Short output flag with attached value", "kubectl get pod -ojson web", []string{"kubectl", "get", "pod", "-ojson", "web"}},
+		{"Quoted value", "kubectl get pod -l 'app in (a,b)'", []string{"kubectl", "get", "pod", "-l 'app in (a,b)'"}},
+		{"Quoted jsonpath", "kubectl get pod -o jsonpath='{.items[*].metadata.name}'", []string{"kubectl", "get", "pod", "-o jsonpath='{.items[*].metadata.name}'"}},
+		{"Boolean flag", "kubectl get --all-namespaces pods", []string{"kubectl", "get", "--all-namespaces", "pods"}},
+		{"Short boolean flag", "kubectl get -A pods", []string{"kubectl", "get", "-A", "pods"}},
+		{"Flag followed by flag", "kubectl get pod --sort-by --watch", []string{"kubectl", "get", "pod", "--sort-by", "--watch"}},
+		{"Verb specific boolean flag", "kubectl -n kubeflow logs -f my-pod", []string{"kubectl", "-n kubeflow", "logs", "-f", "my-pod"}},
+		{"Verb specific flag with value", "kubectl apply -f pod.yaml", []string{"kubectl", "apply", "-f pod.yaml"}},
+		{"Flag with placeholder", `kubectl -n {{ns from "kubectl get ns -o name"}} get pod`, []string{"kubectl", `-n {{ns from "kubectl get ns -o name"}}`, "get", "pod"}},
+		{"Double dash", "kubectl exec my-pod -- ls -la /", []string{"kubectl", "exec", "my-pod", "--", "ls", "-la", "/"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			result, err := split(test.input)
+
+			// Assert
+			assert.Nil(t, err)
+			assert.EqualValues(t, test.expected, result)
+		})
+	}
+}
+
+func TestTokenizer_PartArgs(t *testing.T) {
+	// Arrange
+	part := `--field-selector "status.phase!=Running"`
+	expected := []string{"--field-selector", "status.phase!=Running"}
+
+	// Act
+	result := partArgs(part)
+
+	// Assert
+	assert.EqualValues(t, expected, result)
+}
+
+func TestTokenizer_Quote(t *testing.T) {
+	// Arrange
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Safe", "kubeflow", "kubeflow"},
+		{"Empty", "", "''"},
+		{"Spaces", "app in (a,b)", "'app in (a,b)'"},
+		{"Single quote", "it's", `'it'\''s'`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			result := quote(test.input)
+
+			// Assert
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package commands
+
+import "strings"
+
+// ChangedLines returns which lines of the output of a command were not in its previous output,
+// e.g. to highlight them when the command runs periodically
+func ChangedLines(previous, current string) []bool {
+	seen := map[string]int{}
+	for _, line := range strings.Split(previous, "\n") {
+		seen[line]++
+	}
+
+	lines := strings.Split(current, "\n")
+	changed := make([]bool, len(lines))
+	for index, line := range lines {
+		if seen[line] > 0 {
+			seen[line]--
+			continue
+		}
+		changed[index] = true
+	}
+	return changed
+}
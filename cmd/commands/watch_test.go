@@ -0,0 +1,31 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatch_ChangedLines(t *testing.T) {
+	// Arrange
+	previous := "NAME   READY   STATUS\nweb    0/1     Pending\ndb     1/1     Running\n"
+	current := "NAME   READY   STATUS\ndb     1/1     Running\nweb    1/1     Running\ncache  1/1     Running\n"
+
+	// Act
+	result := ChangedLines(previous, current)
+
+	// Assert
+	assert.EqualValues(t, []bool{false, false, true, true, false}, result)
+}
+
+func TestWatch_ChangedLinesDuplicates(t *testing.T) {
+	// Arrange
+	previous := "a\nb"
+	current := "a\na\nb"
+
+	// Act
+	result := ChangedLines(previous, current)
+
+	// Assert
+	assert.EqualValues(t, []bool{false, true, false}, result)
+}
@@ -0,0 +1,173 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Workspaces represents the command trees of the app and the stores where they are persisted.
+// By default there is a single tree for every kube context. When commands are kept apart per context,
+// each kube context, or each profile of several contexts, has a tree and a store of its own.
+type Workspaces struct {
+	// File is the store of the single tree, or of the commands that run with no kube context.
+	// The stores of the other workspaces are next to it (e.g. commands.aks-prod.json for commands.json).
+	File   string
+	Legacy *Backup
+	Roots  []string
+	// Policy and Executor are how the commands of every tree run
+	Policy   Policy
+	Executor Executor
+	// PerContext keeps the commands of each kube context, or profile, apart
+	PerContext bool
+	// profiles are the profiles of the kube contexts that are in one, by context
+	profiles   map[string]string
+	workspaces map[string]*Workspace
+}
+
+// Workspace represents a command tree and its store
+type Workspace struct {
+	// Name is the profile or kube context of the tree. It is empty for the single tree.
+	Name   string
+	Forest *CForest
+	Store  *Store
+}
+
+// NewWorkspaces creates the workspaces of the app. Profiles are lists of kube contexts that share a tree, by name.
+func NewWorkspaces(file string, legacy *Backup, roots []string, perContext bool, profiles map[string][]string) *Workspaces {
+	workspaces := Workspaces{
+		File:       file,
+		Legacy:     legacy,
+		Roots:      roots,
+		PerContext: perContext,
+		profiles:   map[string]string{},
+		workspaces: map[string]*Workspace{},
+	}
+	for profile, contexts := range profiles {
+		for _, context := range contexts {
+			workspaces.profiles[context] = profile
+		}
+	}
+	return &workspaces
+}
+
+// Name returns the workspace of the commands of a kube context: its profile, the context itself,
+// or the single workspace when commands are not kept apart
+func (workspaces *Workspaces) Name(context string) string {
+	if !workspaces.PerContext {
+		return ""
+	}
+	if profile, ok := workspaces.profiles[context]; ok {
+		return profile
+	}
+	return context
+}
+
+// Names returns the workspaces of some kube contexts, plus the ones that are open, sorted
+func (workspaces *Workspaces) Names(contexts []string) []string {
+	seen := map[string]bool{}
+	var names []string
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	for _, context := range contexts {
+		add(workspaces.Name(context))
+	}
+	for name := range workspaces.workspaces {
+		add(name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Open returns a workspace, reading its tree from its store the first time
+func (workspaces *Workspaces) Open(name string) (*Workspace, error) {
+	if workspace, ok := workspaces.workspaces[name]; ok {
+		return workspace, nil
+	}
+
+	// Only the single tree takes the commands of the backup of older versions of the app
+	store := NewStore(workspaces.File, workspaces.Legacy)
+	if name != "" {
+		store = NewStore(workspaces.file(name), nil)
+	}
+	forest, err := store.Commands(workspaces.Roots...)
+	if err != nil {
+		return nil, err
+	}
+	forest.Policy, forest.Executor = workspaces.Policy, workspaces.Executor
+
+	workspace := Workspace{Name: name, Forest: forest, Store: store}
+	workspaces.workspaces[name] = &workspace
+	return &workspace, nil
+}
+
+// ForContext returns the workspace of the commands of a kube context.
+// When commands are kept apart, they run against that context unless their tree was set to run against another one.
+func (workspaces *Workspaces) ForContext(context string) (*Workspace, error) {
+	workspace, err := workspaces.Open(workspaces.Name(context))
+	if err != nil {
+		return nil, err
+	}
+	if workspaces.PerContext {
+		workspace.Forest.Context = context
+	}
+	return workspace, nil
+}
+
+// Opened returns the workspaces that are open, sorted by name
+func (workspaces *Workspaces) Opened() []*Workspace {
+	opened := make([]*Workspace, 0, len(workspaces.workspaces))
+	for _, workspace := range workspaces.workspaces {
+		opened = append(opened, workspace)
+	}
+	sort.Slice(opened, func(i, j int) bool {
+		return opened[i].Name < opened[j].Name
+	})
+	return opened
+}
+
+// SetCommands writes a new snapshot of the store of every workspace that is open
+func (workspaces *Workspaces) SetCommands() error {
+	for _, workspace := range workspaces.Opened() {
+		if err := workspace.Store.SetCommands(workspace.Forest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Sync updates every workspace that is open with the changes other instances of the app made to its store.
+// It returns whether any of them changed.
+func (workspaces *Workspaces) Sync() (bool, error) {
+	changed := false
+	for _, workspace := range workspaces.Opened() {
+		synced, err := workspace.Store.Sync(workspace.Forest)
+		if err != nil {
+			return false, err
+		}
+		changed = changed || synced
+	}
+	return changed, nil
+}
+
+// file returns the store file of a workspace, next to the store of the single tree
+func (workspaces *Workspaces) file(name string) string {
+	extension := filepath.Ext(workspaces.File)
+	return fmt.Sprintf("%s.%s%s", strings.TrimSuffix(workspaces.File, extension), storeFileName(name), extension)
+}
+
+// storeFileName returns a name that is safe to use in a file name in every OS
+// (e.g. arn_aws_eks_eu-west-1_123_cluster_prod for arn:aws:eks:eu-west-1:123:cluster/prod)
+func storeFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '.' {
+			return r
+		}
+		return '_'
+	}, name)
+}
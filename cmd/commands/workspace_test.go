@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkspaces_Name(t *testing.T) {
+	// Arrange
+	profiles := map[string][]string{"prod": {"aks-prod-eu", "aks-prod-us"}}
+	perContext := NewWorkspaces("commands.json", nil, nil, true, profiles)
+	single := NewWorkspaces("commands.json", nil, nil, false, profiles)
+
+	tests := []struct {
+		context  string
+		expected string
+	}{
+		{"aks-prod-eu", "prod"},
+		{"aks-prod-us", "prod"},
+		{"kind-superk", "kind-superk"},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.context, func(t *testing.T) {
+			// Act
+			result := perContext.Name(test.context)
+			singleResult := single.Name(test.context)
+
+			// Assert
+			assert.Equal(t, test.expected, result)
+			assert.Equal(t, "", singleResult)
+		})
+	}
+}
+
+func TestWorkspaces_Names(t *testing.T) {
+	// Arrange
+	workspaces := NewWorkspaces("commands.json", nil, nil, true, map[string][]string{"prod": {"aks-prod-eu", "aks-prod-us"}})
+
+	// Act
+	result := workspaces.Names([]string{"kind-superk", "aks-prod-us", "aks-prod-eu"})
+
+	// Assert
+	assert.EqualValues(t, []string{"kind-superk", "prod"}, result)
+}
+
+func TestWorkspaces_ForContext(t *testing.T) {
+	// Arrange
+	dir, err := ioutil.TempDir("", "superk_test_workspaces_")
+	assert.Nil(t, err)
+	file := filepath.Join(dir, "commands.json")
+	workspaces := NewWorkspaces(file, nil, []string{"kubectl"}, true, map[string][]string{"prod": {"aks-prod-eu"}})
+
+	// Act
+	dev, err := workspaces.ForContext("arn:aws:eks:eu-west-1:123:cluster/dev")
+	assert.Nil(t, err)
+	err = dev.Forest.MergeCommand("kubectl get pod")
+	assert.Nil(t, err)
+	prod, err := workspaces.ForContext("aks-prod-eu")
+	assert.Nil(t, err)
+	err = prod.Forest.MergeCommand("kubectl get node")
+	assert.Nil(t, err)
+	err = workspaces.SetCommands()
+	assert.Nil(t, err)
+
+	// Assert
+	assert.Equal(t, filepath.Join(dir, "commands.arn_aws_eks_eu-west-1_123_cluster_dev.json"), dev.Store.File)
+	assert.Equal(t, filepath.Join(dir, "commands.prod.json"), prod.Store.File)
+	assert.Equal(t, "aks-prod-eu", prod.Forest.Context)
+	assert.EqualValues(t, []string{"kubectl", "--context", "aks-prod-eu", "get", "node"}, prod.Forest.GetCmd(3).ExecArgs())
+	assert.Len(t, workspaces.Opened(), 2)
+
+	reopened := NewWorkspaces(file, nil, []string{"kubectl"}, true, nil)
+	result, err := reopened.Open("prod")
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"kubectl get node"}, result.Forest.Serialize())
+	_, err = os.Stat(file)
+	assert.True(t, os.IsNotExist(err))
+
+	// Cleanup
+	err = os.RemoveAll(dir)
+	assert.Nil(t, err)
+}
+
+func TestWorkspaces_Single(t *testing.T) {
+	// Arrange
+	dir, err := ioutil.TempDir("", "superk_test_workspaces_")
+	assert.Nil(t, err)
+	file := filepath.Join(dir, "commands.json")
+	workspaces := NewWorkspaces(file, nil, []string{"kubectl"}, false, nil)
+
+	// Act
+	dev, err := workspaces.ForContext("aks-dev")
+	assert.Nil(t, err)
+	prod, err := workspaces.ForContext("aks-prod")
+	assert.Nil(t, err)
+
+	// Assert
+	assert.Equal(t, dev, prod)
+	assert.Equal(t, file, dev.Store.File)
+	assert.Equal(t, "", dev.Forest.Context)
+
+	// Cleanup
+	err = os.RemoveAll(dir)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,200 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"superk/cmd/commands"
+	"time"
+)
+
+const (
+	appName              string        = "superk"
+	fileName             string        = "config.json"
+	defaultSyncInterval  time.Duration = 2 * time.Second
+	defaultRetryBackoff  time.Duration = time.Second
+	defaultWatchInterval time.Duration = 2 * time.Second
+	defaultConcurrency   int           = 4
+)
+
+// Config represents the user settings of the app
+type Config struct {
+	// Roots are the binaries whose commands can be added to the tree. The first one is the default.
+	Roots []string `json:"roots,omitempty"`
+	// SyncInterval is how often the app checks for commands added by other instances of the app. 0 disables it.
+	SyncInterval Duration `json:"syncInterval"`
+	// Timeout is how long commands may take, unless they have a timeout of their own. 0 means there is no limit.
+	Timeout Duration `json:"timeout"`
+	// Retry is when and how commands that fail run again
+	Retry Retry `json:"retry"`
+	// WatchInterval is how often commands that are watched run again, unless user chooses another interval
+	WatchInterval Duration `json:"watchInterval"`
+	// Concurrency is how many commands may run at once when all the commands in a subtree run
+	Concurrency int `json:"concurrency"`
+	// Guard is which commands need confirmation before they run, besides the ones that change the cluster
+	Guard Guard `json:"guard"`
+	// Contexts are the settings of each kube context, by name
+	Contexts map[string]Context `json:"contexts,omitempty"`
+	// TreePerContext keeps the commands of each kube context in a tree and a store of their own
+	TreePerContext bool `json:"treePerContext"`
+	// Profiles are kube contexts that share a tree when trees are kept per context, by profile name
+	// (e.g. "prod": ["aks-prod-eu", "aks-prod-us"])
+	Profiles map[string][]string `json:"profiles,omitempty"`
+}
+
+// Context represents the settings of a kube context
+type Context struct {
+	// ReadOnly refuses to run commands that change the cluster against the context
+	ReadOnly bool `json:"readOnly"`
+}
+
+// Guard represents which commands need confirmation before they run. Mutating commands (e.g. "delete") always do,
+// unless they are allowed.
+type Guard struct {
+	// Allow are mutating commands that run without confirmation (e.g. "apply")
+	Allow []string `json:"allow,omitempty"`
+	// Deny are commands that need confirmation even if they are not mutating (e.g. "-n production").
+	// Commands that are both allowed and denied need confirmation.
+	Deny []string `json:"deny,omitempty"`
+}
+
+// Retry represents when and how commands that fail run again
+type Retry struct {
+	// MaxAttempts is the maximum number of times a command runs. Commands don't run again if it is 0 or 1.
+	MaxAttempts int `json:"maxAttempts"`
+	// Backoff is how long to wait before the second attempt. The wait doubles after every attempt.
+	Backoff Duration `json:"backoff"`
+	// Patterns are regular expressions that match the output of the failures worth retrying
+	Patterns []string `json:"patterns,omitempty"`
+	// ExitCodes are the exit codes of the failures worth retrying.
+	// With no patterns and no exit codes, every failure is worth retrying.
+	ExitCodes []int `json:"exitCodes,omitempty"`
+}
+
+// NewConfig creates a config with default settings
+func NewConfig() *Config {
+	return &Config{
+		Roots:         commands.DefaultRoots,
+		SyncInterval:  Duration{defaultSyncInterval},
+		Retry:         Retry{MaxAttempts: 1, Backoff: Duration{defaultRetryBackoff}},
+		WatchInterval: Duration{defaultWatchInterval},
+		Concurrency:   defaultConcurrency,
+	}
+}
+
+// Policy returns how commands run according to the settings
+func (config *Config) Policy() (*commands.Policy, error) {
+	retry, err := commands.NewRetryPolicy(
+		config.Retry.MaxAttempts,
+		config.Retry.Backoff.Duration,
+		config.Retry.Patterns,
+		config.Retry.ExitCodes)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid retry pattern: %v", err)
+	}
+	return &commands.Policy{Timeout: config.Timeout.Duration, Retry: retry}, nil
+}
+
+// Guardrails returns which commands need confirmation before they run according to the settings
+func (config *Config) Guardrails() (*commands.Guard, error) {
+	return commands.NewGuard(config.Guard.Allow, config.Guard.Deny)
+}
+
+// ReadOnlyContexts returns the kube contexts where commands that change the cluster do not run, sorted by name
+func (config *Config) ReadOnlyContexts() []string {
+	var contexts []string
+	for name, context := range config.Contexts {
+		if context.ReadOnly {
+			contexts = append(contexts, name)
+		}
+	}
+	sort.Strings(contexts)
+	return contexts
+}
+
+// validateProfiles checks that every profile has a name and that no kube context is in two profiles
+func (config *Config) validateProfiles() error {
+	names := make([]string, 0, len(config.Profiles))
+	for profile := range config.Profiles {
+		names = append(names, profile)
+	}
+	sort.Strings(names)
+
+	profiles := map[string]string{}
+	for _, profile := range names {
+		if profile == "" {
+			return errors.New("Profiles must have a name")
+		}
+		for _, context := range config.Profiles[profile] {
+			if other, ok := profiles[context]; ok && other != profile {
+				return fmt.Errorf("Context %s is in profiles %s and %s", context, other, profile)
+			}
+			profiles[context] = profile
+		}
+	}
+	return nil
+}
+
+// DefaultPath returns the path of the config file in the user config directory
+// (e.g. ~/.config/superk/config.json)
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, appName, fileName), nil
+}
+
+// DataDir returns the directory where the app keeps its data, following the XDG Base Directory spec
+// (e.g. ~/.local/share/superk)
+func DataDir() (string, error) {
+	dir := os.Getenv("XDG_DATA_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dir, appName), nil
+}
+
+// Load reads the config file. Missing settings, or a missing file, get default settings.
+func Load(path string) (*Config, error) {
+	config := NewConfig()
+
+	bytes, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(bytes, config); err != nil {
+		return nil, err
+	}
+	if len(config.Roots) == 0 {
+		config.Roots = commands.DefaultRoots
+	}
+	if _, err := config.Policy(); err != nil {
+		return nil, err
+	}
+	if _, err := config.Guardrails(); err != nil {
+		return nil, err
+	}
+	if config.WatchInterval.Duration <= 0 {
+		return nil, errors.New("Watch interval must be positive")
+	}
+	if config.Concurrency < 1 {
+		return nil, errors.New("Concurrency must be at least 1")
+	}
+	if err := config.validateProfiles(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
@@ -0,0 +1,357 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"superk/cmd/commands"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_LoadMissing(t *testing.T) {
+	// Arrange
+	path := filepath.Join(os.TempDir(), "superk_test_missing_config.json")
+
+	// Act
+	config, err := Load(path)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.EqualValues(t, commands.DefaultRoots, config.Roots)
+}
+
+func TestConfig_Load(t *testing.T) {
+	// Arrange
+	path, err := writeTmpConfig(`{"roots": ["kubectl", "helm"]}`)
+	assert.Nil(t, err)
+
+	// Act
+	config, err := Load(path)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"kubectl", "helm"}, config.Roots)
+
+	// Cleanup
+	err = os.Remove(path)
+	assert.Nil(t, err)
+}
+
+func TestConfig_LoadDefaults(t *testing.T) {
+	// Arrange
+	path, err := writeTmpConfig(`{}`)
+	assert.Nil(t, err)
+
+	// Act
+	config, err := Load(path)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.EqualValues(t, commands.DefaultRoots, config.Roots)
+	assert.Equal(t, 2*time.Second, config.SyncInterval.Duration)
+	assert.Equal(t, time.Duration(0), config.Timeout.Duration)
+	assert.Equal(t, 1, config.Retry.MaxAttempts)
+	assert.Equal(t, 2*time.Second, config.WatchInterval.Duration)
+	assert.Equal(t, 4, config.Concurrency)
+
+	// Cleanup
+	err = os.Remove(path)
+	assert.Nil(t, err)
+}
+
+func TestConfig_LoadSyncInterval(t *testing.T) {
+	// Arrange
+	tests := []struct {
+		name     string
+		input    string
+		expected time.Duration
+	}{
+		{"Seconds", `{"syncInterval": "5s"}`, 5 * time.Second},
+		{"Disabled", `{"syncInterval": "0s"}`, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path, err := writeTmpConfig(test.input)
+			assert.Nil(t, err)
+
+			// Act
+			config, err := Load(path)
+
+			// Assert
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, config.SyncInterval.Duration)
+
+			// Cleanup
+			err = os.Remove(path)
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestConfig_LoadInvalidSyncInterval(t *testing.T) {
+	// Arrange
+	path, err := writeTmpConfig(`{"syncInterval": "soon"}`)
+	assert.Nil(t, err)
+
+	// Act
+	config, err := Load(path)
+
+	// Assert
+	assert.Nil(t, config)
+	assert.NotNil(t, err)
+
+	// Cleanup
+	err = os.Remove(path)
+	assert.Nil(t, err)
+}
+
+func TestConfig_LoadWatchInterval(t *testing.T) {
+	// Arrange
+	tests := []struct {
+		name  string
+		input string
+		valid bool
+	}{
+		{"Seconds", `{"watchInterval": "5s"}`, true},
+		{"Zero", `{"watchInterval": "0s"}`, false},
+		{"Negative", `{"watchInterval": "-1s"}`, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path, err := writeTmpConfig(test.input)
+			assert.Nil(t, err)
+
+			// Act
+			config, err := Load(path)
+
+			// Assert
+			if test.valid {
+				assert.Nil(t, err)
+				assert.Equal(t, 5*time.Second, config.WatchInterval.Duration)
+			} else {
+				assert.Nil(t, config)
+				assert.NotNil(t, err)
+			}
+
+			// Cleanup
+			err = os.Remove(path)
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestConfig_LoadInvalidConcurrency(t *testing.T) {
+	// Arrange
+	path, err := writeTmpConfig(`{"concurrency": 0}`)
+	assert.Nil(t, err)
+
+	// Act
+	config, err := Load(path)
+
+	// Assert
+	assert.Nil(t, config)
+	assert.NotNil(t, err)
+
+	// Cleanup
+	err = os.Remove(path)
+	assert.Nil(t, err)
+}
+
+func TestConfig_LoadPolicy(t *testing.T) {
+	// Arrange
+	path, err := writeTmpConfig(`{
+		"timeout": "30s",
+		"retry": {"maxAttempts": 3, "backoff": "500ms", "patterns": ["connection refused"], "exitCodes": [1]}
+	}`)
+	assert.Nil(t, err)
+
+	// Act
+	config, err := Load(path)
+	assert.Nil(t, err)
+	policy, err := config.Policy()
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 30*time.Second, policy.Timeout)
+	assert.Equal(t, 3, policy.Retry.MaxAttempts)
+	assert.Equal(t, 500*time.Millisecond, policy.Retry.Backoff)
+	assert.Len(t, policy.Retry.Patterns, 1)
+	assert.EqualValues(t, []int{1}, policy.Retry.ExitCodes)
+
+	// Cleanup
+	err = os.Remove(path)
+	assert.Nil(t, err)
+}
+
+func TestConfig_LoadInvalidRetryPattern(t *testing.T) {
+	// Arrange
+	path, err := writeTmpConfig(`{"retry": {"maxAttempts": 3, "patterns": ["(unclosed"]}}`)
+	assert.Nil(t, err)
+
+	// Act
+	config, err := Load(path)
+
+	// Assert
+	assert.Nil(t, config)
+	assert.NotNil(t, err)
+
+	// Cleanup
+	err = os.Remove(path)
+	assert.Nil(t, err)
+}
+
+func TestConfig_LoadGuard(t *testing.T) {
+	// Arrange
+	path, err := writeTmpConfig(`{"guard": {"allow": ["apply"], "deny": ["-n production"]}}`)
+	assert.Nil(t, err)
+
+	// Act
+	config, err := Load(path)
+	assert.Nil(t, err)
+	guard, err := config.Guardrails()
+
+	// Assert
+	assert.Nil(t, err)
+	assert.False(t, guard.Guards([]string{"kubectl", "apply", "-f", "app.yaml"}))
+	assert.True(t, guard.Guards([]string{"kubectl", "delete", "pod", "nginx"}))
+	assert.True(t, guard.Guards([]string{"kubectl", "get", "pod", "--namespace=production"}))
+
+	// Cleanup
+	err = os.Remove(path)
+	assert.Nil(t, err)
+}
+
+func TestConfig_LoadInvalidGuard(t *testing.T) {
+	// Arrange
+	path, err := writeTmpConfig(`{"guard": {"deny": ["delete 'unclosed"]}}`)
+	assert.Nil(t, err)
+
+	// Act
+	config, err := Load(path)
+
+	// Assert
+	assert.Nil(t, config)
+	assert.NotNil(t, err)
+
+	// Cleanup
+	err = os.Remove(path)
+	assert.Nil(t, err)
+}
+
+func TestConfig_LoadContexts(t *testing.T) {
+	// Arrange
+	path, err := writeTmpConfig(`{"contexts": {"aks-prod": {"readOnly": true}, "aks-dev": {}, "aks-live": {"readOnly": true}}}`)
+	assert.Nil(t, err)
+
+	// Act
+	config, err := Load(path)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"aks-live", "aks-prod"}, config.ReadOnlyContexts())
+
+	// Cleanup
+	err = os.Remove(path)
+	assert.Nil(t, err)
+}
+
+func TestConfig_LoadProfiles(t *testing.T) {
+	// Arrange
+	path, err := writeTmpConfig(`{"treePerContext": true, "profiles": {"prod": ["aks-prod-eu", "aks-prod-us"]}}`)
+	assert.Nil(t, err)
+
+	// Act
+	config, err := Load(path)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.True(t, config.TreePerContext)
+	assert.EqualValues(t, map[string][]string{"prod": {"aks-prod-eu", "aks-prod-us"}}, config.Profiles)
+
+	// Cleanup
+	err = os.Remove(path)
+	assert.Nil(t, err)
+}
+
+func TestConfig_LoadInvalidProfiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"context in two profiles", `{"profiles": {"prod": ["aks-prod"], "live": ["aks-prod"]}}`},
+		{"profile without name", `{"profiles": {"": ["aks-prod"]}}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Arrange
+			path, err := writeTmpConfig(test.content)
+			assert.Nil(t, err)
+
+			// Act
+			config, err := Load(path)
+
+			// Assert
+			assert.Nil(t, config)
+			assert.NotNil(t, err)
+
+			// Cleanup
+			err = os.Remove(path)
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestConfig_LoadInvalid(t *testing.T) {
+	// Arrange
+	path, err := writeTmpConfig(`{"roots": `)
+	assert.Nil(t, err)
+
+	// Act
+	config, err := Load(path)
+
+	// Assert
+	assert.Nil(t, config)
+	assert.NotNil(t, err)
+
+	// Cleanup
+	err = os.Remove(path)
+	assert.Nil(t, err)
+}
+
+func TestConfig_DataDir(t *testing.T) {
+	// Arrange
+	previous := os.Getenv("XDG_DATA_HOME")
+	err := os.Setenv("XDG_DATA_HOME", "/tmp/data")
+	assert.Nil(t, err)
+
+	// Act
+	dir, err := DataDir()
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "/tmp/data/superk", dir)
+
+	// Cleanup
+	err = os.Setenv("XDG_DATA_HOME", previous)
+	assert.Nil(t, err)
+}
+
+func writeTmpConfig(content string) (string, error) {
+	tmpFile, err := ioutil.TempFile(os.TempDir(), "superk_test_config_")
+	if err != nil {
+		return "", err
+	}
+	if _, err := tmpFile.WriteString(content); err != nil {
+		return "", err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return "", err
+	}
+	return tmpFile.Name(), nil
+}
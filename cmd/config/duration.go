@@ -0,0 +1,31 @@
+package config
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Duration is a time.Duration that is written in config files as a string (e.g. "2s", "1m30s")
+type Duration struct {
+	time.Duration
+}
+
+// UnmarshalJSON reads a duration from a string like "2s"
+func (duration *Duration) UnmarshalJSON(bytes []byte) error {
+	var value string
+	if err := json.Unmarshal(bytes, &value); err != nil {
+		return err
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	duration.Duration = parsed
+	return nil
+}
+
+// MarshalJSON writes a duration as a string like "2s"
+func (duration Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(duration.String())
+}
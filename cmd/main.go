@@ -1,58 +1,258 @@
 package main
 
 import (
+	"flag"
+	"io/ioutil"
 	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
 	"superk/cmd/commands"
+	"superk/cmd/config"
 	"superk/cmd/widgets"
+	"syscall"
+	"time"
 
 	"github.com/jroimartin/gocui"
 )
 
 const (
-	backupName string = "superk_backup"
+	backupName       string        = "superk_backup"
+	storeName        string        = "commands.json"
+	auditName        string        = "audit.jsonl"
+	autosaveInterval time.Duration = time.Minute
+)
+
+var (
+	configPath = flag.String("config", "", "path of the config file (default <user config dir>/superk/config.json)")
+	roots      = flag.String("roots", "", "comma-separated list of binaries whose commands can be added, the first one is the default (default from config file)")
+	readOnly   = flag.Bool("read-only", false, "refuse to run commands that change the cluster, in every kube context")
+	fakeDir    = flag.String("fake-cluster", "", "directory of fixtures to answer commands with, instead of running them (for demos)")
 )
 
 func main() {
+	flag.Parse()
 
-	commands, err := loadCommandsFromBackup()
+	settings, err := loadConfig()
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	if flag.NArg() > 0 {
+		if err := runSubcommand(flag.Arg(0), flag.Args()[1:], settings); err != nil {
+			log.Fatalln(err)
+		}
+		return
+	}
+
+	dir, err := dataDir()
 	if err != nil {
 		log.Panicln(err)
 	}
-	defer backupCommands(commands)
+	if *fakeDir != "" {
+		defer os.RemoveAll(dir)
+	}
+	// The data of a fake cluster is removed even if the app fails to start
+	fail := func(err error) {
+		if *fakeDir != "" {
+			os.RemoveAll(dir)
+		}
+		log.Panicln(err)
+	}
+	workspaces := createWorkspaces(settings, dir)
+	policy, err := settings.Policy()
+	if err != nil {
+		fail(err)
+	}
+	workspaces.Policy = *policy
+	// Commands that run against a fake cluster are audited in the real log too, where they are marked as fake
+	auditDir, err := config.DataDir()
+	if err != nil {
+		fail(err)
+	}
+	auditLog := createAuditLog(auditDir)
+	executor, readOnly, err := createExecutor(settings, auditLog)
+	if err != nil {
+		fail(err)
+	}
+	workspaces.Executor = executor
+
+	// The tree shown first is the one of the current context of the kubeconfig
+	workspace, err := workspaces.ForContext(commands.KubeContext(nil))
+	if err != nil {
+		fail(err)
+	}
+	defer storeCommands(workspaces)
 
 	g, err := createNewGui()
 	if err != nil {
-		log.Panicln(err)
+		fail(err)
 	}
 	defer g.Close()
 
-	widgets := createWidgets(commands)
+	widgets := createWidgets(workspace.Forest)
+	widgets.Tree().SetWorkspaces(workspaces, workspace)
+	widgets.Tree().SetWatchInterval(settings.WatchInterval.Duration)
+	widgets.Tree().SetConcurrency(settings.Concurrency)
+	guard, err := settings.Guardrails()
+	if err != nil {
+		fail(err)
+	}
+	widgets.Tree().SetGuard(guard)
+	widgets.Status().SetReadOnly(readOnly)
+	widgets.Audit().SetAuditLog(auditLog)
 
 	setGuiManager(g, widgets.MainScreen())
 
+	handleSignals(g)
+	autosave(g, workspaces, widgets)
+	syncStore(g, workspaces, widgets, settings.SyncInterval.Duration)
+
 	if err := setGlobalKeybindings(g, widgets); err != nil {
-		log.Panicln(err)
+		fail(err)
 	}
 
 	if err := setWidgetKeybindings(g, widgets); err != nil {
-		log.Panicln(err)
+		fail(err)
 	}
 
 	if err := mainLoop(g); err != nil {
-		log.Panicln(err)
+		fail(err)
+	}
+}
+
+func loadConfig() (*config.Config, error) {
+	path := *configPath
+	if path == "" {
+		defaultPath, err := config.DefaultPath()
+		if err != nil {
+			return nil, err
+		}
+		path = defaultPath
+	}
+
+	settings, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if *roots != "" {
+		settings.Roots = strings.Split(*roots, ",")
 	}
+	return settings, nil
 }
 
-func loadCommandsFromBackup() (*commands.CTree, error) {
-	return commands.NewBackup(backupName).Commands()
+// dataDir returns the directory where the stores are kept. Commands that run against a fake cluster
+// are kept apart from the real ones, in a temporary directory.
+func dataDir() (string, error) {
+	if *fakeDir == "" {
+		return config.DataDir()
+	}
+	return ioutil.TempDir("", "superk-fake")
+}
+
+// createWorkspaces creates the command trees and the stores where they are persisted in a directory:
+// a single tree, or a tree per kube context or profile.
+// Commands in the backup of older versions of the app are migrated to the single tree, unless the cluster is fake.
+func createWorkspaces(settings *config.Config, dir string) *commands.Workspaces {
+	var legacy *commands.Backup
+	if *fakeDir == "" {
+		legacy = commands.NewBackup(backupName)
+	}
+	file := filepath.Join(dir, storeName)
+	return commands.NewWorkspaces(file, legacy, settings.Roots, settings.TreePerContext, settings.Profiles)
+}
+
+// createStore creates the store of the tree of the current kube context
+func createStore(settings *config.Config) (*commands.Store, error) {
+	dir, err := config.DataDir()
+	if err != nil {
+		return nil, err
+	}
+	workspace, err := createWorkspaces(settings, dir).ForContext(commands.KubeContext(nil))
+	if err != nil {
+		return nil, err
+	}
+	return workspace.Store, nil
 }
 
-func backupCommands(commandTree *commands.CTree) {
-	if err := commands.NewBackup(backupName).SetCommands(commandTree); err != nil {
+// createExecutor creates what runs commands: a fake cluster if there is one, or else the binaries installed in the machine.
+// Either way, commands that change the cluster do not run against read-only kube contexts, and every command is audited
+// (marked as fake, if the cluster is fake).
+// It also returns the part of it that refuses to run commands.
+func createExecutor(settings *config.Config, auditLog *commands.AuditLog) (commands.Executor, *commands.ReadOnlyExecutor, error) {
+	refuser := commands.ReadOnlyExecutor{All: *readOnly, Contexts: settings.ReadOnlyContexts()}
+	if *fakeDir != "" {
+		fake, err := commands.NewFakeExecutor(*fakeDir)
+		if err != nil {
+			return nil, nil, err
+		}
+		refuser.Executor = fake
+	}
+	return &commands.AuditExecutor{Executor: &refuser, Log: auditLog, Fake: *fakeDir != ""}, &refuser, nil
+}
+
+// createAuditLog creates the log in a directory where every command that runs is recorded
+func createAuditLog(dir string) *commands.AuditLog {
+	return commands.NewAuditLog(filepath.Join(dir, auditName))
+}
+
+func storeCommands(workspaces *commands.Workspaces) {
+	if err := workspaces.SetCommands(); err != nil {
 		log.Panicln(err)
 	}
 }
 
+// autosave writes a new snapshot of the stores periodically, so their journals of changes don't grow forever
+func autosave(g *gocui.Gui, workspaces *commands.Workspaces, allWidgets *widgets.Widgets) {
+	go func() {
+		for range time.Tick(autosaveInterval) {
+			// Changes to the tree happen in the main loop, so the snapshot is written there too
+			g.Update(func(g *gocui.Gui) error {
+				if err := workspaces.SetCommands(); err != nil {
+					return allWidgets.Msg().ShowMsg(g, "Autosave error", err.Error())
+				}
+				return nil
+			})
+		}
+	}()
+}
+
+// syncStore periodically merges the commands that other instances of the app add to the stores
+func syncStore(g *gocui.Gui, workspaces *commands.Workspaces, allWidgets *widgets.Widgets, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		for range time.Tick(interval) {
+			g.Update(func(g *gocui.Gui) error {
+				changed, err := workspaces.Sync()
+				if err != nil {
+					return allWidgets.Msg().ShowMsg(g, "Sync error", err.Error())
+				}
+				if changed {
+					_, err = allWidgets.Tree().Refresh(g)
+				}
+				return err
+			})
+		}
+	}()
+}
+
+// handleSignals exits the app gracefully when it is terminated or its terminal is closed,
+// so the terminal is restored and the commands are stored
+func handleSignals(g *gocui.Gui) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	go func() {
+		<-signals
+		g.Update(func(g *gocui.Gui) error {
+			return gocui.ErrQuit
+		})
+	}()
+}
+
 func createNewGui() (*gocui.Gui, error) {
 	g, err := gocui.NewGui(gocui.OutputNormal)
 	if err != nil {
@@ -63,10 +263,13 @@ func createNewGui() (*gocui.Gui, error) {
 	g.Cursor = true
 	g.Mouse = true
 
+	// Let popups be closed with Esc
+	g.InputEsc = true
+
 	return g, nil
 }
 
-func createWidgets(commands *commands.CTree) *widgets.Widgets {
+func createWidgets(commands *commands.CForest) *widgets.Widgets {
 	return widgets.NewWidgets(commands)
 }
 
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"superk/cmd/commands"
+	"superk/cmd/config"
+	"text/tabwriter"
+	"time"
+)
+
+// Subcommands that can be run from the command line instead of starting the app
+var subcommands = map[string]func(settings *config.Config, args []string) error{
+	"migrate": migrate,
+	"import":  importHistory,
+	"export":  export,
+	"audit":   audit,
+}
+
+// Layouts of the times accepted by the audit subcommand
+var auditTimeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"}
+
+func runSubcommand(name string, args []string, settings *config.Config) error {
+	subcommand, ok := subcommands[name]
+	if !ok {
+		var names []string
+		for name := range subcommands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("Unknown subcommand %q. Available subcommands: %s", name, strings.Join(names, ", "))
+	}
+	return subcommand(settings, args)
+}
+
+// migrate rewrites the store with the canonical form of its commands
+func migrate(settings *config.Config, args []string) error {
+	store, err := createStore(settings)
+	if err != nil {
+		return err
+	}
+	before, after, err := store.Migrate(settings.Roots...)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Migrated %d commands to %d canonical commands in %s\n", before, after, store.File)
+	return nil
+}
+
+// importHistory adds the commands in shell history files to the store
+func importHistory(settings *config.Config, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "show the commands that would be imported without importing them")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: superk import [-dry-run] [history file...] (default: bash, zsh and fish history files)")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	files := flags.Args()
+	if len(files) == 0 {
+		files = commands.DefaultHistoryFiles()
+	}
+	if len(files) == 0 {
+		return errors.New("No history files found")
+	}
+
+	var lines []string
+	for _, file := range files {
+		history, err := commands.ReadHistory(file)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, history...)
+	}
+
+	store, err := createStore(settings)
+	if err != nil {
+		return err
+	}
+	forest, err := store.Commands(settings.Roots...)
+	if err != nil {
+		return err
+	}
+
+	preview := forest.PreviewImport(lines)
+	for _, command := range preview {
+		fmt.Println(command)
+	}
+	if *dryRun {
+		fmt.Printf("Would import %d commands from %s\n", len(preview), strings.Join(files, ", "))
+		return nil
+	}
+
+	if err := forest.Import(preview); err != nil {
+		return err
+	}
+	if err := store.SetCommands(forest); err != nil {
+		return err
+	}
+	fmt.Printf("Imported %d commands from %s\n", len(preview), strings.Join(files, ", "))
+	return nil
+}
+
+// audit shows the commands that ran, as recorded in the audit log, oldest first
+func audit(settings *config.Config, args []string) error {
+	flags := flag.NewFlagSet("audit", flag.ContinueOnError)
+	since := flags.Duration("since", 0, "show only the commands that started within this long (e.g. 24h)")
+	from := flags.String("from", "", "show only the commands that started from this time on (e.g. 2006-01-02 15:04)")
+	to := flags.String("to", "", "show only the commands that started up to this time (e.g. 2006-01-02 15:04)")
+	verb := flags.String("verb", "", "show only the commands with this verb (e.g. delete)")
+	context := flags.String("context", "", "show only the commands that ran against this kube context")
+	asJSON := flags.Bool("json", false, "show the entries as JSON lines, as they are in the audit log")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	filter := commands.AuditFilter{Verb: *verb, Context: *context}
+	var err error
+	if filter.From, err = parseAuditTime(*from); err != nil {
+		return err
+	}
+	if filter.To, err = parseAuditTime(*to); err != nil {
+		return err
+	}
+	if *since > 0 {
+		filter.From = time.Now().Add(-*since)
+	}
+
+	dir, err := config.DataDir()
+	if err != nil {
+		return err
+	}
+	entries, err := createAuditLog(dir).Query(filter)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		for _, entry := range entries {
+			if err := encoder.Encode(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "STARTED\tDURATION\tEXIT\tUSER\tCONTEXT\tNAMESPACE\tCOMMAND")
+	for _, entry := range entries {
+		exit := fmt.Sprint(entry.ExitCode)
+		if entry.Error != "" {
+			exit = "error"
+		}
+		command := entry.Command
+		if entry.Fake {
+			command += " (fake)"
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			entry.StartTime.Local().Format("2006-01-02 15:04:05"),
+			entry.EndTime.Sub(entry.StartTime).Round(time.Millisecond),
+			exit,
+			entry.User,
+			orDash(entry.Context),
+			orDash(entry.Namespace),
+			command)
+	}
+	return writer.Flush()
+}
+
+// parseAuditTime parses a time in any of the layouts accepted by the audit subcommand, in local time
+func parseAuditTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range auditTimeLayouts {
+		if parsed, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("Invalid time %q. Use a time like %q", value, "2006-01-02 15:04")
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// export writes the commands in the store in a format that can be shared
+func export(settings *config.Config, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := flags.String("format", "markdown", fmt.Sprintf("export format: %s", strings.Join(commands.ExporterNames(), ", ")))
+	output := flags.String("o", "", "file to write to (default stdout)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	exporter := commands.GetExporter(*format)
+	if exporter == nil {
+		return fmt.Errorf("Unknown export format %q. Available formats: %s", *format, strings.Join(commands.ExporterNames(), ", "))
+	}
+
+	store, err := createStore(settings)
+	if err != nil {
+		return err
+	}
+	forest, err := store.Commands(settings.Roots...)
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		fmt.Print(exporter.Export(forest))
+		return nil
+	}
+	return exporter.ExportFile(forest, *output)
+}
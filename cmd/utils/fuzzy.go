@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// FuzzyMatch returns whether all the characters of a pattern appear in a text in the same order, ignoring case,
+// and a score that is higher when they are consecutive or at the start of words
+// Example: "wbpd" matches "web-pod"
+func FuzzyMatch(pattern, text string) (int, bool) {
+	patternRunes := []rune(strings.ToLower(pattern))
+	textRunes := []rune(strings.ToLower(text))
+
+	score, next, previous := 0, 0, -2
+	for index := 0; index < len(textRunes) && next < len(patternRunes); index++ {
+		if textRunes[index] != patternRunes[next] {
+			continue
+		}
+		score++
+		if index == previous+1 {
+			score += 2
+		}
+		if index == 0 || !unicode.IsLetter(textRunes[index-1]) && !unicode.IsDigit(textRunes[index-1]) {
+			score += 2
+		}
+		previous = index
+		next++
+	}
+	return score, next == len(patternRunes)
+}
+
+// FuzzyFilter returns the items that match a pattern, best matches first.
+// Items that match equally well keep their order.
+func FuzzyFilter(pattern string, items []string) []string {
+	type match struct {
+		item  string
+		score int
+	}
+	var matches []match
+	for _, item := range items {
+		if score, ok := FuzzyMatch(pattern, item); ok {
+			matches = append(matches, match{item, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+
+	filtered := make([]string, 0, len(matches))
+	for _, current := range matches {
+		filtered = append(filtered, current.item)
+	}
+	return filtered
+}
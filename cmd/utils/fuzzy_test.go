@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuzzy_FuzzyMatch(t *testing.T) {
+	// Arrange
+	tests := []struct {
+		name     string
+		pattern  string
+		text     string
+		expected bool
+	}{
+		{"Empty pattern", "", "web-pod", true},
+		{"Substring", "pod", "web-pod", true},
+		{"Subsequence", "wbpd", "web-pod", true},
+		{"Ignore case", "WEB", "web-pod", true},
+		{"Wrong order", "dop", "web-pod", false},
+		{"Longer pattern", "web-pods", "web-pod", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			_, result := FuzzyMatch(test.pattern, test.text)
+
+			// Assert
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func TestFuzzy_FuzzyFilter(t *testing.T) {
+	// Arrange
+	items := []string{"pod/db-0", "pod/web-1", "pod/worker-7b", "pod/web-2"}
+
+	// Act
+	all := FuzzyFilter("", items)
+	filtered := FuzzyFilter("web", items)
+
+	// Assert
+	assert.EqualValues(t, items, all)
+	assert.EqualValues(t, []string{"pod/web-1", "pod/web-2", "pod/worker-7b"}, filtered)
+}
@@ -0,0 +1,282 @@
+package widgets
+
+import (
+	"fmt"
+	"strings"
+	"superk/cmd/commands"
+	"time"
+
+	"github.com/jroimartin/gocui"
+)
+
+const (
+	// AuditWidgetName is the name of this widget
+	AuditWidgetName  string = "audit"
+	auditWidgetTitle string = "Audit log"
+	auditWidgetHelp  string = "Audit log \x7c \x1b[7mTYPE\x1b[0m Filter \x7c \x1b[7mENTER\x1b[0m Details \x7c \x1b[7mESC\x1b[0m Close \x7c \x1b[7m^X\x1b[0m Exit"
+	auditTimeFormat  string = "2006-01-02 15:04:05"
+)
+
+// Check interface
+var _ IWidget = &AuditWidget{}
+
+// AuditWidget represents a popup with the commands that ran, newest first, as recorded in the audit log
+type AuditWidget struct {
+	Widget
+	log     *commands.AuditLog
+	entries []commands.AuditEntry
+	filter  string
+	// expanded are the entries whose details are shown
+	expanded map[int]bool
+	// rows are the entries each line on screen belongs to
+	rows     []int
+	previous string
+	widgets  *Widgets
+}
+
+// NewAuditWidget creates a new AuditWidget
+func NewAuditWidget(widgets *Widgets) *AuditWidget {
+	return &AuditWidget{Widget: Widget{Name: AuditWidgetName, Title: auditWidgetTitle}, widgets: widgets}
+}
+
+// SetAuditLog sets the audit log the popup shows
+func (widget *AuditWidget) SetAuditLog(log *commands.AuditLog) {
+	widget.log = log
+}
+
+// ShowAudit shows the popup with the entries of the audit log
+func (widget *AuditWidget) ShowAudit(g *gocui.Gui) error {
+	if widget.log == nil {
+		return widget.widgets.Msg().ShowMsg(g, "Audit log", "There is no audit log")
+	}
+	entries, err := widget.log.Query(commands.AuditFilter{})
+	if err != nil {
+		return widget.widgets.Msg().ShowMsg(g, "Audit log", err.Error())
+	}
+
+	// Newest first
+	widget.entries = make([]commands.AuditEntry, len(entries))
+	for index, entry := range entries {
+		widget.entries[len(entries)-1-index] = entry
+	}
+	widget.filter, widget.expanded = "", map[int]bool{}
+	if current := g.CurrentView(); current != nil && current.Name() != widget.Name {
+		widget.previous = current.Name()
+	}
+
+	maxX, maxY := g.Size()
+	v, err := widget.Layout(g, 0, 0, maxX, maxY)
+	if err != nil {
+		return err
+	}
+	if err := moveTo(v, 0); err != nil {
+		return err
+	}
+	return widget.SetAsCurrentView(g)
+}
+
+// HideAudit hides the popup
+func (widget *AuditWidget) HideAudit(g *gocui.Gui) error {
+	if err := g.DeleteView(widget.Name); err != nil {
+		return err
+	}
+
+	// Give the focus back to the widget that had it before the popup
+	if previous, ok := widget.widgets.widgets[widget.previous]; ok {
+		return previous.SetAsCurrentView(g)
+	}
+	return nil
+}
+
+// GetName returns the name of the widget
+func (widget *AuditWidget) GetName() string { return widget.Name }
+
+// Layout shows the contents of the widget on screen
+func (widget *AuditWidget) Layout(g *gocui.Gui, x, y int, w, h int) (*gocui.View, error) {
+	widget.X, widget.Y, widget.W, widget.H = x, y, w, h
+
+	v, err := g.SetView(widget.Name, 2, 1, w-3, h-3)
+	if err != nil && err != gocui.ErrUnknownView {
+		return nil, err
+	}
+
+	v.Highlight = true
+	v.SelBgColor = gocui.ColorGreen
+	v.SelFgColor = gocui.ColorBlack
+	v.Editable = true
+	v.Editor = gocui.EditorFunc(widget.edit)
+	widget.render(v)
+
+	return v, nil
+}
+
+// render shows the entries that match the filter, with the details of the expanded ones
+func (widget *AuditWidget) render(v *gocui.View) {
+	v.Clear()
+	widget.rows = nil
+	filter := strings.ToLower(widget.filter)
+	for index, entry := range widget.entries {
+		if filter != "" && !strings.Contains(auditSearchText(entry), filter) {
+			continue
+		}
+		widget.print(v, index, auditRow(entry))
+		if !widget.expanded[index] {
+			continue
+		}
+		for _, line := range auditDetails(entry) {
+			widget.print(v, index, "    "+line)
+		}
+	}
+
+	v.Title = fmt.Sprintf("%s [%d entries]", widget.Title, len(widget.entries))
+	if widget.filter != "" {
+		v.Title = fmt.Sprintf("%s [%s]", widget.Title, widget.filter)
+	}
+}
+
+// print shows a line of an entry
+func (widget *AuditWidget) print(v *gocui.View, index int, line string) {
+	fmt.Fprintln(v, line)
+	widget.rows = append(widget.rows, index)
+}
+
+// auditRow returns the line of an entry: when it ran, how it went, its context and its command
+func auditRow(entry commands.AuditEntry) string {
+	var status string
+	switch {
+	case entry.Error != "":
+		status = "\x1b[31m✗ error\x1b[0m "
+	case entry.ExitCode != 0:
+		status = fmt.Sprintf("\x1b[31m✗ exit %d\x1b[0m", entry.ExitCode)
+	default:
+		status = "\x1b[32m✓\x1b[0m       "
+	}
+	context := entry.Context
+	if context == "" {
+		context = "-"
+	}
+	return fmt.Sprintf("%s %s %s %s", entry.StartTime.Local().Format(auditTimeFormat), status, context, entry.Command)
+}
+
+// auditDetails returns the lines with the rest of the fields of an entry
+func auditDetails(entry commands.AuditEntry) []string {
+	namespace := entry.Namespace
+	if namespace == "" {
+		namespace = "default of the context"
+	}
+	details := []string{
+		fmt.Sprintf("User: %s", entry.User),
+		fmt.Sprintf("Namespace: %s", namespace),
+		fmt.Sprintf("Ran from %s to %s (%s)",
+			entry.StartTime.Local().Format(auditTimeFormat),
+			entry.EndTime.Local().Format(auditTimeFormat),
+			entry.EndTime.Sub(entry.StartTime).Round(time.Millisecond)),
+		fmt.Sprintf("Exit code: %d", entry.ExitCode),
+		fmt.Sprintf("Output SHA-256: %s", entry.OutputHash),
+	}
+	if entry.Error != "" {
+		details = append(details, fmt.Sprintf("\x1b[31mError: %s\x1b[0m", entry.Error))
+	}
+	if entry.Fake {
+		details = append(details, "Answered by a fake cluster")
+	}
+	return details
+}
+
+// auditSearchText returns the text the filter of the popup looks for in an entry
+func auditSearchText(entry commands.AuditEntry) string {
+	fields := []string{
+		entry.StartTime.Local().Format(auditTimeFormat),
+		entry.Context,
+		entry.Namespace,
+		entry.User,
+		entry.Command,
+		entry.Error,
+	}
+	return strings.ToLower(strings.Join(fields, " "))
+}
+
+// Refresh updates the contents of the widget on screen
+func (widget *AuditWidget) Refresh(g *gocui.Gui) (*gocui.View, error) {
+	return widget.Layout(g, widget.X, widget.Y, widget.W, widget.H)
+}
+
+// SetAsCurrentView sets the widget as the current view
+func (widget *AuditWidget) SetAsCurrentView(g *gocui.Gui) error {
+	if _, err := g.SetCurrentView(widget.Name); err != nil {
+		return err
+	}
+
+	if err := widget.widgets.Status().SetStatus(g, auditWidgetHelp); err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetKeyBindings sets keybindings for the widget
+func (widget *AuditWidget) SetKeyBindings(g *gocui.Gui) error {
+	if err := g.SetKeybinding(widget.Name, gocui.KeyArrowUp, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		v.MoveCursor(0, -1, false)
+		return nil
+	}); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(widget.Name, gocui.KeyArrowDown, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		if getCommandPosition(v) < len(widget.rows) {
+			v.MoveCursor(0, 1, false)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(widget.Name, gocui.KeyEnter, gocui.ModNone, widget.toggleDetails); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(widget.Name, gocui.KeyEsc, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		return widget.HideAudit(g)
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// toggleDetails shows the details of the entry under the cursor, or hides them
+func (widget *AuditWidget) toggleDetails(g *gocui.Gui, v *gocui.View) error {
+	row := getCommandPosition(v) - 1
+	if row < 0 || row >= len(widget.rows) {
+		return nil
+	}
+	index := widget.rows[row]
+	widget.expanded[index] = !widget.expanded[index]
+	widget.render(v)
+
+	// Keep the cursor on the first line of the entry
+	for line, entry := range widget.rows {
+		if entry == index {
+			return moveTo(v, line)
+		}
+	}
+	return nil
+}
+
+// edit updates the filter of the entries as user types
+func (widget *AuditWidget) edit(v *gocui.View, key gocui.Key, ch rune, mod gocui.Modifier) {
+	filter := []rune(widget.filter)
+	switch {
+	case ch != 0 && mod == 0:
+		filter = append(filter, ch)
+	case key == gocui.KeySpace:
+		filter = append(filter, ' ')
+	case (key == gocui.KeyBackspace || key == gocui.KeyBackspace2) && len(filter) > 0:
+		filter = filter[:len(filter)-1]
+	default:
+		return
+	}
+
+	widget.filter = string(filter)
+	widget.render(v)
+	// Editors cannot fail, and the cursor can always move to the first entry
+	_ = moveTo(v, 0)
+}
@@ -2,7 +2,7 @@ package widgets
 
 import (
 	"fmt"
-	"strings"
+	"superk/cmd/commands"
 	"superk/cmd/utils"
 
 	"github.com/jroimartin/gocui"
@@ -18,21 +18,29 @@ const (
 // Check interface
 var _ IWidget = &CommandWidget{}
 
-// CommandWidget represents a kubectl command to run
+// CommandWidget represents a command to run (kubectl, helm...)
 type CommandWidget struct {
 	Widget
-	editor  *gocui.Editor
-	widgets *Widgets
+	editor   *gocui.Editor
+	commands *commands.CForest
+	widgets  *Widgets
 }
 
 // NewCommandWidget creates a new CommandWidget
 func NewCommandWidget(
 	editor *gocui.Editor,
+	commands *commands.CForest,
 	widgets *Widgets) *CommandWidget {
 	return &CommandWidget{
-		Widget:  Widget{Name: CommandWidgetName, Title: commandWidgetTitle},
-		editor:  editor,
-		widgets: widgets}
+		Widget:   Widget{Name: CommandWidgetName, Title: commandWidgetTitle},
+		editor:   editor,
+		commands: commands,
+		widgets:  widgets}
+}
+
+// SetCommands sets the forest new commands are added to and completed from
+func (widget *CommandWidget) SetCommands(commands *commands.CForest) {
+	widget.commands = commands
 }
 
 // GetName returns the name of the widget
@@ -123,13 +131,11 @@ func (widget *CommandWidget) run(g *gocui.Gui, v *gocui.View) error {
 	if err != nil {
 		command = ""
 	}
-	command = strings.TrimSpace(command)
-	if command != "kubectl" && !strings.HasPrefix(command, "kubectl ") {
-		command = fmt.Sprintf("kubectl %s", command)
-	}
+	// Commands that don't start with an allowed root (e.g. "get pod") are kubectl commands by default
+	command = widget.commands.Qualify(command)
 
 	if err := widget.widgets.Tree().AddCommand(g, command); err != nil {
-		return err
+		return widget.widgets.Msg().ShowMsg(g, "Error", err.Error())
 	}
 
 	v.Clear()
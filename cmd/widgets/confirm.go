@@ -0,0 +1,163 @@
+package widgets
+
+import (
+	"fmt"
+	"superk/cmd/utils"
+
+	"github.com/jroimartin/gocui"
+)
+
+const (
+	// ConfirmWidgetName is the name of this widget
+	ConfirmWidgetName string = "confirm"
+	confirmWidgetHelp string = "Confirm \x7c \x1b[7mENTER\x1b[0m Accept \x7c \x1b[7mESC\x1b[0m Cancel \x7c \x1b[7m^X\x1b[0m Exit"
+)
+
+// Check interface
+var _ IWidget = &ConfirmWidget{}
+
+// ConfirmWidget represents a popup that shows a list of lines and asks user to accept or cancel an action
+type ConfirmWidget struct {
+	Widget
+	lines     []string
+	onConfirm func(g *gocui.Gui) error
+	previous  string
+	widgets   *Widgets
+}
+
+// NewConfirmWidget creates a new ConfirmWidget
+func NewConfirmWidget(widgets *Widgets) *ConfirmWidget {
+	return &ConfirmWidget{Widget: Widget{Name: ConfirmWidgetName}, widgets: widgets}
+}
+
+// ShowConfirm shows the popup. The action runs only if user accepts it.
+func (widget *ConfirmWidget) ShowConfirm(g *gocui.Gui, title string, lines []string, onConfirm func(g *gocui.Gui) error) error {
+	widget.Title, widget.lines, widget.onConfirm = title, lines, onConfirm
+	if current := g.CurrentView(); current != nil && current.Name() != widget.Name {
+		widget.previous = current.Name()
+	}
+
+	maxX, maxY := g.Size()
+	if _, err := widget.Layout(g, 0, 0, maxX, maxY); err != nil {
+		return err
+	}
+
+	return widget.SetAsCurrentView(g)
+}
+
+// HideConfirm hides the popup without running the action
+func (widget *ConfirmWidget) HideConfirm(g *gocui.Gui) error {
+	if err := g.DeleteView(widget.Name); err != nil {
+		return err
+	}
+
+	// Give the focus back to the widget that had it before the popup
+	if previous, ok := widget.widgets.widgets[widget.previous]; ok {
+		return previous.SetAsCurrentView(g)
+	}
+	return nil
+}
+
+// GetName returns the name of the widget
+func (widget *ConfirmWidget) GetName() string { return widget.Name }
+
+// Layout shows the contents of the widget on screen
+func (widget *ConfirmWidget) Layout(g *gocui.Gui, x, y int, w, h int) (*gocui.View, error) {
+	widget.X, widget.Y, widget.W, widget.H = x, y, w, h
+
+	width := len(widget.Title)
+	for _, line := range widget.lines {
+		if len(line) > width {
+			width = len(line)
+		}
+	}
+	width = utils.Min(width+4, w-8)
+	// Lines that do not fit are wrapped, so they can be read in full (e.g. long commands)
+	lines := wrapLines(widget.lines, width-2)
+	height := utils.Min(len(lines)+1, h-8)
+
+	x0, y0 := w/2-width/2, h/2-height/2
+	v, err := g.SetView(widget.Name, x0, y0, x0+width, y0+height)
+	if err != nil && err != gocui.ErrUnknownView {
+		return nil, err
+	}
+
+	v.Title = widget.Title
+	v.Highlight = true
+	v.SelBgColor = gocui.ColorGreen
+	v.SelFgColor = gocui.ColorBlack
+	v.Clear()
+	for _, line := range lines {
+		fmt.Fprintf(v, " %s\n", line)
+	}
+
+	return v, nil
+}
+
+// wrapLines splits the lines longer than a certain width into several lines
+func wrapLines(lines []string, width int) []string {
+	if width < 1 {
+		return lines
+	}
+	wrapped := make([]string, 0, len(lines))
+	for _, line := range lines {
+		runes := []rune(line)
+		for len(runes) > width {
+			wrapped = append(wrapped, string(runes[:width]))
+			runes = runes[width:]
+		}
+		wrapped = append(wrapped, string(runes))
+	}
+	return wrapped
+}
+
+// Refresh updates the contents of the widget on screen
+func (widget *ConfirmWidget) Refresh(g *gocui.Gui) (*gocui.View, error) {
+	return widget.Layout(g, widget.X, widget.Y, widget.W, widget.H)
+}
+
+// SetAsCurrentView sets the widget as the current view
+func (widget *ConfirmWidget) SetAsCurrentView(g *gocui.Gui) error {
+	if _, err := g.SetCurrentView(widget.Name); err != nil {
+		return err
+	}
+
+	if err := widget.widgets.Status().SetStatus(g, confirmWidgetHelp); err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetKeyBindings sets keybindings for the widget
+func (widget *ConfirmWidget) SetKeyBindings(g *gocui.Gui) error {
+	if err := g.SetKeybinding(widget.Name, gocui.KeyArrowUp, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		v.MoveCursor(0, -1, false)
+		return nil
+	}); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(widget.Name, gocui.KeyArrowDown, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		v.MoveCursor(0, 1, false)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(widget.Name, gocui.KeyEnter, gocui.ModNone, widget.accept); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(widget.Name, gocui.KeyEsc, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		return widget.HideConfirm(g)
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (widget *ConfirmWidget) accept(g *gocui.Gui, v *gocui.View) error {
+	if err := widget.HideConfirm(g); err != nil {
+		return err
+	}
+	return widget.onConfirm(g)
+}
@@ -0,0 +1,309 @@
+package widgets
+
+import (
+	"fmt"
+	"strings"
+	"superk/cmd/commands"
+
+	"github.com/jroimartin/gocui"
+)
+
+const (
+	// FanoutWidgetName is the name of this widget
+	FanoutWidgetName string = "fanout"
+	fanoutTabsName   string = "fanout-tabs"
+	fanoutWidgetHelp string = "Fan-out \x7c \x1b[7m←→\x1b[0m Context \x7c \x1b[7m^D\x1b[0m Diff \x7c \x1b[7m^K\x1b[0m Cancel \x7c \x1b[7mESC\x1b[0m Close \x7c \x1b[7m^X\x1b[0m Exit"
+)
+
+// Check interface
+var _ IWidget = &FanoutWidget{}
+
+// FanoutWidget represents a popup with the outputs of a command that ran against several kube contexts,
+// one tab per context. In diff mode, the parts of each output that disagree with the other contexts stand out.
+type FanoutWidget struct {
+	Widget
+	command  string
+	fanout   *commands.Fanout
+	current  int
+	diff     bool
+	previous string
+	widgets  *Widgets
+}
+
+// NewFanoutWidget creates a new FanoutWidget
+func NewFanoutWidget(widgets *Widgets) *FanoutWidget {
+	return &FanoutWidget{Widget: Widget{Name: FanoutWidgetName}, widgets: widgets}
+}
+
+// ShowFanout shows the popup with the outputs of a command that runs against several kube contexts, the first one first
+func (widget *FanoutWidget) ShowFanout(g *gocui.Gui, command string, fanout *commands.Fanout) error {
+	widget.command, widget.fanout, widget.current, widget.diff = command, fanout, 0, false
+	if current := g.CurrentView(); current != nil && current.Name() != widget.Name {
+		widget.previous = current.Name()
+	}
+
+	maxX, maxY := g.Size()
+	if _, err := widget.Layout(g, 0, 0, maxX, maxY); err != nil {
+		return err
+	}
+	return widget.SetAsCurrentView(g)
+}
+
+// UpdateFanout shows the new outputs of a command that runs against several kube contexts,
+// if the popup still shows that command
+func (widget *FanoutWidget) UpdateFanout(g *gocui.Gui, fanout *commands.Fanout) error {
+	if widget.fanout != fanout {
+		return nil
+	}
+	if _, err := g.View(widget.Name); err != nil {
+		return nil
+	}
+	_, err := widget.Refresh(g)
+	return err
+}
+
+// HideFanout hides the popup. Commands that are running keep running.
+func (widget *FanoutWidget) HideFanout(g *gocui.Gui) error {
+	if err := g.DeleteView(fanoutTabsName); err != nil {
+		return err
+	}
+	if err := g.DeleteView(widget.Name); err != nil {
+		return err
+	}
+
+	// Give the focus back to the widget that had it before the popup
+	if previous, ok := widget.widgets.widgets[widget.previous]; ok {
+		return previous.SetAsCurrentView(g)
+	}
+	return nil
+}
+
+// GetName returns the name of the widget
+func (widget *FanoutWidget) GetName() string { return widget.Name }
+
+// Layout shows the contents of the widget on screen
+func (widget *FanoutWidget) Layout(g *gocui.Gui, x, y int, w, h int) (*gocui.View, error) {
+	widget.X, widget.Y, widget.W, widget.H = x, y, w, h
+
+	entries := widget.entries()
+	var diffs [][][]commands.DiffRange
+	if widget.diff {
+		diffs = widget.compare(entries)
+	}
+
+	// Tabs are drawn above the output, so they stay on screen while the output scrolls
+	tabs, err := g.SetView(fanoutTabsName, 2, 1, w-3, 3)
+	if err != nil && err != gocui.ErrUnknownView {
+		return nil, err
+	}
+	done, failed, total := widget.fanout.Batch.Count()
+	tabs.Title = fmt.Sprintf("Fan-out [%s] [%d/%d done, %d failed]", widget.command, done, total, failed)
+	if widget.diff {
+		tabs.Title = fmt.Sprintf("%s [diff, ^D to hide]", tabs.Title)
+	}
+	tabs.Clear()
+	for index, context := range widget.fanout.Contexts {
+		tab := fmt.Sprintf(" %s %s ", context, fanoutStatus(entries[index]))
+		if diffs != nil && diffs[index] != nil {
+			if count := countDiffs(diffs[index]); count > 0 {
+				tab = fmt.Sprintf("%s\x1b[33m≠%d\x1b[0m ", tab, count)
+			}
+		}
+		if index == widget.current {
+			tab = fmt.Sprintf("\x1b[7m%s\x1b[0m", strings.Replace(tab, "\x1b[0m", "\x1b[0m\x1b[7m", -1))
+		}
+		fmt.Fprint(tabs, tab, "│")
+	}
+
+	v, err := g.SetView(widget.Name, 2, 3, w-3, h-3)
+	if err != nil && err != gocui.ErrUnknownView {
+		return nil, err
+	}
+	v.Wrap = false
+	v.Clear()
+	entry := entries[widget.current]
+	if entry.Output == nil {
+		fmt.Fprintf(v, "The command is %s\n", entry.Status)
+		return v, nil
+	}
+	lines := strings.Split(strings.TrimRight(*entry.Output.Output, "\n"), "\n")
+	for index, line := range lines {
+		if diffs != nil && diffs[widget.current] != nil && index < len(diffs[widget.current]) {
+			line = highlightRanges(line, diffs[widget.current][index])
+		}
+		fmt.Fprintln(v, line)
+	}
+	for _, line := range strings.Split(strings.TrimRight(entry.Output.Stderr, "\n"), "\n") {
+		if line != "" {
+			fmt.Fprintf(v, "\x1b[31m%s\x1b[0m\n", line)
+		}
+	}
+	return v, nil
+}
+
+// entries returns the commands of the fan-out and their results, in the order of their contexts
+func (widget *FanoutWidget) entries() []commands.BatchEntry {
+	byCmd := map[*commands.Cmd]commands.BatchEntry{}
+	for _, entry := range widget.fanout.Batch.Entries() {
+		byCmd[entry.Cmd] = entry
+	}
+	entries := make([]commands.BatchEntry, len(widget.fanout.Cmds))
+	for index, cmd := range widget.fanout.Cmds {
+		entries[index] = byCmd[cmd]
+	}
+	return entries
+}
+
+// compare compares the outputs of the commands that succeeded. The others have no differences.
+func (widget *FanoutWidget) compare(entries []commands.BatchEntry) [][][]commands.DiffRange {
+	var outputs []string
+	var indexes []int
+	for index, entry := range entries {
+		if entry.Status == commands.BatchDone && !entry.Failed() {
+			outputs = append(outputs, *entry.Output.Output)
+			indexes = append(indexes, index)
+		}
+	}
+
+	diffs := make([][][]commands.DiffRange, len(entries))
+	if len(outputs) < 2 {
+		return diffs
+	}
+	for index, diff := range commands.CompareOutputs(outputs) {
+		diffs[indexes[index]] = diff
+	}
+	return diffs
+}
+
+// fanoutStatus returns a mark for the result of a command of a fan-out
+func fanoutStatus(entry commands.BatchEntry) string {
+	switch {
+	case entry.Failed():
+		return exitCodeBadge(entry.Output.ExitCode)
+	case entry.Status == commands.BatchDone:
+		return exitCodeBadge(0)
+	case entry.Status == commands.BatchRunning:
+		return spinnerFrame()
+	default:
+		return entry.Status
+	}
+}
+
+// countDiffs returns how many lines of an output disagree with the other outputs
+func countDiffs(diff [][]commands.DiffRange) int {
+	count := 0
+	for _, ranges := range diff {
+		if len(ranges) > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// highlightRanges shows some parts of a line in reverse video
+func highlightRanges(line string, ranges []commands.DiffRange) string {
+	var builder strings.Builder
+	last := 0
+	for _, r := range ranges {
+		if r.Start < last || r.End > len(line) {
+			continue
+		}
+		builder.WriteString(line[last:r.Start])
+		builder.WriteString("\x1b[7m")
+		builder.WriteString(line[r.Start:r.End])
+		builder.WriteString("\x1b[0m")
+		last = r.End
+	}
+	builder.WriteString(line[last:])
+	return builder.String()
+}
+
+// Refresh updates the contents of the widget on screen
+func (widget *FanoutWidget) Refresh(g *gocui.Gui) (*gocui.View, error) {
+	return widget.Layout(g, widget.X, widget.Y, widget.W, widget.H)
+}
+
+// SetAsCurrentView sets the widget as the current view
+func (widget *FanoutWidget) SetAsCurrentView(g *gocui.Gui) error {
+	if _, err := g.SetCurrentView(widget.Name); err != nil {
+		return err
+	}
+
+	if err := widget.widgets.Status().SetStatus(g, fanoutWidgetHelp); err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetKeyBindings sets keybindings for the widget
+func (widget *FanoutWidget) SetKeyBindings(g *gocui.Gui) error {
+	if err := g.SetKeybinding(widget.Name, gocui.KeyArrowLeft, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		return widget.showTab(g, v, widget.current-1)
+	}); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(widget.Name, gocui.KeyArrowRight, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		return widget.showTab(g, v, widget.current+1)
+	}); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(widget.Name, gocui.KeyArrowUp, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		return scroll(v, -1)
+	}); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(widget.Name, gocui.KeyArrowDown, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		return scroll(v, 1)
+	}); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(widget.Name, gocui.KeyCtrlD, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		widget.diff = !widget.diff
+		_, err := widget.Refresh(g)
+		return err
+	}); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(widget.Name, gocui.KeyCtrlK, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		widget.fanout.Batch.Cancel()
+		_, err := widget.Refresh(g)
+		return err
+	}); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(widget.Name, gocui.KeyEsc, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		return widget.HideFanout(g)
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// showTab shows the output of the command that ran against another context, from its first line
+func (widget *FanoutWidget) showTab(g *gocui.Gui, v *gocui.View, tab int) error {
+	if tab < 0 || tab >= len(widget.fanout.Contexts) {
+		return nil
+	}
+	widget.current = tab
+	if err := v.SetOrigin(0, 0); err != nil {
+		return err
+	}
+	_, err := widget.Refresh(g)
+	return err
+}
+
+// scroll scrolls a view some lines up or down, without going past its contents
+func scroll(v *gocui.View, lines int) error {
+	x, y := v.Origin()
+	_, height := v.Size()
+	y += lines
+	if last := len(v.BufferLines()) - height; y > last {
+		y = last
+	}
+	if y < 0 {
+		y = 0
+	}
+	return v.SetOrigin(x, y)
+}
@@ -0,0 +1,275 @@
+package widgets
+
+import (
+	"fmt"
+	"superk/cmd/utils"
+
+	"github.com/jroimartin/gocui"
+)
+
+const (
+	// ListWidgetName is the name of this widget
+	ListWidgetName string = "list"
+	listWidgetHelp string = "Select \x7c \x1b[7mTYPE\x1b[0m Filter \x7c \x1b[7mENTER\x1b[0m Select \x7c \x1b[7mESC\x1b[0m Cancel \x7c \x1b[7m^X\x1b[0m Exit"
+	checklistHelp  string = "Select \x7c \x1b[7mTYPE\x1b[0m Filter \x7c \x1b[7mSPACE\x1b[0m Check \x7c \x1b[7mENTER\x1b[0m Done \x7c \x1b[7mESC\x1b[0m Cancel \x7c \x1b[7m^X\x1b[0m Exit"
+)
+
+// Check interface
+var _ IWidget = &ListWidget{}
+
+// ListWidget represents a popup that lets user select an item from a list, fuzzy filtering it as they type.
+// As a checklist, it lets user check several items.
+type ListWidget struct {
+	Widget
+	items    []string
+	filter   string
+	filtered []string
+	onSelect func(g *gocui.Gui, item string) error
+	// checked are the items user checked, and onCheck the action that runs with them, if the list is a checklist
+	checked  map[string]bool
+	onCheck  func(g *gocui.Gui, items []string) error
+	previous string
+	widgets  *Widgets
+}
+
+// NewListWidget creates a new ListWidget
+func NewListWidget(widgets *Widgets) *ListWidget {
+	return &ListWidget{Widget: Widget{Name: ListWidgetName}, widgets: widgets}
+}
+
+// ShowList shows the popup with the selected item under the cursor. The action runs with the item user selects, if any.
+func (widget *ListWidget) ShowList(
+	g *gocui.Gui,
+	title string,
+	items []string,
+	selected string,
+	onSelect func(g *gocui.Gui, item string) error) error {
+	widget.onSelect, widget.checked, widget.onCheck = onSelect, nil, nil
+	return widget.show(g, title, items, selected)
+}
+
+// ShowChecklist shows the popup with some items checked. The action runs with the items user checks,
+// or with the item under the cursor if user checks none.
+func (widget *ListWidget) ShowChecklist(
+	g *gocui.Gui,
+	title string,
+	items []string,
+	checked []string,
+	onCheck func(g *gocui.Gui, items []string) error) error {
+	widget.onSelect, widget.checked, widget.onCheck = nil, map[string]bool{}, onCheck
+	for _, item := range checked {
+		widget.checked[item] = true
+	}
+	return widget.show(g, title, items, "")
+}
+
+func (widget *ListWidget) show(g *gocui.Gui, title string, items []string, selected string) error {
+	widget.Title, widget.items = title, items
+	widget.filter, widget.filtered = "", items
+	if current := g.CurrentView(); current != nil && current.Name() != widget.Name {
+		widget.previous = current.Name()
+	}
+
+	maxX, maxY := g.Size()
+	v, err := widget.Layout(g, 0, 0, maxX, maxY)
+	if err != nil {
+		return err
+	}
+	index := 0
+	for current, item := range items {
+		if item == selected {
+			index = current
+		}
+	}
+	if err := moveTo(v, index); err != nil {
+		return err
+	}
+
+	return widget.SetAsCurrentView(g)
+}
+
+// moveTo moves the cursor to an item of the list, scrolling the list if needed
+func moveTo(v *gocui.View, index int) error {
+	_, height := v.Size()
+	origin := utils.Max(index-height+1, 0)
+	if err := v.SetOrigin(0, origin); err != nil {
+		return err
+	}
+	return v.SetCursor(0, index-origin)
+}
+
+// HideList hides the popup without selecting any item
+func (widget *ListWidget) HideList(g *gocui.Gui) error {
+	if err := g.DeleteView(widget.Name); err != nil {
+		return err
+	}
+
+	// Give the focus back to the widget that had it before the popup
+	if previous, ok := widget.widgets.widgets[widget.previous]; ok {
+		return previous.SetAsCurrentView(g)
+	}
+	return nil
+}
+
+// GetName returns the name of the widget
+func (widget *ListWidget) GetName() string { return widget.Name }
+
+// Layout shows the contents of the widget on screen
+func (widget *ListWidget) Layout(g *gocui.Gui, x, y int, w, h int) (*gocui.View, error) {
+	widget.X, widget.Y, widget.W, widget.H = x, y, w, h
+
+	width := len(widget.Title)
+	for _, item := range widget.items {
+		width = utils.Max(width, len(item))
+	}
+	if widget.onCheck != nil {
+		width += len("[x] ")
+	}
+	width = utils.Min(width+4, w-8)
+	height := utils.Min(utils.Max(len(widget.items), 1)+1, h-8)
+
+	x0, y0 := w/2-width/2, h/2-height/2
+	v, err := g.SetView(widget.Name, x0, y0, x0+width, y0+height)
+	if err != nil && err != gocui.ErrUnknownView {
+		return nil, err
+	}
+
+	v.Highlight = true
+	v.SelBgColor = gocui.ColorGreen
+	v.SelFgColor = gocui.ColorBlack
+	v.Editable = true
+	v.Editor = gocui.EditorFunc(widget.edit)
+	widget.render(v)
+
+	return v, nil
+}
+
+// render shows the items that match the filter
+func (widget *ListWidget) render(v *gocui.View) {
+	v.Title = widget.Title
+	if widget.filter != "" {
+		v.Title = fmt.Sprintf("%s [%s]", widget.Title, widget.filter)
+	}
+	v.Clear()
+	for _, item := range widget.filtered {
+		switch {
+		case widget.onCheck == nil:
+			fmt.Fprintf(v, " %s\n", item)
+		case widget.checked[item]:
+			fmt.Fprintf(v, " [x] %s\n", item)
+		default:
+			fmt.Fprintf(v, " [ ] %s\n", item)
+		}
+	}
+}
+
+// Refresh updates the contents of the widget on screen
+func (widget *ListWidget) Refresh(g *gocui.Gui) (*gocui.View, error) {
+	return widget.Layout(g, widget.X, widget.Y, widget.W, widget.H)
+}
+
+// SetAsCurrentView sets the widget as the current view
+func (widget *ListWidget) SetAsCurrentView(g *gocui.Gui) error {
+	if _, err := g.SetCurrentView(widget.Name); err != nil {
+		return err
+	}
+
+	help := listWidgetHelp
+	if widget.onCheck != nil {
+		help = checklistHelp
+	}
+	if err := widget.widgets.Status().SetStatus(g, help); err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetKeyBindings sets keybindings for the widget
+func (widget *ListWidget) SetKeyBindings(g *gocui.Gui) error {
+	if err := g.SetKeybinding(widget.Name, gocui.KeyArrowUp, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		v.MoveCursor(0, -1, false)
+		return nil
+	}); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(widget.Name, gocui.KeyArrowDown, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		if getCommandPosition(v) < len(widget.filtered) {
+			v.MoveCursor(0, 1, false)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(widget.Name, gocui.KeyEnter, gocui.ModNone, widget.selectItem); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(widget.Name, gocui.KeyEsc, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		return widget.HideList(g)
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (widget *ListWidget) selectItem(g *gocui.Gui, v *gocui.View) error {
+	index := getCommandPosition(v) - 1
+	if err := widget.HideList(g); err != nil {
+		return err
+	}
+	if index < 0 || index >= len(widget.filtered) {
+		return nil
+	}
+	if widget.onCheck == nil {
+		return widget.onSelect(g, widget.filtered[index])
+	}
+
+	var checked []string
+	for _, item := range widget.items {
+		if widget.checked[item] {
+			checked = append(checked, item)
+		}
+	}
+	if len(checked) == 0 {
+		checked = []string{widget.filtered[index]}
+	}
+	return widget.onCheck(g, checked)
+}
+
+// toggleItem checks the item under the cursor of a checklist, or unchecks it
+func (widget *ListWidget) toggleItem(v *gocui.View) {
+	index := getCommandPosition(v) - 1
+	if index < 0 || index >= len(widget.filtered) {
+		return
+	}
+	item := widget.filtered[index]
+	widget.checked[item] = !widget.checked[item]
+	widget.render(v)
+}
+
+// edit updates the filter of the list as user types
+func (widget *ListWidget) edit(v *gocui.View, key gocui.Key, ch rune, mod gocui.Modifier) {
+	if key == gocui.KeySpace && widget.onCheck != nil {
+		widget.toggleItem(v)
+		return
+	}
+
+	filter := []rune(widget.filter)
+	switch {
+	case ch != 0 && mod == 0:
+		filter = append(filter, ch)
+	case key == gocui.KeySpace:
+		filter = append(filter, ' ')
+	case (key == gocui.KeyBackspace || key == gocui.KeyBackspace2) && len(filter) > 0:
+		filter = filter[:len(filter)-1]
+	default:
+		return
+	}
+
+	widget.filter = string(filter)
+	widget.filtered = utils.FuzzyFilter(widget.filter, widget.items)
+	widget.render(v)
+	// Editors cannot fail, and the cursor can always move to the first item
+	_ = moveTo(v, 0)
+}
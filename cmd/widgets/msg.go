@@ -17,7 +17,8 @@ var _ IWidget = &MsgWidget{}
 // MsgWidget represents a popup message
 type MsgWidget struct {
 	Widget
-	message string
+	message  string
+	previous string
 }
 
 // NewMsgWidget creates a new NewMsgWidget
@@ -28,6 +29,9 @@ func NewMsgWidget() *MsgWidget {
 // ShowMsg shows a popup message to user
 func (widget *MsgWidget) ShowMsg(g *gocui.Gui, title, message string) error {
 	widget.Title, widget.message = title, message
+	if current := g.CurrentView(); current != nil && current.Name() != widget.Name {
+		widget.previous = current.Name()
+	}
 
 	maxX, maxY := g.Size()
 	if _, err := widget.Layout(g, 0, 0, maxX, maxY); err != nil {
@@ -46,6 +50,13 @@ func (widget *MsgWidget) HideMsg(g *gocui.Gui) error {
 	if err := g.DeleteView(widget.Name); err != nil {
 		return err
 	}
+
+	// Give the focus back to the view that had it before the message
+	if widget.previous != "" {
+		if _, err := g.SetCurrentView(widget.previous); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -14,8 +14,9 @@ import (
 const (
 	// OutputWidgetName is the name of this widget
 	OutputWidgetName  string = "output"
+	outputTitleName   string = "output-title"
 	outputWidgetTitle string = "Output"
-	outputWidgetHelp  string = "Output \x7c \x1b[7m^C\x1b[0m Copy word \x7c \x1b[7m^L\x1b[0m Copy line \x7c \x1b[7m^X\x1b[0m Exit"
+	outputWidgetHelp  string = "Output \x7c \x1b[7m^C\x1b[0m Copy word \x7c \x1b[7m^L\x1b[0m Copy line \x7c \x1b[7m^K\x1b[0m Cancel \x7c \x1b[7m^F\x1b[0m Follow \x7c \x1b[7m^T\x1b[0m Table \x7c \x1b[7m^X\x1b[0m Exit"
 )
 
 // Check interface
@@ -24,9 +25,26 @@ var _ IWidget = &OutputWidget{}
 // OutputWidget represents the output of a kubectl command
 type OutputWidget struct {
 	Widget
-	output    *string
-	clipboard *utils.Clipboard
-	widgets   *Widgets
+	output *string
+	cmd    *commands.Cmd
+	// follow keeps the end of the output of a streaming command on screen
+	follow bool
+	// stream is the output of the streaming command on screen, and streamed the number of its lines on screen
+	stream    *commands.LineBuffer
+	streamed  int
+	viewLines int
+	// watch is the command that runs periodically, if any
+	watch *Watch
+	// table is how the output is shown when it is a table (e.g. kubectl get), unless raw shows it as text instead.
+	// parsed is the table parsed from the output parsedFrom, and row and column are the cell under the cursor.
+	table       *commands.TableView
+	parsed      *commands.Table
+	parsedFrom  *string
+	row, column int
+	raw         bool
+	tableShown  bool
+	clipboard   *utils.Clipboard
+	widgets     *Widgets
 }
 
 // NewOutputWidget creates a new OutputWidget
@@ -35,26 +53,41 @@ func NewOutputWidget(
 	widgets *Widgets) *OutputWidget {
 	return &OutputWidget{
 		Widget:    Widget{Name: OutputWidgetName, Title: outputWidgetTitle},
+		follow:    true,
+		table:     commands.NewTableView(),
 		clipboard: clipboard,
 		widgets:   widgets}
 }
 
-// SetCommandOutput sets the command and its output that this widget will show to user
+// SetCommandOutput sets the command and its output that this widget will show to user.
+// While the command runs, its previous output is shown, if any.
 func (widget *OutputWidget) SetCommandOutput(g *gocui.Gui, cmd *commands.Cmd) error {
+	// How the table of a command is shown lasts until another command is shown
+	if cmd != widget.cmd {
+		widget.table, widget.row, widget.column = commands.NewTableView(), 0, 0
+	}
+
 	// Refresh widget
-	widget.Title = fmt.Sprintf("Output [%s] [%s]", cmd.ToString(), cmd.RunTime.Format(time.UnixDate))
-	widget.output = cmd.CmdOutput.Output
+	widget.cmd, widget.output, widget.stream = cmd, nil, nil
 	v, err := widget.Refresh(g)
 	if err != nil {
 		return err
 	}
 
-	// Scroll view and set cursor at the end of the output
+	// Tables keep the cell under the cursor on screen
+	if widget.tableShown {
+		return nil
+	}
+	return scrollToEnd(v)
+}
+
+// scrollToEnd scrolls a view and sets its cursor at the end of its contents
+func scrollToEnd(v *gocui.View) error {
 	// TODO: This is not taking wrapped lines into account!
 	_, height := v.Size()
 	lineCount := len(v.BufferLines())
 	originY := utils.Max(0, lineCount-height)
-	cursorY := lineCount - originY - 1
+	cursorY := utils.Max(0, lineCount-originY-1)
 	if err := v.SetOrigin(0, originY); err != nil {
 		return err
 	}
@@ -65,6 +98,102 @@ func (widget *OutputWidget) SetCommandOutput(g *gocui.Gui, cmd *commands.Cmd) er
 	return nil
 }
 
+// UpdateCommandOutput shows the new output of a command that finished running, if this widget still shows that command
+func (widget *OutputWidget) UpdateCommandOutput(g *gocui.Gui, cmd *commands.Cmd) error {
+	if widget.cmd != cmd {
+		return nil
+	}
+	return widget.SetCommandOutput(g, cmd)
+}
+
+// SetWatch sets the command that runs periodically, to show when it runs again and what changed in its output.
+// A nil watch means no command runs periodically.
+func (widget *OutputWidget) SetWatch(watch *Watch) {
+	widget.watch = watch
+}
+
+// watching returns whether this widget shows the command that runs periodically
+func (widget *OutputWidget) watching() bool {
+	return widget.watch != nil && widget.cmd != nil && widget.watch.Cmd == widget.cmd
+}
+
+// SetMessage shows a message to user instead of the output of a command
+func (widget *OutputWidget) SetMessage(g *gocui.Gui, message string) error {
+	widget.cmd, widget.output = nil, &message
+	_, err := widget.Refresh(g)
+	return err
+}
+
+// title returns the title of the widget, which describes the command whose output it shows
+func (widget *OutputWidget) title() string {
+	if widget.cmd == nil {
+		return outputWidgetTitle
+	}
+	if attempt, maxAttempts := widget.cmd.Attempt(); attempt > 0 {
+		running := "Running"
+		if widget.cmd.IsStreaming() {
+			running = "Following, ^F to pause"
+			if !widget.follow {
+				running = "Paused, ^F to follow"
+			}
+		}
+		if maxAttempts > 1 {
+			running = fmt.Sprintf("%s, attempt %d/%d", running, attempt, maxAttempts)
+		}
+		return fmt.Sprintf("Output [%s] [%s %s, ^K to cancel]", widget.cmd.ToString(), spinnerFrame(), running)
+	}
+
+	output := widget.cmd.GetOutput()
+	if output.RunTime == nil {
+		return fmt.Sprintf("Output [%s]", widget.cmd.ToString())
+	}
+	title := fmt.Sprintf("Output [%s] [%s]", widget.cmd.ToString(), output.RunTime.Format(time.UnixDate))
+	if output.MaxAttempts > 1 {
+		title = fmt.Sprintf("%s [attempt %d/%d]", title, len(output.Attempts), output.MaxAttempts)
+	}
+	if output.Failed() {
+		title = fmt.Sprintf("%s [exit %d]", title, output.ExitCode)
+	}
+	if widget.watching() {
+		title = fmt.Sprintf("%s [every %s, next in %s, ^W to stop]", title, widget.watch.Interval, widget.watch.Remaining())
+	}
+	return title
+}
+
+// failed returns whether the command whose output this widget shows failed the last time it ran
+func (widget *OutputWidget) failed() bool {
+	if widget.cmd == nil || widget.cmd.IsRunning() {
+		return false
+	}
+	output := widget.cmd.GetOutput()
+	return output.Failed()
+}
+
+// layoutTitle shows the title of the widget in red when the command failed.
+// Views have a single color for their frame and title, so the title is drawn by a frameless view on top of them.
+func (widget *OutputWidget) layoutTitle(g *gocui.Gui, x, y, w int) error {
+	if !widget.failed() {
+		if err := g.DeleteView(outputTitleName); err != nil && err != gocui.ErrUnknownView {
+			return err
+		}
+		return nil
+	}
+
+	x0, x1 := x+1, utils.Min(x+2+len(widget.Title), x+w-2)
+	if x1 <= x0 {
+		return nil
+	}
+	v, err := g.SetView(outputTitleName, x0, y-1, x1, y+1)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	v.Frame = false
+	v.FgColor = gocui.ColorRed | gocui.AttrBold
+	v.Clear()
+	fmt.Fprint(v, widget.Title)
+	return nil
+}
+
 // GetName returns the name of the widget
 func (widget *OutputWidget) GetName() string { return widget.Name }
 
@@ -77,19 +206,139 @@ func (widget *OutputWidget) Layout(g *gocui.Gui, x, y int, w, h int) (*gocui.Vie
 		return nil, err
 	}
 
+	table := widget.shownTable()
+	if err := widget.setTableShown(g, table != nil); err != nil {
+		return nil, err
+	}
+	widget.Title = widget.title()
+	if table != nil {
+		widget.Title += widget.tableSummary(table)
+	}
 	v.Title = widget.Title
 	v.Highlight = true
 	v.SelBgColor = gocui.ColorGreen
 	v.SelFgColor = gocui.ColorBlack
 	v.Wrap = true
+	if stream := widget.runningStream(); stream != nil {
+		if err := widget.layoutStream(v, stream); err != nil {
+			return nil, err
+		}
+		if err := widget.layoutTitle(g, x, y, w); err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+
+	widget.stream = nil
+	if table != nil {
+		if err := widget.layoutTable(g, v, table, x, y, w); err != nil {
+			return nil, err
+		}
+		if err := widget.layoutTitle(g, x, y, w); err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+
 	v.Clear()
-	if widget.output != nil {
-		fmt.Fprintln(v, *widget.output)
+	if widget.cmd == nil {
+		if widget.output != nil {
+			fmt.Fprintln(v, *widget.output)
+		}
+	} else {
+		output := widget.cmd.GetOutput()
+		if output.Output != nil && (output.Stderr == "" || *output.Output != "") {
+			text := *output.Output
+			if output.Stderr != "" {
+				text = strings.TrimSuffix(text, "\n")
+			}
+			// Lines that changed since the previous run of a watched command stand out
+			if widget.watching() && widget.watch.Previous != nil {
+				text = highlightChanges(*widget.watch.Previous, text)
+			}
+			fmt.Fprintln(v, text)
+		}
+		// Errors go right after the output, in red
+		for _, line := range strings.Split(strings.TrimRight(output.Stderr, "\n"), "\n") {
+			if line != "" {
+				fmt.Fprintf(v, "\x1b[31m%s\x1b[0m\n", line)
+			}
+		}
 	}
 
+	if err := widget.layoutTitle(g, x, y, w); err != nil {
+		return nil, err
+	}
 	return v, nil
 }
 
+// setTableShown records whether the output is shown as a table, which has a header that stays on screen and keys of its own
+func (widget *OutputWidget) setTableShown(g *gocui.Gui, shown bool) error {
+	if !shown {
+		if err := g.DeleteView(outputHeaderName); err != nil && err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	if shown == widget.tableShown {
+		return nil
+	}
+	widget.tableShown = shown
+	if current := g.CurrentView(); current != nil && current.Name() == widget.Name {
+		return widget.widgets.Status().SetStatus(g, widget.help())
+	}
+	return nil
+}
+
+// help returns the keys of the widget, which are not the same for tables
+func (widget *OutputWidget) help() string {
+	if widget.tableShown {
+		return outputTableHelp
+	}
+	return outputWidgetHelp
+}
+
+// runningStream returns the output of the command on screen while it streams it, if it does
+func (widget *OutputWidget) runningStream() *commands.LineBuffer {
+	if widget.cmd == nil || !widget.cmd.IsStreaming() || !widget.cmd.IsRunning() {
+		return nil
+	}
+	return widget.cmd.GetStream()
+}
+
+// layoutStream appends the new lines of the output of a streaming command to the view.
+// The view keeps up to commands.StreamMaxLines lines, dropping half of them when it is full.
+func (widget *OutputWidget) layoutStream(v *gocui.View, stream *commands.LineBuffer) error {
+	if stream != widget.stream {
+		widget.stream, widget.streamed, widget.viewLines = stream, 0, 0
+		v.Clear()
+	}
+
+	lines, total := stream.Lines(widget.streamed)
+	if total-len(lines) > widget.streamed || widget.viewLines+len(lines) > commands.StreamMaxLines {
+		lines, total = stream.Lines(total - commands.StreamMaxLines/2)
+		widget.viewLines = 0
+		v.Clear()
+	}
+	for _, line := range lines {
+		fmt.Fprintln(v, line)
+	}
+	widget.streamed, widget.viewLines = total, widget.viewLines+len(lines)
+
+	if !widget.follow || len(lines) == 0 {
+		return nil
+	}
+	return scrollToEnd(v)
+}
+
+// toggleFollow pauses or resumes following the output of a streaming command
+func (widget *OutputWidget) toggleFollow(g *gocui.Gui, v *gocui.View) error {
+	widget.follow = !widget.follow
+	if !widget.follow {
+		return nil
+	}
+	return scrollToEnd(v)
+}
+
 // Refresh updates the contents of the widget on screen
 func (widget *OutputWidget) Refresh(g *gocui.Gui) (*gocui.View, error) {
 	return widget.Layout(g, widget.X, widget.Y, widget.W, widget.H)
@@ -100,7 +349,7 @@ func (widget *OutputWidget) SetAsCurrentView(g *gocui.Gui) error {
 	if _, err := g.SetCurrentView(widget.Name); err != nil {
 		return err
 	}
-	if err := widget.widgets.Status().SetStatus(g, outputWidgetHelp); err != nil {
+	if err := widget.widgets.Status().SetStatus(g, widget.help()); err != nil {
 		return err
 	}
 	return nil
@@ -128,6 +377,38 @@ func (widget *OutputWidget) SetKeyBindings(g *gocui.Gui) error {
 		return err
 	}
 
+	if err := g.SetKeybinding(widget.Name, gocui.KeyCtrlK, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		if widget.cmd != nil {
+			widget.cmd.Cancel()
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(widget.Name, gocui.KeyCtrlF, gocui.ModNone, widget.toggleFollow); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(widget.Name, gocui.KeyCtrlT, gocui.ModNone, widget.toggleTable); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(widget.Name, gocui.KeyCtrlS, gocui.ModNone, widget.sortTable); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(widget.Name, gocui.KeyCtrlG, gocui.ModNone, widget.filterTable); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(widget.Name, gocui.KeyCtrlD, gocui.ModNone, widget.hideColumn); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(widget.Name, gocui.KeyCtrlA, gocui.ModNone, widget.showColumns); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(widget.Name, gocui.KeyCtrlO, gocui.ModNone, widget.copyColumnToClipboard); err != nil {
+		return err
+	}
+
 	if err := g.SetKeybinding(widget.Name, gocui.MouseLeft, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
 		return widget.SetAsCurrentView(g)
 	}); err != nil {
@@ -138,26 +419,42 @@ func (widget *OutputWidget) SetKeyBindings(g *gocui.Gui) error {
 }
 
 func (widget *OutputWidget) moveCursorUp(g *gocui.Gui, v *gocui.View) error {
+	if widget.tableShown {
+		return widget.moveTableCursor(g, -1, 0)
+	}
 	v.MoveCursor(0, -1, false)
 	return nil
 }
 
 func (widget *OutputWidget) moveCursorDown(g *gocui.Gui, v *gocui.View) error {
+	if widget.tableShown {
+		return widget.moveTableCursor(g, 1, 0)
+	}
 	v.MoveCursor(0, 1, false)
 	return nil
 }
 
 func (widget *OutputWidget) moveCursorLeft(g *gocui.Gui, v *gocui.View) error {
+	if widget.tableShown {
+		return widget.moveTableCursor(g, 0, -1)
+	}
 	v.MoveCursor(-1, 0, false)
 	return nil
 }
 
 func (widget *OutputWidget) moveCursorRight(g *gocui.Gui, v *gocui.View) error {
+	if widget.tableShown {
+		return widget.moveTableCursor(g, 0, 1)
+	}
 	v.MoveCursor(1, 0, false)
 	return nil
 }
 
 func (widget *OutputWidget) copyWordToClipboard(g *gocui.Gui, v *gocui.View) error {
+	if table := widget.shownTable(); table != nil {
+		widget.copyCellToClipboard(table)
+		return nil
+	}
 	xc, yc := v.Cursor()
 	if line, err := v.Line(yc); err == nil && xc <= len(line) {
 		lastSpaceBeforeWord := -1
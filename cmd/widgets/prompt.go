@@ -0,0 +1,129 @@
+package widgets
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jroimartin/gocui"
+)
+
+const (
+	// PromptWidgetName is the name of this widget
+	PromptWidgetName string = "prompt"
+	promptWidgetHelp string = "Prompt \x7c \x1b[7mENTER\x1b[0m Accept \x7c \x1b[7mESC\x1b[0m Cancel \x7c \x1b[7m^W\x1b[0m Paste \x7c \x1b[7m^D\x1b[0m Delete \x7c \x1b[7m^X\x1b[0m Exit"
+)
+
+// Check interface
+var _ IWidget = &PromptWidget{}
+
+// PromptWidget represents a popup that asks user for a value (e.g. the value of a placeholder of a command)
+type PromptWidget struct {
+	Widget
+	editor   *gocui.Editor
+	onDone   func(g *gocui.Gui, value string) error
+	previous string
+	widgets  *Widgets
+}
+
+// NewPromptWidget creates a new PromptWidget
+func NewPromptWidget(editor *gocui.Editor, widgets *Widgets) *PromptWidget {
+	return &PromptWidget{Widget: Widget{Name: PromptWidgetName}, editor: editor, widgets: widgets}
+}
+
+// ShowPrompt shows the popup with a default value, ready to be edited.
+// The action runs with the value once user accepts it.
+func (widget *PromptWidget) ShowPrompt(g *gocui.Gui, title, value string, onDone func(g *gocui.Gui, value string) error) error {
+	widget.Title, widget.onDone = title, onDone
+	if current := g.CurrentView(); current != nil && current.Name() != widget.Name {
+		widget.previous = current.Name()
+	}
+
+	maxX, maxY := g.Size()
+	v, err := widget.Layout(g, 0, 0, maxX, maxY)
+	if err != nil {
+		return err
+	}
+
+	v.Clear()
+	fmt.Fprint(v, value)
+	if err := v.SetCursor(len(value), 0); err != nil {
+		return err
+	}
+
+	return widget.SetAsCurrentView(g)
+}
+
+// HidePrompt hides the popup without running the action
+func (widget *PromptWidget) HidePrompt(g *gocui.Gui) error {
+	if err := g.DeleteView(widget.Name); err != nil {
+		return err
+	}
+
+	// Give the focus back to the widget that had it before the popup
+	if previous, ok := widget.widgets.widgets[widget.previous]; ok {
+		return previous.SetAsCurrentView(g)
+	}
+	return nil
+}
+
+// GetName returns the name of the widget
+func (widget *PromptWidget) GetName() string { return widget.Name }
+
+// Layout shows the contents of the widget on screen
+func (widget *PromptWidget) Layout(g *gocui.Gui, x, y int, w, h int) (*gocui.View, error) {
+	widget.X, widget.Y, widget.W, widget.H = x, y, w, h
+
+	x0, y0 := w/4, h/2-1
+	v, err := g.SetView(widget.Name, x0, y0, w-w/4, y0+2)
+	if err != nil && err != gocui.ErrUnknownView {
+		return nil, err
+	}
+
+	v.Title = widget.Title
+	v.Editable = true
+	v.Editor = *widget.editor
+
+	return v, nil
+}
+
+// Refresh updates the contents of the widget on screen
+func (widget *PromptWidget) Refresh(g *gocui.Gui) (*gocui.View, error) {
+	return widget.Layout(g, widget.X, widget.Y, widget.W, widget.H)
+}
+
+// SetAsCurrentView sets the widget as the current view
+func (widget *PromptWidget) SetAsCurrentView(g *gocui.Gui) error {
+	if _, err := g.SetCurrentView(widget.Name); err != nil {
+		return err
+	}
+
+	if err := widget.widgets.Status().SetStatus(g, promptWidgetHelp); err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetKeyBindings sets keybindings for the widget
+func (widget *PromptWidget) SetKeyBindings(g *gocui.Gui) error {
+	if err := g.SetKeybinding(widget.Name, gocui.KeyEnter, gocui.ModNone, widget.accept); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(widget.Name, gocui.KeyEsc, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		return widget.HidePrompt(g)
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (widget *PromptWidget) accept(g *gocui.Gui, v *gocui.View) error {
+	value, err := v.Line(0)
+	if err != nil {
+		value = ""
+	}
+
+	if err := widget.HidePrompt(g); err != nil {
+		return err
+	}
+	return widget.onDone(g, strings.TrimSpace(value))
+}
@@ -0,0 +1,45 @@
+package widgets
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jroimartin/gocui"
+)
+
+// spinnerInterval is how often the spinners of running commands move
+const spinnerInterval = 100 * time.Millisecond
+
+var spinnerFrames = []string{"|", "/", "-", "\\"}
+
+// spinnerFrame returns the current frame of the spinner shown next to running commands
+func spinnerFrame() string {
+	return spinnerFrames[time.Now().UnixNano()/int64(spinnerInterval)%int64(len(spinnerFrames))]
+}
+
+// Spinner redraws the screen periodically while commands run in the background, so their spinners move
+type Spinner struct {
+	running int32
+	once    sync.Once
+}
+
+// Start tells the spinner that a command started running
+func (s *Spinner) Start(g *gocui.Gui) {
+	atomic.AddInt32(&s.running, 1)
+	s.once.Do(func() {
+		go func() {
+			for range time.Tick(spinnerInterval) {
+				if atomic.LoadInt32(&s.running) > 0 {
+					// Views are updated by their layout, so we only need the screen to be redrawn
+					g.Update(func(g *gocui.Gui) error { return nil })
+				}
+			}
+		}()
+	})
+}
+
+// Stop tells the spinner that a command finished running. It is safe to call it from any goroutine.
+func (s *Spinner) Stop() {
+	atomic.AddInt32(&s.running, -1)
+}
@@ -2,13 +2,16 @@ package widgets
 
 import (
 	"fmt"
+	"superk/cmd/commands"
 
 	"github.com/jroimartin/gocui"
 )
 
 const (
 	// StatusWidgetName is the name of this widget
-	StatusWidgetName string = "status"
+	StatusWidgetName  string = "status"
+	readOnlyIndicator string = "\x1b[1;37;41m READ-ONLY \x1b[0m "
+	contextSegment    string = "\x1b[30;46m %s/%s \x1b[0m "
 )
 
 // Check interface
@@ -17,7 +20,11 @@ var _ IWidget = &StatusWidget{}
 // StatusWidget represents the status bar of the app
 type StatusWidget struct {
 	Widget
-	status string
+	status   string
+	readOnly *commands.ReadOnlyExecutor
+	// context and namespace are where the commands of the tree under the cursor run
+	context   string
+	namespace string
 }
 
 // NewStatusWidget creates a new StatusWidget
@@ -40,6 +47,12 @@ func (widget *StatusWidget) Layout(g *gocui.Gui, x, y int, w, h int) (*gocui.Vie
 
 	v.Frame = false
 	v.Clear()
+	if widget.readOnly != nil && widget.readOnly.ReadOnly(widget.context) {
+		fmt.Fprint(v, readOnlyIndicator)
+	}
+	if widget.context != "" {
+		fmt.Fprintf(v, contextSegment, widget.context, widget.namespace)
+	}
 	fmt.Fprint(v, widget.status)
 	return v, nil
 }
@@ -62,6 +75,16 @@ func (widget *StatusWidget) SetKeyBindings(g *gocui.Gui) error {
 	return nil
 }
 
+// SetReadOnly sets what refuses to run commands that change the cluster, to show whether it does in the current kube context
+func (widget *StatusWidget) SetReadOnly(readOnly *commands.ReadOnlyExecutor) {
+	widget.readOnly = readOnly
+}
+
+// SetKubeContext sets the kube context and the namespace where the commands of the tree under the cursor run
+func (widget *StatusWidget) SetKubeContext(context, namespace string) {
+	widget.context, widget.namespace = context, namespace
+}
+
 // SetStatus allows us to set the status shown by the status bar
 func (widget *StatusWidget) SetStatus(g *gocui.Gui, status string) error {
 	widget.status = status
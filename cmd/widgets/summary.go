@@ -0,0 +1,225 @@
+package widgets
+
+import (
+	"fmt"
+	"strings"
+	"superk/cmd/commands"
+	"time"
+
+	"github.com/jroimartin/gocui"
+)
+
+const (
+	// SummaryWidgetName is the name of this widget
+	SummaryWidgetName string = "summary"
+	summaryWidgetHelp string = "Summary \x7c \x1b[7mENTER\x1b[0m Expand \x7c \x1b[7m^K\x1b[0m Cancel \x7c \x1b[7mESC\x1b[0m Close \x7c \x1b[7m^X\x1b[0m Exit"
+)
+
+// Check interface
+var _ IWidget = &SummaryWidget{}
+
+// SummaryWidget represents a popup with the results of several commands that run at the same time
+// (e.g. all the commands in a subtree), one section per command
+type SummaryWidget struct {
+	Widget
+	batch    *commands.Batch
+	expanded map[*commands.Cmd]bool
+	// sections are the commands whose section each line on screen belongs to
+	sections []*commands.Cmd
+	previous string
+	widgets  *Widgets
+}
+
+// NewSummaryWidget creates a new SummaryWidget
+func NewSummaryWidget(widgets *Widgets) *SummaryWidget {
+	return &SummaryWidget{Widget: Widget{Name: SummaryWidgetName}, widgets: widgets}
+}
+
+// ShowSummary shows the popup with the results of a batch of commands, with every section collapsed
+func (widget *SummaryWidget) ShowSummary(g *gocui.Gui, title string, batch *commands.Batch) error {
+	widget.Title, widget.batch, widget.expanded = title, batch, map[*commands.Cmd]bool{}
+	if current := g.CurrentView(); current != nil && current.Name() != widget.Name {
+		widget.previous = current.Name()
+	}
+
+	maxX, maxY := g.Size()
+	v, err := widget.Layout(g, 0, 0, maxX, maxY)
+	if err != nil {
+		return err
+	}
+	if err := v.SetOrigin(0, 0); err != nil {
+		return err
+	}
+	if err := v.SetCursor(0, 0); err != nil {
+		return err
+	}
+
+	return widget.SetAsCurrentView(g)
+}
+
+// UpdateSummary shows the new results of a batch of commands, if the popup still shows that batch
+func (widget *SummaryWidget) UpdateSummary(g *gocui.Gui, batch *commands.Batch) error {
+	if widget.batch != batch {
+		return nil
+	}
+	if _, err := g.View(widget.Name); err != nil {
+		return nil
+	}
+	_, err := widget.Refresh(g)
+	return err
+}
+
+// HideSummary hides the popup. Commands that are running keep running.
+func (widget *SummaryWidget) HideSummary(g *gocui.Gui) error {
+	if err := g.DeleteView(widget.Name); err != nil {
+		return err
+	}
+
+	// Give the focus back to the widget that had it before the popup
+	if previous, ok := widget.widgets.widgets[widget.previous]; ok {
+		return previous.SetAsCurrentView(g)
+	}
+	return nil
+}
+
+// GetName returns the name of the widget
+func (widget *SummaryWidget) GetName() string { return widget.Name }
+
+// Layout shows the contents of the widget on screen
+func (widget *SummaryWidget) Layout(g *gocui.Gui, x, y int, w, h int) (*gocui.View, error) {
+	widget.X, widget.Y, widget.W, widget.H = x, y, w, h
+
+	v, err := g.SetView(widget.Name, 2, 1, w-3, h-3)
+	if err != nil && err != gocui.ErrUnknownView {
+		return nil, err
+	}
+
+	done, failed, total := widget.batch.Count()
+	v.Title = fmt.Sprintf("%s [%d/%d done, %d failed]", widget.Title, done, total, failed)
+	v.Highlight = true
+	v.SelBgColor = gocui.ColorGreen
+	v.SelFgColor = gocui.ColorBlack
+	v.Clear()
+	widget.sections = nil
+	for _, entry := range widget.batch.Entries() {
+		expanded := widget.expanded[entry.Cmd]
+		widget.print(v, entry.Cmd, summaryHeader(entry, expanded))
+		if !expanded || entry.Output == nil {
+			continue
+		}
+		for _, line := range strings.Split(strings.TrimRight(*entry.Output.Output, "\n"), "\n") {
+			widget.print(v, entry.Cmd, "    "+line)
+		}
+		for _, line := range strings.Split(strings.TrimRight(entry.Output.Stderr, "\n"), "\n") {
+			if line != "" {
+				widget.print(v, entry.Cmd, fmt.Sprintf("    \x1b[31m%s\x1b[0m", line))
+			}
+		}
+	}
+
+	return v, nil
+}
+
+// print shows a line of the section of a command
+func (widget *SummaryWidget) print(v *gocui.View, cmd *commands.Cmd, line string) {
+	fmt.Fprintln(v, line)
+	widget.sections = append(widget.sections, cmd)
+}
+
+// summaryHeader returns the first line of the section of a command, with its status and how long it took
+func summaryHeader(entry commands.BatchEntry, expanded bool) string {
+	marker := "+"
+	if expanded {
+		marker = "-"
+	}
+
+	var status string
+	switch {
+	case entry.Failed():
+		status = fmt.Sprintf("\x1b[31m✗ exit %d\x1b[0m", entry.Output.ExitCode)
+	case entry.Status == commands.BatchDone:
+		status = "\x1b[32m✓\x1b[0m"
+	default:
+		status = entry.Status
+	}
+
+	header := fmt.Sprintf("[%s] %s %s", marker, status, entry.Cmd.ToString())
+	if entry.Status == commands.BatchDone {
+		header = fmt.Sprintf("%s (%s)", header, entry.Output.Duration.Round(time.Millisecond))
+	}
+	return header
+}
+
+// Refresh updates the contents of the widget on screen
+func (widget *SummaryWidget) Refresh(g *gocui.Gui) (*gocui.View, error) {
+	return widget.Layout(g, widget.X, widget.Y, widget.W, widget.H)
+}
+
+// SetAsCurrentView sets the widget as the current view
+func (widget *SummaryWidget) SetAsCurrentView(g *gocui.Gui) error {
+	if _, err := g.SetCurrentView(widget.Name); err != nil {
+		return err
+	}
+
+	if err := widget.widgets.Status().SetStatus(g, summaryWidgetHelp); err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetKeyBindings sets keybindings for the widget
+func (widget *SummaryWidget) SetKeyBindings(g *gocui.Gui) error {
+	if err := g.SetKeybinding(widget.Name, gocui.KeyArrowUp, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		v.MoveCursor(0, -1, false)
+		return nil
+	}); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(widget.Name, gocui.KeyArrowDown, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		if getCommandPosition(v) < len(widget.sections) {
+			v.MoveCursor(0, 1, false)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(widget.Name, gocui.KeyEnter, gocui.ModNone, widget.toggleSection); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(widget.Name, gocui.KeyCtrlK, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		widget.batch.Cancel()
+		_, err := widget.Refresh(g)
+		return err
+	}); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(widget.Name, gocui.KeyEsc, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		return widget.HideSummary(g)
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// toggleSection expands the section under the cursor into the full output of its command, or collapses it
+func (widget *SummaryWidget) toggleSection(g *gocui.Gui, v *gocui.View) error {
+	index := getCommandPosition(v) - 1
+	if index < 0 || index >= len(widget.sections) {
+		return nil
+	}
+	cmd := widget.sections[index]
+	widget.expanded[cmd] = !widget.expanded[cmd]
+	if _, err := widget.Refresh(g); err != nil {
+		return err
+	}
+
+	// Keep the cursor on the first line of the section
+	for line, section := range widget.sections {
+		if section == cmd {
+			return moveTo(v, line)
+		}
+	}
+	return nil
+}
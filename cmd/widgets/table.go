@@ -0,0 +1,354 @@
+package widgets
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"superk/cmd/commands"
+	"superk/cmd/utils"
+
+	"github.com/jroimartin/gocui"
+)
+
+const (
+	outputHeaderName string = "output-header"
+	outputTableHelp  string = "Table \x7c \x1b[7m←→\x1b[0m Column \x7c \x1b[7m^S\x1b[0m Sort \x7c \x1b[7m^G\x1b[0m Filter \x7c \x1b[7m^D\x1b[0m Hide \x7c \x1b[7m^A\x1b[0m Show all \x7c \x1b[7m^C\x1b[0m Copy cell \x7c \x1b[7m^O\x1b[0m Copy column \x7c \x1b[7m^L\x1b[0m Copy line \x7c \x1b[7m^T\x1b[0m Text \x7c \x1b[7m^X\x1b[0m Exit"
+	// tableGap is the space between the columns of a table, as kubectl prints them
+	tableGap string = "   "
+)
+
+// Severities of the statuses of resources in tables
+const (
+	statusOK = iota + 1
+	statusChanging
+	statusFailed
+)
+
+// statusColors are the colors of the statuses of resources in tables: red for failures, yellow for transitions, green for the rest
+var (
+	statusColors = map[int]string{statusOK: "\x1b[32m", statusChanging: "\x1b[33m", statusFailed: "\x1b[31m"}
+	statuses     = map[string]int{
+		"CrashLoopBackOff":           statusFailed,
+		"Error":                      statusFailed,
+		"Failed":                     statusFailed,
+		"ImagePullBackOff":           statusFailed,
+		"ErrImagePull":               statusFailed,
+		"InvalidImageName":           statusFailed,
+		"CreateContainerConfigError": statusFailed,
+		"CreateContainerError":       statusFailed,
+		"RunContainerError":          statusFailed,
+		"OOMKilled":                  statusFailed,
+		"Evicted":                    statusFailed,
+		"NotReady":                   statusFailed,
+		"Unknown":                    statusFailed,
+		"Lost":                       statusFailed,
+		"Pending":                    statusChanging,
+		"ContainerCreating":          statusChanging,
+		"PodInitializing":            statusChanging,
+		"Init":                       statusChanging,
+		"Terminating":                statusChanging,
+		"SchedulingDisabled":         statusChanging,
+		"Released":                   statusChanging,
+		"Running":                    statusOK,
+		"Completed":                  statusOK,
+		"Succeeded":                  statusOK,
+		"Ready":                      statusOK,
+		"Active":                     statusOK,
+		"Bound":                      statusOK,
+		"Available":                  statusOK,
+	}
+)
+
+// shownTable returns the output on screen as a table, if the command prints one that is not streaming
+// and user did not choose to see it as text
+func (widget *OutputWidget) shownTable() *commands.Table {
+	if widget.raw || widget.cmd == nil || widget.runningStream() != nil || !widget.cmd.PrintsTable() {
+		return nil
+	}
+	output := widget.cmd.GetOutput()
+	if output.Output == nil {
+		return nil
+	}
+	// Outputs are parsed once, not every time the screen is drawn
+	if output.Output != widget.parsedFrom {
+		widget.parsed, widget.parsedFrom = commands.ParseTable(*output.Output), output.Output
+	}
+	return widget.parsed
+}
+
+// tableSummary returns which rows and columns of a table are left out, for the title of the widget
+func (widget *OutputWidget) tableSummary(table *commands.Table) string {
+	var details []string
+	if rows := len(widget.table.Rows(table)); rows < len(table.Rows) {
+		details = append(details, fmt.Sprintf("%d/%d rows", rows, len(table.Rows)))
+	}
+	if hidden := len(table.Columns) - len(widget.table.Columns(table)); hidden > 0 {
+		details = append(details, fmt.Sprintf("%d hidden columns, ^A to show", hidden))
+	}
+	if len(details) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" [%s]", strings.Join(details, ", "))
+}
+
+// layoutTable shows the output of a command as a table, with the cell under the cursor on screen.
+// The header is drawn again by a frameless view on top of the first line, so it stays on screen while rows scroll.
+func (widget *OutputWidget) layoutTable(g *gocui.Gui, v *gocui.View, table *commands.Table, x, y, w int) error {
+	v.Wrap = false
+	columns, rows := widget.table.Columns(table), widget.table.Rows(table)
+	widget.row = utils.Max(0, utils.Min(widget.row, len(rows)-1))
+	widget.column = utils.Max(0, utils.Min(widget.column, len(columns)-1))
+
+	headers := make([]string, len(columns))
+	for index, column := range columns {
+		headers[index] = widget.tableHeader(table.Columns[column])
+	}
+	widths := table.Widths(columns, headers, rows)
+	header, starts := formatRow(headers, widths, func(index int, cell string) string {
+		if index == widget.column {
+			return "\x1b[7m" + cell + "\x1b[0m"
+		}
+		return cell
+	})
+
+	// Rows that changed since the previous run of a watched command stand out
+	output := widget.cmd.GetOutput()
+	var changed []bool
+	if widget.watching() && widget.watch.Previous != nil {
+		changed = commands.ChangedLines(*widget.watch.Previous, *output.Output)
+	}
+
+	v.Clear()
+	fmt.Fprintln(v, header)
+	for _, row := range rows {
+		cells := make([]string, len(columns))
+		for index, column := range columns {
+			cells[index] = table.Rows[row][column]
+		}
+		line, _ := formatRow(cells, widths, func(index int, cell string) string {
+			return colorStatus(table.Columns[columns[index]], cell)
+		})
+		// The header is the first line of the output
+		if row+1 < len(changed) && changed[row+1] {
+			line = "\x1b[7m" + strings.Replace(line, "\x1b[0m", "\x1b[0m\x1b[7m", -1) + "\x1b[0m"
+		}
+		fmt.Fprintln(v, line)
+	}
+	if len(rows) == 0 {
+		fmt.Fprintln(v, "No rows match the filters, ^G to change them")
+	}
+	for _, line := range strings.Split(strings.TrimRight(output.Stderr, "\n"), "\n") {
+		if line != "" {
+			fmt.Fprintf(v, "\x1b[31m%s\x1b[0m\n", line)
+		}
+	}
+
+	if err := widget.placeTableCursor(v, starts, widths); err != nil {
+		return err
+	}
+	ox, _ := v.Origin()
+	hv, err := g.SetView(outputHeaderName, x, y, x+w-1, y+2)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	hv.Frame = false
+	hv.Clear()
+	fmt.Fprint(hv, header)
+	return hv.SetOrigin(ox, 0)
+}
+
+// tableHeader returns how the header of a column is shown: with the order of its rows, and a mark if they are filtered
+func (widget *OutputWidget) tableHeader(column string) string {
+	header := column
+	if widget.table.SortColumn == column {
+		header += " ↑"
+		if widget.table.Descending {
+			header = column + " ↓"
+		}
+	}
+	if _, ok := widget.table.Filters[column]; ok {
+		header += " *"
+	}
+	return header
+}
+
+// placeTableCursor scrolls the table so the cell under the cursor is on screen, below the header
+func (widget *OutputWidget) placeTableCursor(v *gocui.View, starts, widths []int) error {
+	width, height := v.Size()
+	ox, oy := v.Origin()
+	line := widget.row + 1
+	if line-1 < oy {
+		oy = line - 1
+	}
+	if line > oy+height-1 {
+		oy = utils.Max(0, line-height+1)
+	}
+	if len(starts) > 0 {
+		start, end := starts[widget.column], starts[widget.column]+widths[widget.column]
+		if end > ox+width {
+			ox = end - width
+		}
+		if start < ox {
+			ox = start
+		}
+	}
+	if err := v.SetOrigin(ox, oy); err != nil {
+		return err
+	}
+
+	// Views too small for a row below the header have no cursor
+	cursorX, cursorY := 0, line-oy
+	if len(starts) > 0 {
+		cursorX = starts[widget.column] - ox
+	}
+	if cursorY >= height || cursorX >= width {
+		return nil
+	}
+	return v.SetCursor(cursorX, cursorY)
+}
+
+// formatRow aligns the cells of a row of a table in columns of some widths, styling each cell.
+// It returns the row and where each of its cells starts.
+func formatRow(cells []string, widths []int, style func(index int, cell string) string) (string, []int) {
+	var builder strings.Builder
+	starts := make([]int, len(cells))
+	position := 0
+	for index, cell := range cells {
+		if index > 0 {
+			builder.WriteString(tableGap)
+			position += len(tableGap)
+		}
+		starts[index] = position
+		builder.WriteString(style(index, cell))
+		if index < len(cells)-1 {
+			builder.WriteString(strings.Repeat(" ", widths[index]-len([]rune(cell))))
+		}
+		position += widths[index]
+	}
+	return builder.String(), starts
+}
+
+// colorStatus shows the status of a resource (e.g. Running or Init:CrashLoopBackOff) in the color of its worst part,
+// and the readiness of a resource (e.g. 1/2) in yellow when some of its containers are not ready
+func colorStatus(column, cell string) string {
+	severity := 0
+	switch {
+	case column == "READY":
+		if ready := strings.SplitN(cell, "/", 2); len(ready) == 2 {
+			count, countErr := strconv.Atoi(ready[0])
+			total, totalErr := strconv.Atoi(ready[1])
+			if countErr == nil && totalErr == nil && count < total {
+				severity = statusChanging
+			}
+		}
+	case strings.Contains(column, "STATUS") || strings.Contains(column, "PHASE") || strings.Contains(column, "STATE"):
+		for _, part := range strings.FieldsFunc(cell, func(r rune) bool { return r == ':' || r == ',' }) {
+			severity = utils.Max(severity, statuses[part])
+		}
+	}
+	if severity == 0 {
+		return cell
+	}
+	return statusColors[severity] + cell + "\x1b[0m"
+}
+
+// tableCell returns the cell under the cursor: its column and its row among the rows shown
+func (widget *OutputWidget) tableCell(table *commands.Table) (int, []int, bool) {
+	columns, rows := widget.table.Columns(table), widget.table.Rows(table)
+	if len(columns) == 0 || len(rows) == 0 {
+		return 0, nil, false
+	}
+	return columns[widget.column], rows, true
+}
+
+// moveTableCursor moves the cursor some rows down and some columns right
+func (widget *OutputWidget) moveTableCursor(g *gocui.Gui, rows, columns int) error {
+	widget.row = utils.Max(0, widget.row+rows)
+	widget.column = utils.Max(0, widget.column+columns)
+	_, err := widget.Refresh(g)
+	return err
+}
+
+// toggleTable shows the output as a table or as text
+func (widget *OutputWidget) toggleTable(g *gocui.Gui, v *gocui.View) error {
+	if widget.cmd == nil || !widget.cmd.PrintsTable() {
+		return nil
+	}
+	widget.raw = !widget.raw
+	_, err := widget.Refresh(g)
+	return err
+}
+
+// sortTable sorts the rows by the column under the cursor: ascending first, then descending, then not sorted
+func (widget *OutputWidget) sortTable(g *gocui.Gui, v *gocui.View) error {
+	table := widget.shownTable()
+	if table == nil {
+		return nil
+	}
+	columns := widget.table.Columns(table)
+	widget.table.Sort(table.Columns[columns[widget.column]])
+	widget.row = 0
+	_, err := widget.Refresh(g)
+	return err
+}
+
+// filterTable asks user what the cells of the column under the cursor must contain to show their rows
+func (widget *OutputWidget) filterTable(g *gocui.Gui, v *gocui.View) error {
+	table := widget.shownTable()
+	if table == nil {
+		return nil
+	}
+	column := table.Columns[widget.table.Columns(table)[widget.column]]
+	title := fmt.Sprintf("Show rows whose %s contains (! to hide them instead)", column)
+	return widget.widgets.Prompt().ShowPrompt(g, title, widget.table.Filters[column], func(g *gocui.Gui, value string) error {
+		widget.table.SetFilter(column, value)
+		widget.row = 0
+		_, err := widget.Refresh(g)
+		return err
+	})
+}
+
+// hideColumn hides the column under the cursor, unless it is the last one shown
+func (widget *OutputWidget) hideColumn(g *gocui.Gui, v *gocui.View) error {
+	table := widget.shownTable()
+	if table == nil {
+		return nil
+	}
+	columns := widget.table.Columns(table)
+	if len(columns) < 2 {
+		return nil
+	}
+	widget.table.Hidden[table.Columns[columns[widget.column]]] = true
+	_, err := widget.Refresh(g)
+	return err
+}
+
+// showColumns shows the columns that were hidden
+func (widget *OutputWidget) showColumns(g *gocui.Gui, v *gocui.View) error {
+	widget.table.Hidden = map[string]bool{}
+	_, err := widget.Refresh(g)
+	return err
+}
+
+// copyCellToClipboard copies the cell under the cursor
+func (widget *OutputWidget) copyCellToClipboard(table *commands.Table) {
+	if column, rows, ok := widget.tableCell(table); ok {
+		widget.clipboard.Content = table.Rows[rows[widget.row]][column]
+	}
+}
+
+// copyColumnToClipboard copies the cells of the column under the cursor, one per line, in the rows shown
+func (widget *OutputWidget) copyColumnToClipboard(g *gocui.Gui, v *gocui.View) error {
+	table := widget.shownTable()
+	if table == nil {
+		return nil
+	}
+	if column, rows, ok := widget.tableCell(table); ok {
+		cells := make([]string, len(rows))
+		for index, row := range rows {
+			cells[index] = table.Rows[row][column]
+		}
+		widget.clipboard.Content = strings.Join(cells, "\n")
+	}
+	return nil
+}
@@ -2,8 +2,11 @@ package widgets
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
 	"superk/cmd/commands"
 	"superk/cmd/utils"
+	"time"
 
 	"github.com/jroimartin/gocui"
 )
@@ -12,23 +15,38 @@ const (
 	// TreeWidgetName is the name of this widget
 	TreeWidgetName  string = "tree"
 	treeWidgetTitle string = "Commands"
-	treeWidgetHelp  string = "Commands \x7c \x1b[7mENTER\x1b[0m Update \x7c \x1b[7m^R\x1b[0m Reuse \x7c \x1b[7m^C\x1b[0m Copy \x7c \x1b[7m^D\x1b[0m Delete \x7c \x1b[7m^X\x1b[0m Exit"
+	// currentContextItem is the item of the context picker for the current context of the kubeconfig, whichever it is
+	currentContextItem string = "(current context of the kubeconfig)"
+	treeWidgetHelp     string = "Commands \x7c \x1b[7mENTER\x1b[0m Update \x7c \x1b[7m^R\x1b[0m Reuse \x7c \x1b[7m^C\x1b[0m Copy \x7c \x1b[7m^D\x1b[0m Delete \x7c \x1b[7m^K\x1b[0m Cancel \x7c \x1b[7m^T\x1b[0m Timeout \x7c \x1b[7m^S\x1b[0m Stream \x7c \x1b[7m^W\x1b[0m Watch \x7c \x1b[7m^A\x1b[0m Run all \x7c \x1b[7m^F\x1b[0m Fan-out \x7c \x1b[7m^U\x1b[0m Audit \x7c \x1b[7m^N\x1b[0m Context \x7c \x1b[7m^Y\x1b[0m Copy to context \x7c \x1b[7m^B\x1b[0m Clone \x7c \x1b[7m^G\x1b[0m Replace \x7c \x1b[7m^O\x1b[0m Import \x7c \x1b[7m^E\x1b[0m Export \x7c \x1b[7m^X\x1b[0m Exit"
 )
 
 // Check interface
 var _ IWidget = &TreeWidget{}
 
-// TreeWidget represents a tree of kubectl commands
+// TreeWidget represents a forest of command trees (kubectl, helm...)
 type TreeWidget struct {
 	Widget
-	commands  *commands.CTree
+	commands  *commands.CForest
 	clipboard *utils.Clipboard
-	widgets   *Widgets
+	spinner   Spinner
+	// watch is the command that runs periodically, if any
+	watch         *Watch
+	watchInterval time.Duration
+	// concurrency is how many commands may run at once when all the commands in a subtree run
+	concurrency int
+	// guard is which commands need confirmation before they run
+	guard *commands.Guard
+	// workspaces are the trees of every kube context, and workspace is the one shown, if trees are kept per context
+	workspaces *commands.Workspaces
+	workspace  string
+	// fanoutContexts are the kube contexts the last command that fanned out ran against
+	fanoutContexts []string
+	widgets        *Widgets
 }
 
 // NewTreeWidget creates a new TreeWidget
 func NewTreeWidget(
-	commands *commands.CTree,
+	commands *commands.CForest,
 	clipboard *utils.Clipboard,
 	widgets *Widgets) *TreeWidget {
 	return &TreeWidget{
@@ -39,6 +57,42 @@ func NewTreeWidget(
 	}
 }
 
+// SetWatchInterval sets how often watched commands run again, unless user chooses another interval
+func (widget *TreeWidget) SetWatchInterval(interval time.Duration) {
+	widget.watchInterval = interval
+}
+
+// SetConcurrency sets how many commands may run at once when all the commands in a subtree run
+func (widget *TreeWidget) SetConcurrency(concurrency int) {
+	widget.concurrency = concurrency
+}
+
+// SetGuard sets which commands need confirmation before they run
+func (widget *TreeWidget) SetGuard(guard *commands.Guard) {
+	widget.guard = guard
+}
+
+// SetWorkspaces sets the trees of every kube context and the one shown, to switch trees when the context changes
+func (widget *TreeWidget) SetWorkspaces(workspaces *commands.Workspaces, workspace *commands.Workspace) {
+	widget.workspaces = workspaces
+	widget.showWorkspace(workspace)
+}
+
+// showWorkspace makes the tree show the commands of a workspace
+func (widget *TreeWidget) showWorkspace(workspace *commands.Workspace) {
+	widget.workspace, widget.commands = workspace.Name, workspace.Forest
+	widget.widgets.Command().SetCommands(workspace.Forest)
+	widget.Title = treeWidgetTitle
+	if workspace.Name != "" {
+		widget.Title = fmt.Sprintf("%s [%s]", treeWidgetTitle, workspace.Name)
+	}
+}
+
+// perContext returns whether each kube context has a tree of its own
+func (widget *TreeWidget) perContext() bool {
+	return widget.workspaces != nil && widget.workspaces.PerContext
+}
+
 // AddCommand adds a new command to the tree
 func (widget *TreeWidget) AddCommand(g *gocui.Gui, command string) error {
 	// Update tree
@@ -89,13 +143,43 @@ func (widget *TreeWidget) Layout(g *gocui.Gui, x, y int, w, h int) (*gocui.View,
 	v.SelBgColor = gocui.ColorGreen
 	v.SelFgColor = gocui.ColorBlack
 	v.Clear()
-	for _, item := range widget.commands.ToStrings(2) {
+	running := widget.commands.GetRunning()
+	exitCodes := widget.commands.GetLastExitCodes()
+	for index, item := range widget.commands.ToStrings(2) {
+		if running[index+1] {
+			item = fmt.Sprintf("%s %s", item, spinnerFrame())
+		} else if exitCode, ok := exitCodes[index+1]; ok {
+			item = fmt.Sprintf("%s %s", item, exitCodeBadge(exitCode))
+		}
 		fmt.Fprintln(v, item)
 	}
+	widget.widgets.Status().SetKubeContext(widget.kubeContext(getCommandPosition(v)))
 
 	return v, nil
 }
 
+// kubeContext returns the kube context and the namespace where the commands of the tree at a certain position run
+func (widget *TreeWidget) kubeContext(position int) (string, string) {
+	if !commands.TakesKubeContext(widget.commands.GetRoot(position)) {
+		return "", ""
+	}
+	kubeconfig, err := commands.LoadKubeconfig()
+	if err != nil {
+		return "", ""
+	}
+	context := widget.commands.GetContext(position)
+	if context == "" {
+		context = widget.commands.Context
+	}
+	if context == "" {
+		context = kubeconfig.CurrentContext
+	}
+	if context == "" {
+		return "", ""
+	}
+	return context, kubeconfig.Namespace(context)
+}
+
 // Refresh updates the contents of the widget on screen
 func (widget *TreeWidget) Refresh(g *gocui.Gui) (*gocui.View, error) {
 	return widget.Layout(g, widget.X, widget.Y, widget.W, widget.H)
@@ -162,6 +246,55 @@ func (widget *TreeWidget) SetKeyBindings(g *gocui.Gui) error {
 		return err
 	}
 
+	if err := g.SetKeybinding(widget.Name, gocui.KeyCtrlK, gocui.ModNone, widget.cancel); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(widget.Name, gocui.KeyCtrlT, gocui.ModNone, widget.setTimeout); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(widget.Name, gocui.KeyCtrlS, gocui.ModNone, widget.toggleStreaming); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(widget.Name, gocui.KeyCtrlW, gocui.ModNone, widget.toggleWatch); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(widget.Name, gocui.KeyCtrlA, gocui.ModNone, widget.runSubtree); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(widget.Name, gocui.KeyCtrlU, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		return widget.widgets.Audit().ShowAudit(g)
+	}); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(widget.Name, gocui.KeyCtrlN, gocui.ModNone, widget.switchContext); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(widget.Name, gocui.KeyCtrlF, gocui.ModNone, widget.fanOut); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(widget.Name, gocui.KeyCtrlY, gocui.ModNone, widget.copyToContext); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(widget.Name, gocui.KeyCtrlB, gocui.ModNone, widget.clone); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(widget.Name, gocui.KeyCtrlG, gocui.ModNone, widget.replace); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(widget.Name, gocui.KeyCtrlO, gocui.ModNone, widget.importHistory); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(widget.Name, gocui.KeyCtrlE, gocui.ModNone, widget.export); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -187,8 +320,8 @@ func (widget *TreeWidget) moveCursorRight(g *gocui.Gui, v *gocui.View) error {
 
 func (widget *TreeWidget) reuse(g *gocui.Gui, v *gocui.View) error {
 	position := getCommandPosition(v)
-	if cmd := widget.commands.GetCmd(position); cmd != nil {
-		if err := widget.widgets.Command().SetContent(g, cmd.ToString()); err != nil {
+	if command := widget.commands.GetCommand(position); command != nil {
+		if err := widget.widgets.Command().SetContent(g, *command); err != nil {
 			return err
 		}
 	}
@@ -197,20 +330,389 @@ func (widget *TreeWidget) reuse(g *gocui.Gui, v *gocui.View) error {
 
 func (widget *TreeWidget) copyToClipboard(g *gocui.Gui, v *gocui.View) error {
 	position := getCommandPosition(v)
-	if cmd := widget.commands.GetCmd(position); cmd != nil {
-		widget.clipboard.Content = cmd.ToString()
+	if command := widget.commands.GetCommand(position); command != nil {
+		widget.clipboard.Content = *command
 	}
 	return nil
 }
 
 func (widget *TreeWidget) run(g *gocui.Gui, v *gocui.View, cacheFirst bool) error {
 	position := getCommandPosition(v)
-	if cmd := widget.commands.GetCmd(position); cmd != nil {
-		_ = cmd.Run(cacheFirst)
 
-		if err := widget.widgets.Output().SetCommandOutput(g, cmd); err != nil {
+	// Ask user for the values of the placeholders of the command before running it
+	if placeholders := widget.commands.GetPlaceholders(position); len(placeholders) > 0 && !cacheFirst {
+		values := widget.commands.GetValues(position)
+		return widget.askValues(g, position, placeholders, 0, values, func(g *gocui.Gui) error {
+			if err := widget.commands.SetValues(position, values); err != nil {
+				return widget.widgets.Msg().ShowMsg(g, "Error", err.Error())
+			}
+			return widget.runCmd(g, position, false)
+		})
+	}
+	if missing := widget.commands.GetMissingValues(position); len(missing) > 0 {
+		message := fmt.Sprintf("Press ENTER to fill in {{%s}}", strings.Join(missing, "}}, {{"))
+		return widget.widgets.Output().SetMessage(g, message)
+	}
+
+	return widget.runCmd(g, position, cacheFirst)
+}
+
+// askValues asks user for the values of the placeholders of a command, one after the other.
+// Placeholders with a source let user pick one of the values listed by their source.
+func (widget *TreeWidget) askValues(
+	g *gocui.Gui,
+	position int,
+	names []string,
+	index int,
+	values map[string]string,
+	onDone func(g *gocui.Gui) error) error {
+	if index == len(names) {
+		return onDone(g)
+	}
+
+	name := names[index]
+	title := fmt.Sprintf("Value of {{%s}} (%d/%d)", name, index+1, len(names))
+	next := func(g *gocui.Gui, value string) error {
+		values[name] = value
+		return widget.askValues(g, position, names, index+1, values, onDone)
+	}
+
+	source, ok := widget.commands.GetSources(position)[name]
+	if !ok {
+		return widget.widgets.Prompt().ShowPrompt(g, title, values[name], next)
+	}
+	source = commands.Expand(source, values)
+	cmd, err := widget.commands.GetSourceCmd(source)
+	if err != nil {
+		return widget.widgets.Msg().ShowMsg(g, "Error", err.Error())
+	}
+
+	pick := func(g *gocui.Gui) error {
+		output := cmd.GetOutput()
+		if output.Cancelled {
+			return nil
+		}
+		items := commands.SourceValues(*output.Output)
+		if len(items) == 0 {
+			return widget.widgets.Msg().ShowMsg(g, "Error", fmt.Sprintf("\"%s\" listed no values for {{%s}}", source, name))
+		}
+		return widget.widgets.List().ShowList(g, title, items, values[name], next)
+	}
+	if cmd.IsFresh(commands.SourceMaxAge) {
+		return pick(g)
+	}
+	// The list shows up once the source finishes running, unless user cancels it
+	then := func(g *gocui.Gui) error {
+		if err := widget.widgets.Output().UpdateCommandOutput(g, cmd); err != nil {
+			return err
+		}
+		// User may have moved on to another widget while the source ran
+		if current := g.CurrentView(); current == nil || current.Name() != widget.Name {
+			return nil
+		}
+		return pick(g)
+	}
+	// Sources that change the cluster only run once user confirms them, like any other command
+	if widget.guard.Guards(cmd.Args) {
+		return widget.confirmRun(g, cmd, then)
+	}
+	widget.start(g, cmd, then)
+	return widget.widgets.Output().SetCommandOutput(g, cmd)
+}
+
+func (widget *TreeWidget) runCmd(g *gocui.Gui, position int, cacheFirst bool) error {
+	cmd := widget.commands.GetCmd(position)
+	if cmd == nil {
+		return nil
+	}
+	if cmd.IsRunning() || cacheFirst && cmd.GetOutput().Output != nil {
+		return widget.widgets.Output().SetCommandOutput(g, cmd)
+	}
+
+	// Commands that change the cluster never run just because the cursor moves over them, and only once user confirms them
+	if widget.guard.Guards(cmd.Args) {
+		if cacheFirst {
+			return widget.widgets.Output().SetMessage(g, fmt.Sprintf("Press ENTER to run %s", cmd.ToString()))
+		}
+		return widget.confirmRun(g, cmd, func(g *gocui.Gui) error {
+			return widget.widgets.Output().UpdateCommandOutput(g, cmd)
+		})
+	}
+
+	widget.start(g, cmd, func(g *gocui.Gui) error {
+		return widget.widgets.Output().UpdateCommandOutput(g, cmd)
+	})
+	return widget.widgets.Output().SetCommandOutput(g, cmd)
+}
+
+// confirmRun asks user to confirm a command that changes the cluster, showing the context it runs against, and runs it.
+// then runs in the main loop once the command finishes.
+func (widget *TreeWidget) confirmRun(g *gocui.Gui, cmd *commands.Cmd, then func(g *gocui.Gui) error) error {
+	context := commands.KubeContext(cmd.ExecArgs())
+	if context == "" {
+		context = "unknown"
+	}
+	lines := []string{cmd.ToString(), "", fmt.Sprintf("Context: %s", context)}
+	return widget.widgets.Confirm().ShowConfirm(g, "Run this command? It changes the cluster", lines, func(g *gocui.Gui) error {
+		widget.start(g, cmd, then)
+		return widget.widgets.Output().SetCommandOutput(g, cmd)
+	})
+}
+
+// start runs a command in the background, so the app keeps responding while it runs.
+// Once it finishes, its execution is recorded in the tree and then runs in the main loop.
+func (widget *TreeWidget) start(g *gocui.Gui, cmd *commands.Cmd, then func(g *gocui.Gui) error) {
+	// The tree shown may be the one of another context by the time the command finishes
+	forest := widget.commands
+	widget.spinner.Start(g)
+	if !cmd.RunAsync(func(output *commands.CmdOutput) {
+		widget.spinner.Stop()
+		g.Update(func(g *gocui.Gui) error {
+			forest.RecordRun(cmd, output)
+			return then(g)
+		})
+	}) {
+		widget.spinner.Stop()
+	}
+}
+
+// setTimeout asks user how long the command may take, instead of the timeout in the config file
+func (widget *TreeWidget) setTimeout(g *gocui.Gui, v *gocui.View) error {
+	position := getCommandPosition(v)
+	command := widget.commands.GetCommand(position)
+	if command == nil {
+		return nil
+	}
+
+	value := ""
+	if timeout := widget.commands.GetTimeout(position); timeout > 0 {
+		value = timeout.String()
+	}
+	title := fmt.Sprintf("Timeout of %s (e.g. 30s, empty for default)", *command)
+	return widget.widgets.Prompt().ShowPrompt(g, title, value, func(g *gocui.Gui, value string) error {
+		var timeout time.Duration
+		if value != "" {
+			parsed, err := time.ParseDuration(value)
+			if err != nil {
+				return widget.widgets.Msg().ShowMsg(g, "Error", fmt.Sprintf("Invalid timeout %q", value))
+			}
+			timeout = parsed
+		}
+		if err := widget.commands.SetTimeout(position, timeout); err != nil {
+			return widget.widgets.Msg().ShowMsg(g, "Error", err.Error())
+		}
+		return nil
+	})
+}
+
+func (widget *TreeWidget) toggleStreaming(g *gocui.Gui, v *gocui.View) error {
+	position := getCommandPosition(v)
+	command := widget.commands.GetCommand(position)
+	if command == nil {
+		return nil
+	}
+
+	stream := !widget.commands.GetStreaming(position)
+	if err := widget.commands.SetStreaming(position, stream); err != nil {
+		return widget.widgets.Msg().ShowMsg(g, "Error", err.Error())
+	}
+	message := fmt.Sprintf("%s streams its output while it runs", *command)
+	if !widget.commands.GetCmd(position).IsStreaming() {
+		message = fmt.Sprintf("%s shows its output when it finishes", *command)
+	} else if !stream {
+		message = fmt.Sprintf("%s still streams its output, as its flags say it follows it", *command)
+	}
+	return widget.widgets.Msg().ShowMsg(g, "Streaming", message)
+}
+
+// runSubtree runs all the commands under the command at the cursor at the same time, and shows a summary of their results
+func (widget *TreeWidget) runSubtree(g *gocui.Gui, v *gocui.View) error {
+	position := getCommandPosition(v)
+	command := widget.commands.GetCommand(position)
+	if command == nil {
+		return nil
+	}
+	// Commands that change the cluster need confirmation one by one
+	var cmds []*commands.Cmd
+	for _, cmd := range widget.commands.GetLeafCmds(position) {
+		if !widget.guard.Guards(cmd.Args) {
+			cmds = append(cmds, cmd)
+		}
+	}
+	if len(cmds) == 0 {
+		message := fmt.Sprintf("No command under %s can run on its own (e.g. it streams its output or changes the cluster)", *command)
+		return widget.widgets.Msg().ShowMsg(g, "Run all", message)
+	}
+
+	forest := widget.commands
+	batch := commands.NewBatch(cmds, widget.concurrency)
+	batch.Run(func(entry commands.BatchEntry) {
+		if entry.Status == commands.BatchRunning {
+			widget.spinner.Start(g)
+			g.Update(func(g *gocui.Gui) error {
+				return widget.widgets.Summary().UpdateSummary(g, batch)
+			})
+			return
+		}
+
+		widget.spinner.Stop()
+		g.Update(func(g *gocui.Gui) error {
+			// Executions that were already running are recorded by whatever started them
+			if entry.Started {
+				forest.RecordRun(entry.Cmd, entry.Output)
+			}
+			if err := widget.widgets.Output().UpdateCommandOutput(g, entry.Cmd); err != nil {
+				return err
+			}
+			return widget.widgets.Summary().UpdateSummary(g, batch)
+		})
+	})
+	return widget.widgets.Summary().ShowSummary(g, fmt.Sprintf("Run all under %s", *command), batch)
+}
+
+// fanOut asks user which kube contexts to run the command under the cursor against, and runs it against all of them at once
+func (widget *TreeWidget) fanOut(g *gocui.Gui, v *gocui.View) error {
+	position := getCommandPosition(v)
+	command := widget.commands.GetCommand(position)
+	if command == nil || !commands.TakesKubeContext(widget.commands.GetRoot(position)) {
+		return nil
+	}
+	if missing := widget.commands.GetMissingValues(position); len(missing) > 0 {
+		message := fmt.Sprintf("Run %s once to set the values of its placeholders first", *command)
+		return widget.widgets.Msg().ShowMsg(g, "Fan-out", message)
+	}
+	cmd := widget.commands.GetCmd(position)
+	// Commands that change the cluster need confirmation one context at a time
+	if widget.guard.Guards(cmd.Args) {
+		message := fmt.Sprintf("%s changes the cluster, so it does not run against several contexts at once", cmd.ToString())
+		return widget.widgets.Msg().ShowMsg(g, "Fan-out", message)
+	}
+	kubeconfig, err := commands.LoadKubeconfig()
+	if err != nil {
+		return widget.widgets.Msg().ShowMsg(g, "Context error", err.Error())
+	}
+
+	title := fmt.Sprintf("Run %s against", cmd.ToString())
+	return widget.widgets.List().ShowChecklist(g, title, kubeconfig.ContextNames(), widget.fanoutContexts, func(g *gocui.Gui, contexts []string) error {
+		fanout, err := commands.NewFanout(cmd, contexts, widget.concurrency)
+		if err != nil {
+			return widget.widgets.Msg().ShowMsg(g, "Fan-out error", err.Error())
+		}
+		widget.fanoutContexts = contexts
+
+		fanout.Batch.Run(func(entry commands.BatchEntry) {
+			if entry.Status == commands.BatchRunning {
+				widget.spinner.Start(g)
+			} else {
+				widget.spinner.Stop()
+			}
+			g.Update(func(g *gocui.Gui) error {
+				return widget.widgets.Fanout().UpdateFanout(g, fanout)
+			})
+		})
+		return widget.widgets.Fanout().ShowFanout(g, cmd.ToString(), fanout)
+	})
+}
+
+// toggleWatch asks user how often to run the command again and again, or stops running it if it already does
+func (widget *TreeWidget) toggleWatch(g *gocui.Gui, v *gocui.View) error {
+	position := getCommandPosition(v)
+	command := widget.commands.GetCommand(position)
+	if command == nil {
+		return nil
+	}
+	if missing := widget.commands.GetMissingValues(position); len(missing) > 0 {
+		message := fmt.Sprintf("Press ENTER to fill in {{%s}}", strings.Join(missing, "}}, {{"))
+		return widget.widgets.Output().SetMessage(g, message)
+	}
+	cmd := widget.commands.GetCmd(position)
+	if widget.watch != nil && widget.watch.Cmd == cmd {
+		return widget.stopWatch(g)
+	}
+	if widget.guard.Guards(cmd.Args) {
+		message := fmt.Sprintf("%s changes the cluster, so it cannot run again and again", *command)
+		return widget.widgets.Msg().ShowMsg(g, "Watch", message)
+	}
+	if cmd.IsStreaming() {
+		message := fmt.Sprintf("%s streams its output, so it never finishes and cannot run again and again", *command)
+		return widget.widgets.Msg().ShowMsg(g, "Watch", message)
+	}
+
+	title := fmt.Sprintf("Watch %s every (e.g. 2s)", *command)
+	return widget.widgets.Prompt().ShowPrompt(g, title, widget.watchInterval.String(), func(g *gocui.Gui, value string) error {
+		interval, err := time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			return widget.widgets.Msg().ShowMsg(g, "Error", fmt.Sprintf("Invalid interval %q", value))
+		}
+		if err := widget.startWatch(g, cmd, interval); err != nil {
 			return err
 		}
+		return widget.widgets.Output().SetCommandOutput(g, cmd)
+	})
+}
+
+// startWatch runs a command now and then again and again, stopping the command that was watched before, if any
+func (widget *TreeWidget) startWatch(g *gocui.Gui, cmd *commands.Cmd, interval time.Duration) error {
+	if err := widget.stopWatch(g); err != nil {
+		return err
+	}
+	watch := newWatch(cmd, interval)
+	widget.watch = watch
+	widget.widgets.Output().SetWatch(watch)
+
+	go func() {
+		ticker := time.NewTicker(watchTick)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-watch.stop:
+				return
+			case <-ticker.C:
+				g.Update(func(g *gocui.Gui) error {
+					return widget.tickWatch(g, watch)
+				})
+			}
+		}
+	}()
+	return widget.tickWatch(g, watch)
+}
+
+// tickWatch runs the watched command again if it is time to.
+// The watch stops if its command is no longer in the tree (e.g. user deleted it).
+func (widget *TreeWidget) tickWatch(g *gocui.Gui, watch *Watch) error {
+	if widget.watch != watch {
+		return nil
+	}
+	if !widget.commands.HasCmd(watch.Cmd) {
+		return widget.stopWatch(g)
+	}
+	if watch.Cmd.IsRunning() || time.Now().Before(watch.Next) {
+		return nil
+	}
+
+	watch.Previous = watch.Cmd.GetOutput().Output
+	widget.start(g, watch.Cmd, func(g *gocui.Gui) error {
+		watch.Next = time.Now().Add(watch.Interval)
+		return widget.widgets.Output().UpdateCommandOutput(g, watch.Cmd)
+	})
+	return widget.widgets.Output().UpdateCommandOutput(g, watch.Cmd)
+}
+
+// stopWatch stops running the watched command again and again, if any
+func (widget *TreeWidget) stopWatch(g *gocui.Gui) error {
+	if widget.watch == nil {
+		return nil
+	}
+	close(widget.watch.stop)
+	widget.watch = nil
+	widget.widgets.Output().SetWatch(nil)
+	_, err := widget.widgets.Output().Refresh(g)
+	return err
+}
+
+func (widget *TreeWidget) cancel(g *gocui.Gui, v *gocui.View) error {
+	if cmd := widget.commands.GetCmd(getCommandPosition(v)); cmd != nil {
+		cmd.Cancel()
 	}
 	return nil
 }
@@ -220,11 +722,244 @@ func (widget *TreeWidget) delete(g *gocui.Gui, v *gocui.View) error {
 	if err := widget.commands.RemoveCommand(position); err != nil {
 		return nil
 	}
+	if widget.watch != nil && !widget.commands.HasCmd(widget.watch.Cmd) {
+		return widget.stopWatch(g)
+	}
 	return nil
 }
 
+// switchContext lets user pick the kube context the commands of the tree under the cursor run against.
+// The kubeconfig is left as is: commands run with the --context flag (or the flag of their binary).
+func (widget *TreeWidget) switchContext(g *gocui.Gui, v *gocui.View) error {
+	kubeconfig, err := commands.LoadKubeconfig()
+	if err != nil {
+		return widget.widgets.Msg().ShowMsg(g, "Context error", err.Error())
+	}
+	if widget.perContext() {
+		return widget.widgets.List().ShowList(g, "Switch to context", kubeconfig.ContextNames(), widget.commands.Context, widget.switchWorkspace)
+	}
+
+	position := getCommandPosition(v)
+	root := widget.commands.GetRoot(position)
+	if !commands.TakesKubeContext(root) {
+		return nil
+	}
+
+	items := append([]string{currentContextItem}, kubeconfig.ContextNames()...)
+	selected := widget.commands.GetContext(position)
+	if selected == "" {
+		selected = currentContextItem
+	}
+	title := fmt.Sprintf("Run %s commands against", root)
+	return widget.widgets.List().ShowList(g, title, items, selected, func(g *gocui.Gui, context string) error {
+		if context == currentContextItem {
+			context = ""
+		}
+		if err := widget.commands.SetContext(position, context); err != nil {
+			return widget.widgets.Msg().ShowMsg(g, "Context error", err.Error())
+		}
+		if _, err := widget.Refresh(g); err != nil {
+			return err
+		}
+		if context == "" {
+			context = kubeconfig.CurrentContext
+		}
+		return widget.widgets.Output().SetMessage(g, fmt.Sprintf("%s commands run against %s", root, context))
+	})
+}
+
+// switchWorkspace shows the tree of a kube context, and makes its commands run against that context
+func (widget *TreeWidget) switchWorkspace(g *gocui.Gui, context string) error {
+	workspace, err := widget.workspaces.ForContext(context)
+	if err != nil {
+		return widget.widgets.Msg().ShowMsg(g, "Context error", err.Error())
+	}
+	// The watched command belongs to the tree that is no longer shown
+	if err := widget.stopWatch(g); err != nil {
+		return err
+	}
+	widget.showWorkspace(workspace)
+
+	v, err := widget.Refresh(g)
+	if err != nil {
+		return err
+	}
+	if err := v.SetOrigin(0, 0); err != nil {
+		return err
+	}
+	if err := v.SetCursor(0, 0); err != nil {
+		return err
+	}
+	return widget.widgets.Output().SetMessage(g, fmt.Sprintf("Commands run against %s", context))
+}
+
+// copyToContext copies the command under the cursor, and all its children commands, into the tree of another kube context
+func (widget *TreeWidget) copyToContext(g *gocui.Gui, v *gocui.View) error {
+	if !widget.perContext() {
+		return widget.widgets.Msg().ShowMsg(g, "Copy to context", "Set treePerContext in the config file to keep a tree per kube context")
+	}
+	position := getCommandPosition(v)
+	command := widget.commands.GetCommand(position)
+	if command == nil {
+		return nil
+	}
+	kubeconfig, err := commands.LoadKubeconfig()
+	if err != nil {
+		return widget.widgets.Msg().ShowMsg(g, "Context error", err.Error())
+	}
+
+	var names []string
+	for _, name := range widget.workspaces.Names(kubeconfig.ContextNames()) {
+		if name != "" && name != widget.workspace {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return widget.widgets.Msg().ShowMsg(g, "Copy to context", "There are no other kube contexts in the kubeconfig")
+	}
+	title := fmt.Sprintf("Copy %s to", *command)
+	return widget.widgets.List().ShowList(g, title, names, "", func(g *gocui.Gui, name string) error {
+		workspace, err := widget.workspaces.Open(name)
+		if err != nil {
+			return widget.widgets.Msg().ShowMsg(g, "Copy error", err.Error())
+		}
+		count, err := widget.commands.CopyCommand(position, workspace.Forest)
+		if err != nil {
+			return widget.widgets.Msg().ShowMsg(g, "Copy error", err.Error())
+		}
+		return widget.widgets.Output().SetMessage(g, fmt.Sprintf("Copied %d commands to %s", count, name))
+	})
+}
+
+// clone copies the command under the cursor, and all its children commands, with a value replaced
+// (e.g. -n team-a by -n team-b), once user sees the commands it adds
+func (widget *TreeWidget) clone(g *gocui.Gui, v *gocui.View) error {
+	position := getCommandPosition(v)
+	command := widget.commands.GetCommand(position)
+	if command == nil {
+		return nil
+	}
+	// Namespaces are what is most often replaced
+	value := ""
+	if cmd := widget.commands.GetCmd(position); commands.TakesKubeContext(cmd.Path) {
+		value = commands.KubeNamespace(cmd.Args)
+	}
+
+	title := fmt.Sprintf("Clone %s replacing", *command)
+	return widget.widgets.Prompt().ShowPrompt(g, title, value, func(g *gocui.Gui, old string) error {
+		title := fmt.Sprintf("Replace %s with", old)
+		return widget.widgets.Prompt().ShowPrompt(g, title, "", func(g *gocui.Gui, new string) error {
+			preview, err := widget.commands.PreviewClone(position, old, new)
+			if err != nil {
+				return widget.widgets.Msg().ShowMsg(g, "Clone error", err.Error())
+			}
+			lines := make([]string, 0, len(preview))
+			for _, change := range preview {
+				lines = append(lines, change.After)
+			}
+			title := fmt.Sprintf("Add %d commands?", len(preview))
+			return widget.widgets.Confirm().ShowConfirm(g, title, lines, func(g *gocui.Gui) error {
+				count, err := widget.commands.CloneCommand(position, old, new)
+				if err != nil {
+					return widget.widgets.Msg().ShowMsg(g, "Clone error", err.Error())
+				}
+				if _, err := widget.Refresh(g); err != nil {
+					return err
+				}
+				return widget.widgets.Output().SetMessage(g, fmt.Sprintf("Cloned %d commands with %s instead of %s", count, new, old))
+			})
+		})
+	})
+}
+
+// replace replaces some text in the parts of every command of the tree, once user sees the commands that change
+func (widget *TreeWidget) replace(g *gocui.Gui, v *gocui.View) error {
+	return widget.widgets.Prompt().ShowPrompt(g, "Replace in every command", "", func(g *gocui.Gui, old string) error {
+		title := fmt.Sprintf("Replace %s with", old)
+		return widget.widgets.Prompt().ShowPrompt(g, title, "", func(g *gocui.Gui, new string) error {
+			preview, err := widget.commands.PreviewReplace(old, new)
+			if err != nil {
+				return widget.widgets.Msg().ShowMsg(g, "Replace error", err.Error())
+			}
+			if len(preview) == 0 {
+				return widget.widgets.Msg().ShowMsg(g, "Replace", fmt.Sprintf("%s is not in any command", old))
+			}
+			lines := make([]string, 0, len(preview))
+			for _, change := range preview {
+				lines = append(lines, fmt.Sprintf("%s → %s", change.Before, change.After))
+			}
+			title := fmt.Sprintf("Change %d commands?", len(preview))
+			return widget.widgets.Confirm().ShowConfirm(g, title, lines, func(g *gocui.Gui) error {
+				count, err := widget.commands.ReplacePart(old, new)
+				if err != nil {
+					return widget.widgets.Msg().ShowMsg(g, "Replace error", err.Error())
+				}
+				// The watched command was built from the parts before they changed
+				if widget.watch != nil && !widget.commands.HasCmd(widget.watch.Cmd) {
+					if err := widget.stopWatch(g); err != nil {
+						return err
+					}
+				}
+				if _, err := widget.Refresh(g); err != nil {
+					return err
+				}
+				return widget.widgets.Output().SetMessage(g, fmt.Sprintf("Replaced %s with %s in %d parts", old, new, count))
+			})
+		})
+	})
+}
+
+func (widget *TreeWidget) importHistory(g *gocui.Gui, v *gocui.View) error {
+	var lines []string
+	for _, file := range commands.DefaultHistoryFiles() {
+		history, err := commands.ReadHistory(file)
+		if err != nil {
+			return widget.widgets.Msg().ShowMsg(g, "Import error", err.Error())
+		}
+		lines = append(lines, history...)
+	}
+
+	// Show user what will be added before changing the tree
+	preview := widget.commands.PreviewImport(lines)
+	if len(preview) == 0 {
+		return widget.widgets.Msg().ShowMsg(g, "Import", "No new commands found in shell history")
+	}
+	title := fmt.Sprintf("Import %d commands from shell history?", len(preview))
+	return widget.widgets.Confirm().ShowConfirm(g, title, preview, func(g *gocui.Gui) error {
+		if err := widget.commands.Import(preview); err != nil {
+			return widget.widgets.Msg().ShowMsg(g, "Import error", err.Error())
+		}
+		_, err := widget.Refresh(g)
+		return err
+	})
+}
+
+func (widget *TreeWidget) export(g *gocui.Gui, v *gocui.View) error {
+	return widget.widgets.List().ShowList(g, "Export as", commands.ExporterNames(), "", func(g *gocui.Gui, format string) error {
+		// Files are written to the current directory (e.g. superk_markdown.md)
+		exporter := commands.GetExporter(format)
+		file, err := filepath.Abs(fmt.Sprintf("superk_%s.%s", exporter.Name, exporter.Extension))
+		if err == nil {
+			err = exporter.ExportFile(widget.commands, file)
+		}
+		if err != nil {
+			return widget.widgets.Msg().ShowMsg(g, "Export error", err.Error())
+		}
+		return widget.widgets.Msg().ShowMsg(g, "Export", fmt.Sprintf("Exported to %s", file))
+	})
+}
+
 func getCommandPosition(v *gocui.View) int {
 	_, yc := v.Cursor()
 	_, yo := v.Origin()
 	return yc + yo + 1
 }
+
+// exitCodeBadge returns a green mark for a command that succeeded the last time it ran,
+// or a red mark with its exit code for a command that failed
+func exitCodeBadge(exitCode int) string {
+	if exitCode == 0 {
+		return "\x1b[32m✓\x1b[0m"
+	}
+	return fmt.Sprintf("\x1b[31m✗ %d\x1b[0m", exitCode)
+}
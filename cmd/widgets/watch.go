@@ -0,0 +1,46 @@
+package widgets
+
+import (
+	"strings"
+	"superk/cmd/commands"
+	"time"
+)
+
+// watchTick is how often a watch checks whether its command should run again, and its countdown is refreshed
+const watchTick = 250 * time.Millisecond
+
+// Watch represents a command of the tree that runs periodically, like watch -n
+type Watch struct {
+	Cmd      *commands.Cmd
+	Interval time.Duration
+	// Next is when the command runs again, once it finished running
+	Next time.Time
+	// Previous is the output of the command before it last ran, to highlight the lines that changed since then
+	Previous *string
+	stop     chan struct{}
+}
+
+// newWatch creates a watch that runs its command right away
+func newWatch(cmd *commands.Cmd, interval time.Duration) *Watch {
+	return &Watch{Cmd: cmd, Interval: interval, stop: make(chan struct{})}
+}
+
+// Remaining returns how long until the command runs again, rounded to seconds
+func (watch *Watch) Remaining() time.Duration {
+	remaining := time.Until(watch.Next).Round(time.Second)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// highlightChanges shows in reverse video the lines of an output that were not in its previous output
+func highlightChanges(previous, output string) string {
+	lines := strings.Split(output, "\n")
+	for index, changed := range commands.ChangedLines(previous, output) {
+		if changed && lines[index] != "" {
+			lines[index] = "\x1b[7m" + lines[index] + "\x1b[0m"
+		}
+	}
+	return strings.Join(lines, "\n")
+}
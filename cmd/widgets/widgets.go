@@ -12,16 +12,22 @@ type Widgets struct {
 }
 
 // NewWidgets creates a new Widgets
-func NewWidgets(commands *commands.CTree) *Widgets {
+func NewWidgets(commands *commands.CForest) *Widgets {
 	clipboard := utils.NewClipboard()
 	editor := editors.NewCustomEditor(clipboard)
 
 	all := Widgets{widgets: map[string]IWidget{}}
 	all.widgets[MsgWidgetName] = NewMsgWidget()
+	all.widgets[ConfirmWidgetName] = NewConfirmWidget(&all)
+	all.widgets[ListWidgetName] = NewListWidget(&all)
+	all.widgets[PromptWidgetName] = NewPromptWidget(editor, &all)
+	all.widgets[SummaryWidgetName] = NewSummaryWidget(&all)
+	all.widgets[AuditWidgetName] = NewAuditWidget(&all)
+	all.widgets[FanoutWidgetName] = NewFanoutWidget(&all)
 	all.widgets[StatusWidgetName] = NewStatusWidget()
 	all.widgets[OutputWidgetName] = NewOutputWidget(clipboard, &all)
 	all.widgets[TreeWidgetName] = NewTreeWidget(commands, clipboard, &all)
-	all.widgets[CommandWidgetName] = NewCommandWidget(editor, &all)
+	all.widgets[CommandWidgetName] = NewCommandWidget(editor, commands, &all)
 	all.widgets[MainScreenWidgetName] = NewMainScreenWidget(&all)
 
 	return &all
@@ -30,6 +36,24 @@ func NewWidgets(commands *commands.CTree) *Widgets {
 // Msg returns the message widget
 func (all *Widgets) Msg() *MsgWidget { return all.widgets[MsgWidgetName].(*MsgWidget) }
 
+// Confirm returns the confirmation widget
+func (all *Widgets) Confirm() *ConfirmWidget { return all.widgets[ConfirmWidgetName].(*ConfirmWidget) }
+
+// List returns the list widget
+func (all *Widgets) List() *ListWidget { return all.widgets[ListWidgetName].(*ListWidget) }
+
+// Prompt returns the prompt widget
+func (all *Widgets) Prompt() *PromptWidget { return all.widgets[PromptWidgetName].(*PromptWidget) }
+
+// Summary returns the summary widget
+func (all *Widgets) Summary() *SummaryWidget { return all.widgets[SummaryWidgetName].(*SummaryWidget) }
+
+// Audit returns the audit log widget
+func (all *Widgets) Audit() *AuditWidget { return all.widgets[AuditWidgetName].(*AuditWidget) }
+
+// Fanout returns the fan-out widget
+func (all *Widgets) Fanout() *FanoutWidget { return all.widgets[FanoutWidgetName].(*FanoutWidget) }
+
 // Status returns the status widget
 func (all *Widgets) Status() *StatusWidget { return all.widgets[StatusWidgetName].(*StatusWidget) }
 